@@ -0,0 +1,26 @@
+package actors
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// Console implements HUD.
+type Console struct {
+	*super.Console
+	heightRatio float64 // of the screen it drops down over
+}
+
+// NewConsole is a constructor.
+// The console drops down from the top of the screen over the given ratio of its height.
+func NewConsole(nLines int, heightRatio float64) *Console {
+	return &Console{
+		Console:     super.NewConsole(nLines),
+		heightRatio: heightRatio,
+	}
+}
+
+// PosOnScreen implements the HUD interface that super.Console lacks of.
+func (console *Console) PosOnScreen(width, height float64) {
+	console.SetBounds(pixel.R(0, height*(1-console.heightRatio), width, height))
+}
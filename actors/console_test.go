@@ -0,0 +1,36 @@
+package actors
+
+import (
+	"testing"
+
+	"github.com/nanitefactory/visual/visualtest"
+)
+
+func TestConsole(t *testing.T) {
+	console := NewConsole(100, 0.4)
+	console.PosOnScreen(800, 600)
+	rt := visualtest.NewRecordingTarget()
+
+	// Hidden by default.
+	console.Update(1)
+	console.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws while hidden; want 0", n)
+	}
+
+	console.Toggle()
+	console.Print("hello")
+	console.Update(0)
+	console.Draw(rt)
+	if !rt.DrewPicture() {
+		t.Fatal("no text is drawn")
+	}
+	for _, c := range rt.Draws() {
+		if c.HasPicture {
+			continue
+		}
+		if b := c.Bounds(); b.Min.X != 0 || b.Max.X != 800 || b.Min.Y != 360 || b.Max.Y != 600 {
+			t.Errorf("background drawn at %v; want it over the top 40%% of 800x600", b)
+		}
+	}
+}
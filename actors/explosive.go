@@ -1,35 +1,35 @@
-package actors
-
-import (
-	"image/color"
-
-	"github.com/faiface/pixel"
-	"github.com/nanitefactory/visual/super"
-)
-
-// Explosions implements Actor interface.
-type Explosions struct {
-	*super.Explosions
-}
-
-// NewExplosions is a constructor.
-// The 3rd argument colors can be nil. Then it will use its default value of a color set.
-func NewExplosions(width, height float64, colors []color.Color, precision int) *Explosions {
-	parent := super.NewExplosions(width, height, colors, precision)
-	return &Explosions{parent}
-}
-
-// Draw implements Drawer interface.
-func (e *Explosions) Draw(t pixel.Target) {
-	if e.IsExploding() {
-		e.Explosions.Draw(t)
-	}
-}
-
-// Update implements Updater interface.
-func (e *Explosions) Update(dt float64) {
-	// Only update when there is at least one (animating) explosion.
-	if e.IsExploding() {
-		e.Explosions.Update(dt)
-	}
-}
+package actors
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// Explosions implements Actor interface.
+type Explosions struct {
+	*super.Explosions
+}
+
+// NewExplosions is a constructor.
+// The 3rd argument colors can be nil. Then it will use its default value of a color set.
+func NewExplosions(width, height float64, colors []color.Color, precision int) *Explosions {
+	parent := super.NewExplosions(width, height, colors, precision)
+	return &Explosions{parent}
+}
+
+// Draw implements Drawer interface.
+func (e *Explosions) Draw(t pixel.Target) {
+	if e.IsExploding() {
+		e.Explosions.Draw(t)
+	}
+}
+
+// Update implements Updater interface.
+func (e *Explosions) Update(dt float64) {
+	// Only update when there is at least one (animating) explosion.
+	if e.IsExploding() {
+		e.Explosions.Update(dt)
+	}
+}
@@ -0,0 +1,56 @@
+package actors
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/visualtest"
+)
+
+func TestExplosions(t *testing.T) {
+	col := color.RGBA{255, 0, 0, 255}
+	e := NewExplosions(1000, 1000, []color.Color{col}, 4)
+	rt := visualtest.NewRecordingTarget()
+
+	// Nothing is drawn before anything explodes.
+	e.Update(0.001)
+	e.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws before an explosion; want 0", n)
+	}
+
+	// Particles are drawn around where it explodes.
+	e.ExplodeAt(pixel.V(500, 500), pixel.V(10, 10))
+	e.Update(0.001)
+	e.Draw(rt)
+	particleColor := col
+	particleColor.A = 5
+	if !rt.DrewNear(particleColor, pixel.V(500, 500), 16*1.5+2*10*1.5) {
+		t.Error("no particle is drawn near the explosion")
+	}
+	if rt.DrewNear(particleColor, pixel.V(100, 100), 100) {
+		t.Error("a particle is drawn far away from the explosion")
+	}
+
+	// Nothing is drawn after all particles die.
+	rt.Reset()
+	e.Update(1)
+	e.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Errorf("%d draws after all particles died; want 0", n)
+	}
+}
+
+func TestExplosionsOfAnyColors(t *testing.T) {
+	for _, colors := range [][]color.Color{
+		{pixel.RGB(1, 0, 0), color.NRGBA{0, 255, 0, 128}},
+		{},
+		{nil},
+	} {
+		e := NewExplosions(1000, 1000, colors, 4)
+		e.ExplodeAt(pixel.V(500, 500), pixel.V(10, 10))
+		e.Update(0.001)
+		e.Draw(visualtest.NewRecordingTarget())
+	}
+}
@@ -0,0 +1,69 @@
+package actors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/visualtest"
+	"golang.org/x/image/colornames"
+)
+
+func TestFPSWatch(t *testing.T) {
+	watch := NewFPSWatchSimple(pixel.ZV, super.Top, super.Right)
+	watch.PosOnScreen(800, 600)
+	rt := visualtest.NewRecordingTarget()
+
+	// Nothing is drawn until the first second passes.
+	watch.Start()
+	watch.Poll()
+	watch.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws before a second passed; want 0", n)
+	}
+
+	// The label gets updated in the background a second later.
+	time.Sleep(time.Second + time.Second/10)
+	watch.Poll()
+	for deadline := time.Now().Add(time.Second); len(rt.Draws()) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("nothing is drawn a second later")
+		}
+		time.Sleep(time.Millisecond)
+		watch.Draw(rt)
+	}
+	if fps := watch.GetFPS(); fps != 2 {
+		t.Errorf("FPS %d; want 2", fps)
+	}
+	if !rt.DrewPicture() {
+		t.Error("no text is drawn")
+	}
+
+	// The background is anchored at the top right corner of the screen.
+	found := false
+	for _, c := range rt.Draws() {
+		if c.HasPicture || len(c.Vertices) == 0 || !visualtest.SameColor(c.Vertices[0].Color, pixel.ToRGBA(colornames.Black)) {
+			continue
+		}
+		found = true
+		if b := c.Bounds(); b.Max.X > 800 || b.Max.Y > 610 || b.Min.X < 600 || b.Min.Y < 500 {
+			t.Errorf("background drawn at %v; want it at the top right corner of 800x600", b)
+		}
+	}
+	if !found {
+		t.Error("no background is drawn")
+	}
+}
+
+func TestFPSWatchSelfPolling(t *testing.T) {
+	watch := NewFPSWatchSimple(pixel.ZV, super.Top, super.Right)
+	watch.SetSelfPolling(true)
+	watch.Update(0)
+	watch.Update(0)
+	time.Sleep(time.Second + time.Second/10)
+	watch.Update(0)
+	if fps := watch.GetFPS(); fps != 3 {
+		t.Errorf("FPS %d; want 3", fps)
+	}
+}
@@ -0,0 +1,52 @@
+package actors
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// FrameGraph implements HUD.
+type FrameGraph struct {
+	*super.FrameGraph
+	futureAnchorY super.AnchorY // what reflects on screen resize
+	futureAnchorX super.AnchorX // what reflects on screen resize
+	offset        pixel.Vec     // from the anchor on screen
+}
+
+// NewFrameGraph is a constructor.
+// The graph is positioned at the anchor of the screen, and then moved by the offset.
+// For example, (0, -30) with Top and Right puts it just below an FPSWatch at the top right corner.
+func NewFrameGraph(
+	stats func() super.FrameStats, size pixel.Vec, offset pixel.Vec,
+	_anchorY super.AnchorY, _anchorX super.AnchorX, // This is because the order is usually Y then X in spoken language.
+) *FrameGraph {
+	return &FrameGraph{
+		FrameGraph:    super.NewFrameGraph(stats, size, offset, _anchorY, _anchorX),
+		futureAnchorX: _anchorX,
+		futureAnchorY: _anchorY,
+		offset:        offset,
+	}
+}
+
+// PosOnScreen implements the HUD interface that super.FrameGraph lacks of.
+func (graph *FrameGraph) PosOnScreen(width, height float64) {
+	pos := anchorOnScreen(width, height, graph.futureAnchorY, graph.futureAnchorX).Add(graph.offset)
+	graph.SetPos(pos, graph.futureAnchorY, graph.futureAnchorX)
+}
+
+// anchorOnScreen returns the position of an anchor on a screen.
+func anchorOnScreen(width, height float64, anchorY super.AnchorY, anchorX super.AnchorX) (pos pixel.Vec) {
+	switch anchorX {
+	case super.Center:
+		pos.X = width / 2
+	case super.Right:
+		pos.X = width
+	}
+	switch anchorY {
+	case super.Top:
+		pos.Y = height
+	case super.Middle:
+		pos.Y = height / 2
+	}
+	return pos
+}
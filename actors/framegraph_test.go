@@ -0,0 +1,55 @@
+package actors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/visualtest"
+	"golang.org/x/image/colornames"
+)
+
+func TestFrameGraph(t *testing.T) {
+	frame := func(total time.Duration) super.FrameTiming {
+		f := super.FrameTiming{Total: total}
+		f.Phases[super.PhaseDraw] = total / 2
+		f.Phases[super.PhaseVsync] = total - total/2
+		return f
+	}
+	stats := super.FrameStats{Frames: []super.FrameTiming{
+		frame(10 * time.Millisecond),
+		frame(40 * time.Millisecond), // spike
+		frame(10 * time.Millisecond),
+	}}
+	graph := NewFrameGraph(func() super.FrameStats { return stats }, pixel.V(300, 100), pixel.V(0, -30), super.Top, super.Right)
+	graph.PosOnScreen(800, 600)
+	rt := visualtest.NewRecordingTarget()
+
+	// Hidden by default.
+	graph.Update(0)
+	graph.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws while hidden; want 0", n)
+	}
+
+	graph.Toggle()
+	graph.Update(0)
+	graph.Draw(rt)
+	if b := graph.Bounds(); b != pixel.R(500, 470, 800, 570) {
+		t.Errorf("graph at %v; want it below the top right corner of 800x600", b)
+	}
+	if !rt.DrewPicture() {
+		t.Error("no label is drawn")
+	}
+	// 3 bars of 100px each from the left, scaled to 33.3ms at the top.
+	if !rt.DrewNear(colornames.Orange, pixel.V(550, 470+15/2), 20) {
+		t.Error("the draw phase of the first frame is not drawn")
+	}
+	if !rt.DrewNear(colornames.Red, pixel.V(650, 569), 60) {
+		t.Error("the spike is not highlighted")
+	}
+	if rt.DrewNear(colornames.Red, pixel.V(750, 500), 60) {
+		t.Error("a frame within the budget is highlighted")
+	}
+}
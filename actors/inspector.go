@@ -0,0 +1,34 @@
+package actors
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// Inspector implements HUD.
+type Inspector struct {
+	*super.Inspector
+	futureAnchorY super.AnchorY // what reflects on screen resize
+	futureAnchorX super.AnchorX // what reflects on screen resize
+	offset        pixel.Vec     // from the anchor on screen
+}
+
+// NewInspector is a constructor.
+// The inspector is positioned at the anchor of the screen, and then moved by the offset.
+func NewInspector(
+	list func() []super.InspectedItem, nRows int, offset pixel.Vec,
+	_anchorY super.AnchorY, _anchorX super.AnchorX, // This is because the order is usually Y then X in spoken language.
+) *Inspector {
+	return &Inspector{
+		Inspector:     super.NewInspector(list, nRows, offset, _anchorY, _anchorX),
+		futureAnchorX: _anchorX,
+		futureAnchorY: _anchorY,
+		offset:        offset,
+	}
+}
+
+// PosOnScreen implements the HUD interface that super.Inspector lacks of.
+func (ins *Inspector) PosOnScreen(width, height float64) {
+	pos := anchorOnScreen(width, height, ins.futureAnchorY, ins.futureAnchorX).Add(ins.offset)
+	ins.SetPos(pos, ins.futureAnchorY, ins.futureAnchorX)
+}
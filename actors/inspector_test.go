@@ -0,0 +1,44 @@
+package actors
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/visualtest"
+)
+
+func TestInspector(t *testing.T) {
+	explosions := NewExplosions(800, 600, nil, 4)
+	list := func() []super.InspectedItem {
+		return []super.InspectedItem{{Actor: explosions, Type: "*actors.Explosions", Visible: true, Active: true}}
+	}
+	ins := NewInspector(list, 10, pixel.V(-2, -2), super.Top, super.Right)
+	ins.PosOnScreen(800, 600)
+	rt := visualtest.NewRecordingTarget()
+
+	// Hidden by default.
+	ins.Update(1)
+	ins.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws while hidden; want 0", n)
+	}
+
+	ins.SetVisible(true)
+	ins.Update(0)
+	ins.Draw(rt)
+	if !rt.DrewPicture() {
+		t.Fatal("no list is drawn")
+	}
+	for _, c := range rt.Draws() {
+		if c.HasPicture {
+			continue
+		}
+		if b := c.Bounds(); b.Max.X > 800 || b.Max.Y > 600 || b.Min.Y < 300 {
+			t.Errorf("background drawn at %v; want it at the top right corner of 800x600", b)
+		}
+	}
+	if item, _, ok := ins.Selected(); !ok || item.Actor != explosions {
+		t.Error("the only actor is not selected")
+	}
+}
@@ -0,0 +1,76 @@
+package actors
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// Shape implements Actor, and HUD as well to be drawn in screen coords.
+type Shape struct {
+	*super.Shape
+}
+
+// NewShape is a constructor. (See super.NewShape().)
+func NewShape(kind super.ShapeKind, points []pixel.Vec, radius, thickness float64, col color.Color) (*Shape, error) {
+	shape, err := super.NewShape(kind, points, radius, thickness, col)
+	if err != nil {
+		return nil, err
+	}
+	return &Shape{shape}, nil
+}
+
+// Update implements the Updater interface that super.Shape lacks of.
+func (shape *Shape) Update(_ float64) {
+	// empty.
+}
+
+// PosOnScreen implements the HUD interface that super.Shape lacks of.
+// It stays where it is in screen coords.
+func (shape *Shape) PosOnScreen(width, height float64) {
+	// empty.
+}
+
+// Label implements Actor, and HUD as well to be drawn in screen coords.
+type Label struct {
+	*super.Label
+}
+
+// NewLabel is a constructor. (See super.NewLabel().)
+func NewLabel(str string, pos pixel.Vec, scale float64, col color.Color) *Label {
+	return &Label{super.NewLabel(str, pos, scale, col)}
+}
+
+// Update implements the Updater interface that super.Label lacks of.
+func (label *Label) Update(_ float64) {
+	// empty.
+}
+
+// PosOnScreen implements the HUD interface that super.Label lacks of.
+// It stays where it is in screen coords.
+func (label *Label) PosOnScreen(width, height float64) {
+	// empty.
+}
+
+// Sprite implements Actor, and HUD as well to be drawn in screen coords.
+type Sprite struct {
+	*super.Sprite
+}
+
+// NewSprite is a constructor. (See super.NewSprite().)
+func NewSprite(img image.Image, pos pixel.Vec, scale, degree float64) *Sprite {
+	return &Sprite{super.NewSprite(img, pos, scale, degree)}
+}
+
+// Update implements the Updater interface that super.Sprite lacks of.
+func (sprite *Sprite) Update(_ float64) {
+	// empty.
+}
+
+// PosOnScreen implements the HUD interface that super.Sprite lacks of.
+// It stays where it is in screen coords.
+func (sprite *Sprite) PosOnScreen(width, height float64) {
+	// empty.
+}
@@ -0,0 +1,74 @@
+package actors
+
+import (
+	"image"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/visualtest"
+	"golang.org/x/image/colornames"
+)
+
+func TestShape(t *testing.T) {
+	if _, err := NewShape(super.ShapeCircle, []pixel.Vec{pixel.V(0, 0)}, 0, 0, colornames.Red); err == nil {
+		t.Error("a circle of radius 0 is made")
+	}
+	if _, err := NewShape(super.ShapePolygon, []pixel.Vec{pixel.V(0, 0), pixel.V(1, 1)}, 0, 0, colornames.Red); err == nil {
+		t.Error("a polygon of 2 points is made")
+	}
+
+	shape, err := NewShape(super.ShapeRect, []pixel.Vec{pixel.V(10, 20), pixel.V(110, 70)}, 0, 0, colornames.Red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := visualtest.NewRecordingTarget()
+	shape.Draw(rt)
+	if !rt.DrewNear(pixel.ToRGBA(colornames.Red), pixel.V(60, 45), 50) {
+		t.Error("a red rect is not drawn")
+	}
+	if b := shape.Bounds(); b != pixel.R(10, 20, 110, 70) {
+		t.Errorf("bounds %v", b)
+	}
+
+	if err := shape.Set(super.ShapeCircle, []pixel.Vec{pixel.V(200, 200)}, 10, 2, colornames.Blue); err != nil {
+		t.Fatal(err)
+	}
+	rt.Reset()
+	shape.Draw(rt)
+	if !rt.DrewNear(pixel.ToRGBA(colornames.Blue), pixel.V(200, 200), 15) {
+		t.Error("a blue circle is not drawn after set")
+	}
+	if b := shape.Bounds(); b != pixel.R(190, 190, 210, 210) {
+		t.Errorf("bounds %v", b)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	label := NewLabel("hello", pixel.V(100, 100), 2, colornames.White)
+	rt := visualtest.NewRecordingTarget()
+	label.Draw(rt)
+	if !rt.DrewPicture() {
+		t.Error("no text is drawn")
+	}
+	small := NewLabel("hello", pixel.V(100, 100), 1, colornames.White)
+	if b, s := label.Bounds(), small.Bounds(); b.W() < 1.9*s.W() || b.Min.X < 99 {
+		t.Errorf("bounds %v scaled by 2; %v by 1", b, s)
+	}
+}
+
+func TestSprite(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	sprite := NewSprite(img, pixel.V(100, 100), 2, 90)
+	rt := visualtest.NewRecordingTarget()
+	sprite.Draw(rt)
+	if n := len(rt.Draws()); n != 1 {
+		t.Errorf("%d draws; want 1", n)
+	}
+	if b := sprite.Bounds(); b.W() < 19.9 || b.W() > 20.1 || b.H() < 39.9 || b.H() > 40.1 || b.Center().Sub(pixel.V(100, 100)).Len() > 0.1 {
+		t.Errorf("bounds %v of 20x10 scaled by 2 and turned 90 degrees at (100, 100)", b)
+	}
+}
@@ -0,0 +1,34 @@
+package actors
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// ProfileView implements HUD.
+type ProfileView struct {
+	*super.ProfileView
+	futureAnchorY super.AnchorY // what reflects on screen resize
+	futureAnchorX super.AnchorX // what reflects on screen resize
+	offset        pixel.Vec     // from the anchor on screen
+}
+
+// NewProfileView is a constructor.
+// The view is positioned at the anchor of the screen, and then moved by the offset.
+func NewProfileView(
+	profiler *super.Profiler, nRows int, offset pixel.Vec,
+	_anchorY super.AnchorY, _anchorX super.AnchorX, // This is because the order is usually Y then X in spoken language.
+) *ProfileView {
+	return &ProfileView{
+		ProfileView:   super.NewProfileView(profiler, nRows, offset, _anchorY, _anchorX),
+		futureAnchorX: _anchorX,
+		futureAnchorY: _anchorY,
+		offset:        offset,
+	}
+}
+
+// PosOnScreen implements the HUD interface that super.ProfileView lacks of.
+func (view *ProfileView) PosOnScreen(width, height float64) {
+	pos := anchorOnScreen(width, height, view.futureAnchorY, view.futureAnchorX).Add(view.offset)
+	view.SetPos(pos, view.futureAnchorY, view.futureAnchorX)
+}
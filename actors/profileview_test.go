@@ -0,0 +1,48 @@
+package actors
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/visualtest"
+)
+
+func TestProfileView(t *testing.T) {
+	profiler := super.NewProfiler()
+	profiler.SetEnabled(true)
+	explosions := NewExplosions(800, 600, nil, 4)
+	profiler.Frame()
+	profiler.Measure(explosions, 0, super.ProfileUpdate, func() { explosions.Update(1.0 / 60) })
+
+	view := NewProfileView(profiler, 10, pixel.V(2, -2), super.Top, super.Left)
+	view.PosOnScreen(800, 600)
+	rt := visualtest.NewRecordingTarget()
+
+	// Hidden by default.
+	view.Update(1)
+	view.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws while hidden; want 0", n)
+	}
+
+	view.SetVisible(true)
+	view.Update(0)
+	view.Draw(rt)
+	if !rt.DrewPicture() {
+		t.Fatal("no list is drawn")
+	}
+	for _, c := range rt.Draws() {
+		if c.HasPicture {
+			continue
+		}
+		if b := c.Bounds(); b.Min.X < 0 || b.Max.Y > 600 || b.Min.Y < 300 {
+			t.Errorf("background drawn at %v; want it at the top left corner of 800x600", b)
+		}
+	}
+
+	view.NextSort()
+	if by, byType := view.Sort(); by != super.SortByUpdate || !byType {
+		t.Errorf("sorted by %v, by type %v; want by update, by type", by, byType)
+	}
+}
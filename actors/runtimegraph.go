@@ -0,0 +1,34 @@
+package actors
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// RuntimeGraph implements HUD.
+type RuntimeGraph struct {
+	*super.RuntimeGraph
+	futureAnchorY super.AnchorY // what reflects on screen resize
+	futureAnchorX super.AnchorX // what reflects on screen resize
+	offset        pixel.Vec     // from the anchor on screen
+}
+
+// NewRuntimeGraph is a constructor.
+// The graph is positioned at the anchor of the screen, and then moved by the offset.
+func NewRuntimeGraph(
+	samples func() []super.RuntimeSample, size pixel.Vec, offset pixel.Vec,
+	_anchorY super.AnchorY, _anchorX super.AnchorX, // This is because the order is usually Y then X in spoken language.
+) *RuntimeGraph {
+	return &RuntimeGraph{
+		RuntimeGraph:  super.NewRuntimeGraph(samples, size, offset, _anchorY, _anchorX),
+		futureAnchorX: _anchorX,
+		futureAnchorY: _anchorY,
+		offset:        offset,
+	}
+}
+
+// PosOnScreen implements the HUD interface that super.RuntimeGraph lacks of.
+func (graph *RuntimeGraph) PosOnScreen(width, height float64) {
+	pos := anchorOnScreen(width, height, graph.futureAnchorY, graph.futureAnchorX).Add(graph.offset)
+	graph.SetPos(pos, graph.futureAnchorY, graph.futureAnchorX)
+}
@@ -0,0 +1,42 @@
+package actors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/visualtest"
+	"golang.org/x/image/colornames"
+)
+
+func TestRuntimeGraph(t *testing.T) {
+	samples := []super.RuntimeSample{
+		{HeapBytes: 1 << 20, GCPauseMax: time.Millisecond, Goroutines: 4},
+		{HeapBytes: 2 << 20, AllocRate: 1 << 20, Goroutines: 5},
+	}
+	graph := NewRuntimeGraph(func() []super.RuntimeSample { return samples }, pixel.V(300, 160), pixel.V(0, -200), super.Top, super.Right)
+	graph.PosOnScreen(800, 600)
+	rt := visualtest.NewRecordingTarget()
+
+	// Hidden by default.
+	graph.Update(0)
+	graph.Draw(rt)
+	if n := len(rt.Draws()); n != 0 {
+		t.Fatalf("%d draws while hidden; want 0", n)
+	}
+
+	graph.SetVisible(true)
+	graph.Update(0)
+	graph.Draw(rt)
+	if b := graph.Bounds(); b != pixel.R(500, 240, 800, 400) {
+		t.Errorf("graph at %v; want it at the right of 800x600", b)
+	}
+	if !rt.DrewPicture() {
+		t.Error("no label is drawn")
+	}
+	// The heap row on top rises to the right.
+	if !rt.DrewNear(colornames.Deepskyblue, pixel.V(650, 340), 30) {
+		t.Error("the heap is not graphed")
+	}
+}
@@ -1,36 +1,52 @@
-package atlas
-
-import (
-	"github.com/faiface/pixel/text"
-	"github.com/golang/freetype/truetype"
-	"github.com/nanitefactory/bindat/bindatkuji"
-	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
-)
-
-func newAtlasASCII(size float64) *text.Atlas {
-	newTrueTypeFontFaceFromBin := func(bytes []byte, size float64) (font.Face, error) {
-		font, err := truetype.Parse(bytes)
-		if err != nil {
-			return nil, err
-		}
-		return truetype.NewFace(font, &truetype.Options{
-			Size:              size,
-			GlyphCacheEntries: 1,
-		}), nil
-	}
-	return text.NewAtlas(func() font.Face {
-		if binTTF, err := bindatkuji.Asset("NanumBarunGothic.ttf"); err == nil {
-			if retFace, err := newTrueTypeFontFaceFromBin(binTTF, size); err == nil {
-				return retFace
-			}
-		}
-		return basicfont.Face7x13
-	}(), text.ASCII, nil)
-}
-
-// AtlasASCII36 is an atlas of font size 36 that's to draw only ASCII characters.
-var AtlasASCII36 = newAtlasASCII(36)
-
-// AtlasASCII18 is an atlas of font size 18 that's to draw only ASCII characters.
-var AtlasASCII18 = newAtlasASCII(18)
+package atlas
+
+import (
+	"fmt"
+
+	"github.com/faiface/pixel/text"
+	"github.com/golang/freetype/truetype"
+	"github.com/nanitefactory/bindat/bindatkuji"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+func newAtlasASCII(size float64) *text.Atlas {
+	newTrueTypeFontFaceFromBin := func(bytes []byte, size float64) (font.Face, error) {
+		font, err := truetype.Parse(bytes)
+		if err != nil {
+			return nil, err
+		}
+		return truetype.NewFace(font, &truetype.Options{
+			Size:              size,
+			GlyphCacheEntries: 1,
+		}), nil
+	}
+	return text.NewAtlas(func() font.Face {
+		binTTF, err := bindatkuji.Asset("NanumBarunGothic.ttf")
+		if err == nil {
+			var retFace font.Face
+			if retFace, err = newTrueTypeFontFaceFromBin(binTTF, size); err == nil {
+				return retFace
+			}
+		}
+		if fallbackErr == nil {
+			fallbackErr = fmt.Errorf("atlas: NanumBarunGothic.ttf: %v", err)
+		}
+		return basicfont.Face7x13
+	}(), text.ASCII, nil)
+}
+
+// fallbackErr is why atlases fell back on basicfont.Face7x13, if they did.
+var fallbackErr error
+
+// FallbackErr returns the error why the atlases fell back on the basic font, which is rather small.
+// It returns nil if they have the font they were meant to have.
+func FallbackErr() error {
+	return fallbackErr
+}
+
+// AtlasASCII36 is an atlas of font size 36 that's to draw only ASCII characters.
+var AtlasASCII36 = newAtlasASCII(36)
+
+// AtlasASCII18 is an atlas of font size 18 that's to draw only ASCII characters.
+var AtlasASCII18 = newAtlasASCII(18)
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Features
+
+// feature is a point, a line or a polygon read from data.
+type feature struct {
+	kind   super.ShapeKind // ShapeCircle for a point, ShapeLine or ShapePolygon
+	points []pixel.Vec
+}
+
+// boundsOf returns the smallest rectangle that has all features in.
+func boundsOf(features []feature) pixel.Rect {
+	first := true
+	bounds := pixel.Rect{}
+	for _, f := range features {
+		for _, p := range f.points {
+			if first {
+				bounds = pixel.Rect{Min: p, Max: p}
+				first = false
+				continue
+			}
+			bounds.Min = pixel.V(min(bounds.Min.X, p.X), min(bounds.Min.Y, p.Y))
+			bounds.Max = pixel.V(max(bounds.Max.X, p.X), max(bounds.Max.Y, p.Y))
+		}
+	}
+	return bounds
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// -------------------------------------------------------------------------
+// Formats
+
+// Formats of data.
+const (
+	formatAuto    = "auto"
+	formatCSV     = "csv"
+	formatNDJSON  = "ndjson"
+	formatGeoJSON = "geojson"
+)
+
+// Ways points in rows of CSV or NDJSON are joined, within each group.
+const (
+	joinNone    = "none"
+	joinLine    = "line"
+	joinPolygon = "polygon"
+)
+
+// Column names or keys of coordinates and groups in rows, in lower case.
+var (
+	xKeys     = []string{"x", "lon", "lng", "long", "longitude"}
+	yKeys     = []string{"y", "lat", "latitude"}
+	groupKeys = []string{"group", "id"}
+)
+
+// detectFormat tells the format of data by its name, or by what it begins with if the name says nothing.
+func detectFormat(name string, head []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".txt":
+		return formatCSV
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	case ".geojson", ".json":
+		return formatGeoJSON
+	}
+	head = bytes.TrimSpace(head)
+	if !bytes.HasPrefix(head, []byte("{")) {
+		return formatCSV
+	}
+	// A single GeoJSON object spans lines, while NDJSON is an object a line.
+	line := head
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		line = head[:i]
+	}
+	line = bytes.TrimSpace(line)
+	if json.Valid(line) && (len(line) < len(head) || !bytes.Contains(line, []byte(`"type"`))) {
+		return formatNDJSON
+	}
+	return formatGeoJSON
+}
+
+// readData reads features out of data in a format, sniffed if formatAuto.
+// Points in rows of CSV or NDJSON are joined into lines or polygons by groups, unless joinNone.
+func readData(r io.Reader, name, format, join string) ([]feature, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	if format == formatAuto {
+		head, _ := br.Peek(br.Size())
+		format = detectFormat(name, head)
+	}
+	var rows []row
+	var features []feature
+	var err error
+	switch format {
+	case formatCSV:
+		rows, err = readCSV(br)
+	case formatNDJSON:
+		rows, features, err = readNDJSON(br)
+	case formatGeoJSON:
+		var g geoJSON
+		if err = json.NewDecoder(br).Decode(&g); err == nil {
+			features, err = g.features()
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	joined, err := joinRows(rows, join)
+	if err != nil {
+		return nil, err
+	}
+	return append(features, joined...), nil
+}
+
+// row is a point in a row of CSV or NDJSON.
+type row struct {
+	pos   pixel.Vec
+	group string
+}
+
+// joinRows makes points out of rows, or joins them by groups in the order they first appear.
+// A group with too few points to join stays as points.
+func joinRows(rows []row, join string) ([]feature, error) {
+	kind := super.ShapeLine
+	switch join {
+	case joinNone:
+		features := make([]feature, len(rows))
+		for i, r := range rows {
+			features[i] = feature{super.ShapeCircle, []pixel.Vec{r.pos}}
+		}
+		return features, nil
+	case joinLine:
+	case joinPolygon:
+		kind = super.ShapePolygon
+	default:
+		return nil, fmt.Errorf("unknown way to join %q", join)
+	}
+
+	order := []string{}
+	groups := map[string][]pixel.Vec{}
+	for _, r := range rows {
+		if _, ok := groups[r.group]; !ok {
+			order = append(order, r.group)
+		}
+		groups[r.group] = append(groups[r.group], r.pos)
+	}
+	features := []feature{}
+	for _, group := range order {
+		points := groups[group]
+		if (kind == super.ShapeLine && len(points) < 2) || (kind == super.ShapePolygon && len(points) < 3) {
+			for _, p := range points {
+				features = append(features, feature{super.ShapeCircle, []pixel.Vec{p}})
+			}
+			continue
+		}
+		features = append(features, feature{kind, points})
+	}
+	return features, nil
+}
+
+// -------------------------------------------------------------------------
+// CSV
+
+// readCSV reads a point a row.
+// The header names columns of coordinates, such as x and y or lon and lat, and optionally a group.
+// Without a header, the first two columns are x and y.
+func readCSV(r io.Reader) ([]row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	xCol, yCol, groupCol := 0, 1, -1
+	rows := []row{}
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && !isNumber(record[0]) {
+			xCol, yCol, groupCol = -1, -1, -1
+			for i, name := range record {
+				name = strings.ToLower(strings.TrimSpace(name))
+				switch {
+				case xCol < 0 && contains(xKeys, name):
+					xCol = i
+				case yCol < 0 && contains(yKeys, name):
+					yCol = i
+				case groupCol < 0 && contains(groupKeys, name):
+					groupCol = i
+				}
+			}
+			if xCol < 0 || yCol < 0 {
+				return nil, fmt.Errorf("no columns of coordinates in the header %q", record)
+			}
+			continue
+		}
+		if xCol >= len(record) || yCol >= len(record) {
+			return nil, fmt.Errorf("line %d: too few columns", line)
+		}
+		x, errX := strconv.ParseFloat(strings.TrimSpace(record[xCol]), 64)
+		y, errY := strconv.ParseFloat(strings.TrimSpace(record[yCol]), 64)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("line %d: invalid coordinates %q, %q", line, record[xCol], record[yCol])
+		}
+		r := row{pos: pixel.V(x, y)}
+		if 0 <= groupCol && groupCol < len(record) {
+			r.group = record[groupCol]
+		}
+		rows = append(rows, r)
+	}
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------------------------------------------------------------
+// NDJSON
+
+// readNDJSON reads an object a line; a point with coordinates such as "x" and "y" or "lon" and "lat",
+// and optionally a "group", or else a GeoJSON object with a "type".
+func readNDJSON(r io.Reader) ([]row, []feature, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	rows := []row{}
+	features := []feature{}
+	for line := 1; sc.Scan(); line++ {
+		data := bytes.TrimSpace(sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if _, ok := obj["type"]; ok {
+			var g geoJSON
+			if err := json.Unmarshal(data, &g); err != nil {
+				return nil, nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			fs, err := g.features()
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			features = append(features, fs...)
+			continue
+		}
+		x, okX := lookupNumber(obj, xKeys)
+		y, okY := lookupNumber(obj, yKeys)
+		if !okX || !okY {
+			return nil, nil, fmt.Errorf("line %d: no coordinates", line)
+		}
+		r := row{pos: pixel.V(x, y)}
+		for _, key := range groupKeys {
+			if raw, ok := obj[key]; ok {
+				var s string
+				if json.Unmarshal(raw, &s) != nil {
+					s = string(raw) // a number, for example
+				}
+				r.group = s
+				break
+			}
+		}
+		rows = append(rows, r)
+	}
+	return rows, features, sc.Err()
+}
+
+// lookupNumber returns the number of the first key there is, whatever the case of the key is.
+func lookupNumber(obj map[string]json.RawMessage, keys []string) (float64, bool) {
+	for k, raw := range obj {
+		if !contains(keys, strings.ToLower(k)) {
+			continue
+		}
+		var f float64
+		if json.Unmarshal(raw, &f) == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// -------------------------------------------------------------------------
+// GeoJSON
+
+// geoJSON is any GeoJSON object; a feature collection, a feature or a geometry.
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Features    []geoJSON       `json:"features"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Geometries  []geoJSON       `json:"geometries"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// features returns what's drawn of an object.
+// The outer ring of a polygon is a polygon, and its holes are drawn as lines around.
+func (g geoJSON) features() ([]feature, error) {
+	features := []feature{}
+	add := func(kind super.ShapeKind, positions [][]float64) error {
+		points := make([]pixel.Vec, len(positions))
+		for i, p := range positions {
+			if len(p) < 2 {
+				return fmt.Errorf("%s: position of %d numbers", g.Type, len(p))
+			}
+			points[i] = pixel.V(p[0], p[1])
+		}
+		if kind == super.ShapePolygon && len(points) > 1 && points[0] == points[len(points)-1] {
+			points = points[:len(points)-1] // A ring ends where it begins.
+		}
+		if (kind == super.ShapeLine && len(points) < 2) || (kind == super.ShapePolygon && len(points) < 3) {
+			return fmt.Errorf("%s: too few positions", g.Type)
+		}
+		features = append(features, feature{kind, points})
+		return nil
+	}
+	addPolygon := func(rings [][][]float64) error {
+		for i, ring := range rings {
+			kind := super.ShapePolygon
+			if i > 0 {
+				kind = super.ShapeLine
+			}
+			if err := add(kind, ring); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch g.Type {
+	case "FeatureCollection":
+		for _, f := range g.Features {
+			fs, err := f.features()
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, fs...)
+		}
+	case "Feature":
+		if g.Geometry == nil {
+			return features, nil // unlocated
+		}
+		return g.Geometry.features()
+	case "GeometryCollection":
+		for _, geometry := range g.Geometries {
+			fs, err := geometry.features()
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, fs...)
+		}
+	case "Point":
+		var p []float64
+		if err = json.Unmarshal(g.Coordinates, &p); err == nil {
+			err = add(super.ShapeCircle, [][]float64{p})
+		}
+	case "MultiPoint":
+		var ps [][]float64
+		if err = json.Unmarshal(g.Coordinates, &ps); err == nil {
+			for _, p := range ps {
+				if err = add(super.ShapeCircle, [][]float64{p}); err != nil {
+					break
+				}
+			}
+		}
+	case "LineString":
+		var ps [][]float64
+		if err = json.Unmarshal(g.Coordinates, &ps); err == nil {
+			err = add(super.ShapeLine, ps)
+		}
+	case "MultiLineString":
+		var lines [][][]float64
+		if err = json.Unmarshal(g.Coordinates, &lines); err == nil {
+			for _, ps := range lines {
+				if err = add(super.ShapeLine, ps); err != nil {
+					break
+				}
+			}
+		}
+	case "Polygon":
+		var rings [][][]float64
+		if err = json.Unmarshal(g.Coordinates, &rings); err == nil {
+			err = addPolygon(rings)
+		}
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err = json.Unmarshal(g.Coordinates, &polygons); err == nil {
+			for _, rings := range polygons {
+				if err = addPolygon(rings); err != nil {
+					break
+				}
+			}
+		}
+	case "":
+		return nil, errors.New("not a GeoJSON object")
+	default:
+		return nil, fmt.Errorf("unknown GeoJSON type %q", g.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return features, nil
+}
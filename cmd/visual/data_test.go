@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for _, c := range []struct {
+		name, head, want string
+	}{
+		{"points.csv", "{", formatCSV},
+		{"tracks.jsonl", "", formatNDJSON},
+		{"map.geojson", "", formatGeoJSON},
+		{"stdin", "x,y\n1,2\n", formatCSV},
+		{"stdin", "{\"x\": 1, \"y\": 2}\n{\"x\": 3, \"y\": 4}\n", formatNDJSON},
+		{"stdin", "{\"x\": 1, \"y\": 2}\n", formatNDJSON},
+		{"stdin", "{\"type\": \"Point\", \"coordinates\": [1, 2]}\n", formatGeoJSON},
+		{"stdin", "{\n  \"type\": \"FeatureCollection\",\n  \"features\": []\n}\n", formatGeoJSON},
+	} {
+		if got := detectFormat(c.name, []byte(c.head)); got != c.want {
+			t.Errorf("detectFormat(%q, %q) = %q; want %q", c.name, c.head, got, c.want)
+		}
+	}
+}
+
+// describe features briefly, such as "circle(1 2) line(0 0, 1 1)".
+func describe(features []feature) string {
+	strs := []string{}
+	for _, f := range features {
+		points := []string{}
+		for _, p := range f.points {
+			points = append(points, fmt.Sprint(p.X, " ", p.Y))
+		}
+		strs = append(strs, f.kind.String()+"("+strings.Join(points, ", ")+")")
+	}
+	return strings.Join(strs, " ")
+}
+
+func TestReadData(t *testing.T) {
+	for _, c := range []struct {
+		data, join, want string
+	}{
+		{"1,2\n3,4\n", joinNone, "circle(1 2) circle(3 4)"},
+		{"id,lat,lon\na,2,1\nb,4,3\na,6,5\n", joinLine, "line(1 2, 5 6) circle(3 4)"},
+		{"x,y,group\n0,0,a\n1,0,a\n1,1,a\n", joinPolygon, "polygon(0 0, 1 0, 1 1)"},
+		{`{"x": 1, "y": 2, "group": 7}` + "\n" + `{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}` + "\n" + `{"X": 3, "Y": 4, "group": 7}` + "\n", joinLine,
+			"line(0 0, 1 1) line(1 2, 3 4)"},
+		{`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2, 100]}},
+			{"type": "Feature", "geometry": null},
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [4, 0], [4, 4], [0, 0]], [[1, 1], [2, 1], [1, 2], [1, 1]]]}},
+			{"type": "Feature", "geometry": {"type": "GeometryCollection", "geometries": [{"type": "MultiPoint", "coordinates": [[5, 5], [6, 6]]}]}}
+		]}`, joinNone, "circle(1 2) polygon(0 0, 4 0, 4 4) line(1 1, 2 1, 1 2, 1 1) circle(5 5) circle(6 6)"},
+	} {
+		features, err := readData(strings.NewReader(c.data), "stdin", formatAuto, c.join)
+		if err != nil {
+			t.Errorf("%q: %v", c.data, err)
+			continue
+		}
+		if got := describe(features); got != c.want {
+			t.Errorf("%q read as %s; want %s", c.data, got, c.want)
+		}
+	}
+
+	for _, data := range []string{
+		"name,value\na,1\n",
+		"1,2\n3,four\n",
+		`{"x": 1}` + "\n" + `{"x": 2}` + "\n",
+		`{"type": "LineString", "coordinates": [[0, 0]]}`,
+		`{"type": "Circle", "coordinates": [0, 0]}`,
+	} {
+		if _, err := readData(strings.NewReader(data), "stdin", formatAuto, joinNone); err == nil {
+			t.Errorf("%q: no error", data)
+		}
+	}
+}
+
+func TestBoundsOf(t *testing.T) {
+	features := []feature{
+		{super.ShapeCircle, []pixel.Vec{pixel.V(-1, 5)}},
+		{super.ShapeLine, []pixel.Vec{pixel.V(2, 3), pixel.V(4, -2)}},
+	}
+	if got, want := boundsOf(features), pixel.R(-1, -2, 4, 5); got != want {
+		t.Errorf("bounds %v; want %v", got, want)
+	}
+}
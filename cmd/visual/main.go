@@ -0,0 +1,184 @@
+// Command visual is a standalone viewer of data files; points, lines and polygons in CSV, NDJSON or GeoJSON.
+//
+//	visual [flags] [file ...]
+//	cat points.csv | visual [flags]
+//
+// Data is read from the files given, or from stdin if none or "-".
+// Its format is told by the extension, or sniffed; (See -format.)
+//
+//   - CSV is a point a row. The header names columns of coordinates, such as x and y or lon and lat,
+//     and optionally a group. Without a header, the first two columns are x and y.
+//   - NDJSON is a point a line such as {"x": 1, "y": 2, "group": "a"}, or a GeoJSON object a line.
+//   - GeoJSON is a feature collection, a feature or a geometry of any type.
+//
+// Points in rows of CSV or NDJSON can be joined into lines or polygons by groups. (See -join.)
+// The camera fits all data in at first, and it's controlled just as in any visualizer;
+// Arrows to move, Wheeling to zoom and Tab to rotate. The console command "fit" fits it again.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual"
+	"github.com/nanitefactory/visual/super"
+)
+
+// fitMargin is how much of the window data takes up as the camera fits it in.
+const fitMargin = 0.9
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("visual: ")
+
+	var (
+		configPath = flag.String("config", "", "config `file` in JSON, YAML or TOML that flags override")
+		format     = flag.String("format", formatAuto, "format of data: auto, csv, ndjson or geojson")
+		join       = flag.String("join", joinNone, "how points in rows of CSV or NDJSON are joined by groups: none, line or polygon")
+		col        = flag.String("color", "deepskyblue", "`color` of points and lines, and outlines of polygons")
+		fill       = flag.String("fill", "#00bfff40", "`color` of polygons")
+		pointSize  = flag.Float64("size", 3, "radius of points in pixels")
+		lineWidth  = flag.Float64("width", 2, "width of lines in pixels")
+		bg         = flag.String("bg", "", "background `color`")
+		title      = flag.String("title", "", "title of the window; the names of files by default")
+		winWidth   = flag.Float64("win-width", 0, "width of the window")
+		winHeight  = flag.Float64("win-height", 0, "height of the window")
+		centered   = flag.Bool("centered", false, "center the window on the primary monitor")
+		headless   = flag.Bool("headless", false, "hide the window")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [file ...]\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// config
+	cfg := visual.Config{}
+	if *configPath != "" {
+		var err error
+		if cfg, err = visual.LoadConfig(*configPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var err error
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "bg":
+			if cfg.Bg, err = visual.ParseColor(*bg); err != nil {
+				log.Fatal(err)
+			}
+		case "title":
+			cfg.Title = *title
+		case "win-width":
+			cfg.WinWidth = *winWidth
+		case "win-height":
+			cfg.WinHeight = *winHeight
+		case "centered":
+			cfg.WinCentered = *centered
+		case "headless":
+			cfg.Headless = *headless
+		}
+	})
+	if *configPath == "" && *title == "" {
+		cfg.Title = strings.Join(names(flag.Args()), ", ")
+	}
+	if cfg.WinWidth == 0 {
+		cfg.WinWidth = visual.DefaultWinWidth
+	}
+	if cfg.WinHeight == 0 {
+		cfg.WinHeight = visual.DefaultWinHeight
+	}
+	s := style{pointSize: *pointSize, lineWidth: *lineWidth}
+	if s.color, err = visual.ParseColor(*col); err != nil {
+		log.Fatal(err)
+	}
+	if s.fill, err = visual.ParseColor(*fill); err != nil {
+		log.Fatal(err)
+	}
+
+	// data
+	features, err := readFiles(flag.Args(), *format, *join)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(features) == 0 {
+		log.Fatal("no data")
+	}
+	bounds := boundsOf(features)
+
+	// visualizer
+	var v *visual.Visualizer
+	p := newPlot(features, s, func() float64 { return v.Camera().Z() })
+	if v, err = visual.NewVisualizer(cfg, nil, p); err != nil {
+		log.Fatal(err)
+	}
+	fitIn := func() { fit(v.Camera(), bounds, pixel.V(cfg.WinWidth, cfg.WinHeight)) }
+	v.RegisterCommand("fit", "fit - fits the camera to all data", func(args []string) string {
+		fitIn()
+		return ""
+	})
+	v.Invoke(fitIn) // once it runs
+	v.Run()
+}
+
+// readFiles reads features out of files, or stdin if none or "-".
+func readFiles(paths []string, format, join string) ([]feature, error) {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	features := []feature{}
+	for _, path := range paths {
+		name, r := "stdin", io.Reader(os.Stdin)
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			name, r = path, f
+		}
+		fs, err := readData(r, name, format, join)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, fs...)
+	}
+	return features, nil
+}
+
+// names returns the base names of files, where "-" or none is stdin.
+func names(paths []string) []string {
+	if len(paths) == 0 {
+		return []string{"stdin"}
+	}
+	ret := make([]string, len(paths))
+	for i, path := range paths {
+		ret[i] = filepath.Base(path)
+		if path == "-" {
+			ret[i] = "stdin"
+		}
+	}
+	return ret
+}
+
+// fit gets a camera to show all of the bounds on a window of a size, at once.
+func fit(camera *super.Camera, bounds pixel.Rect, winSize pixel.Vec) {
+	zoom := 1.0
+	switch w, h := bounds.W(), bounds.H(); {
+	case w > 0 && h > 0:
+		zoom = min(winSize.X/w, winSize.Y/h)
+	case w > 0:
+		zoom = winSize.X / w
+	case h > 0:
+		zoom = winSize.Y / h
+	}
+	camera.MoveTo(bounds.Center())
+	camera.ZoomTo(zoom * fitMargin)
+	camera.Jump()
+}
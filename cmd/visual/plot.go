@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Plot
+
+// style is what features look like. Sizes are in pixels on the screen, whatever the zoom is.
+type style struct {
+	color     pixel.RGBA // of points and lines, and outlines of polygons
+	fill      pixel.RGBA // of polygons
+	pointSize float64    // radius
+	lineWidth float64
+}
+
+// plot is an actor that draws features all at once.
+type plot struct {
+	features []feature
+	style    style
+	zoom     func() float64 // of the camera, to keep sizes on the screen
+	imd      *imdraw.IMDraw
+	drawnAt  float64 // the zoom the imd is drawn at
+}
+
+func newPlot(features []feature, s style, zoom func() float64) *plot {
+	return &plot{features: features, style: s, zoom: zoom, imd: imdraw.New(nil)}
+}
+
+// Update implements visual.Updater.
+func (p *plot) Update(_ float64) {
+}
+
+// Draw implements visual.Drawer. It redraws features only once the zoom has changed.
+func (p *plot) Draw(t pixel.Target) {
+	if zoom := p.zoom(); zoom != p.drawnAt && zoom > 0 {
+		p.drawnAt = zoom
+		p._Redraw(1 / zoom)
+	}
+	p.imd.Draw(t)
+}
+
+// unexported
+func (p *plot) _Redraw(perPixel float64) {
+	imd := p.imd
+	imd.Clear()
+	imd.Reset()
+	lineWidth := p.style.lineWidth * perPixel
+
+	// polygons first, so that points and lines are on top of them
+	for _, f := range p.features {
+		if f.kind != super.ShapePolygon {
+			continue
+		}
+		imd.Color = p.style.fill
+		imd.Push(f.points...)
+		imd.Polygon(0)
+		if lineWidth > 0 {
+			imd.Color = p.style.color
+			imd.Push(f.points...)
+			imd.Push(f.points[0])
+			imd.Line(lineWidth)
+		}
+	}
+	for _, f := range p.features {
+		imd.Color = p.style.color
+		switch f.kind {
+		case super.ShapeLine:
+			imd.Push(f.points...)
+			imd.Line(lineWidth)
+		case super.ShapeCircle:
+			imd.Push(f.points[0])
+			imd.Circle(p.style.pointSize*perPixel, 0)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package visual
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/drawproto"
+	"github.com/nanitefactory/visual/logger"
+	"github.com/nanitefactory/visual/remote"
+	"golang.org/x/image/colornames"
+	"gopkg.in/yaml.v2"
+)
+
+// -------------------------------------------------------------------------
+// Config defaults and validation
+
+// Defaults of Config that zero values in Config fall back on.
+const (
+	DefaultWinWidth  = 1024.0
+	DefaultWinHeight = 768.0
+	DefaultTitle     = "Visualizer"
+	DefaultStreamFPS = 15.0
+)
+
+// DefaultConfig returns a Config of defaults. The world is as big as the window.
+func DefaultConfig() Config {
+	return Config{}.withDefaults()
+}
+
+// withDefaults returns a copy of a Config whose zero values are replaced with defaults.
+func (c Config) withDefaults() Config {
+	if c.WinWidth == 0 {
+		c.WinWidth = DefaultWinWidth
+	}
+	if c.WinHeight == 0 {
+		c.WinHeight = DefaultWinHeight
+	}
+	if c.Width == 0 {
+		c.Width = c.WinWidth
+	}
+	if c.Height == 0 {
+		c.Height = c.WinHeight
+	}
+	if c.Title == "" {
+		c.Title = DefaultTitle
+	}
+	if c.StreamFPS == 0 {
+		c.StreamFPS = DefaultStreamFPS
+	}
+	return c
+}
+
+// Validate determines whether a Config is able to make a visualizer.
+// Zero values are valid since they fall back on defaults. (See DefaultConfig().)
+func (c Config) Validate() error {
+	errs := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+	finite := func(f float64) bool {
+		return !math.IsNaN(f) && !math.IsInf(f, 0)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value float64
+	}{
+		{"Width", c.Width},
+		{"Height", c.Height},
+		{"WinWidth", c.WinWidth},
+		{"WinHeight", c.WinHeight},
+		{"FixedDt", c.FixedDt},
+		{"InitialZoom", c.InitialZoom},
+		{"StreamFPS", c.StreamFPS},
+	} {
+		check(finite(field.value) && field.value >= 0, "%s must be a positive number or zero for its default, not %v", field.name, field.value)
+	}
+	if c.MetricsAddr != "" {
+		_, err := remote.LocalAddr(c.MetricsAddr)
+		check(err == nil, "MetricsAddr must be a loopback address: %v", err)
+	}
+	if c.RemoteAddr != "" {
+		_, err := remote.LocalAddr(c.RemoteAddr)
+		check(err == nil, "RemoteAddr must be a loopback address: %v", err)
+	}
+	if c.StreamAddr != "" {
+		_, err := remote.LocalAddr(c.StreamAddr)
+		check(err == nil, "StreamAddr must be a loopback address: %v", err)
+	}
+	if c.LeadAddr != "" {
+		_, _, err := net.SplitHostPort(c.LeadAddr)
+		check(err == nil, "LeadAddr must be a host and a port: %v", err)
+	}
+	if c.FollowAddr != "" {
+		host, _, err := net.SplitHostPort(c.FollowAddr)
+		check(err == nil && host != "", "FollowAddr must be a host and a port: %q", c.FollowAddr)
+	}
+	check(c.LeadAddr == "" || c.FollowAddr == "", "either LeadAddr or FollowAddr can be set, not both")
+	check(finite(c.Viewport.X) && finite(c.Viewport.Y), "Viewport must be finite, not %v", c.Viewport)
+	if c.DrawAddr != "" {
+		_, _, err := drawproto.LocalAddr(c.DrawAddr)
+		check(err == nil, "DrawAddr must be a unix socket or a loopback address: %v", err)
+	}
+	check(finite(c.InitialZoomLevel), "InitialZoomLevel must be a finite number, not %v", c.InitialZoomLevel)
+	check(finite(c.InitialRotateDegree), "InitialRotateDegree must be a finite number, not %v", c.InitialRotateDegree)
+	check(c.InitialZoom == 0 || c.InitialZoomLevel == 0, "either InitialZoom or InitialZoomLevel can be set, not both")
+
+	if len(errs) > 0 {
+		return errors.New("visual: invalid config: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// logger returns the Logger a visualizer logs to; Config.Logger along with Config.OnLogging.
+func (c Config) logger() logger.Logger {
+	l := c.Logger
+	if l == nil {
+		l = logger.Default()
+	}
+	if c.OnLogging == nil {
+		return l
+	}
+	onLogging := c.OnLogging
+	return logger.Multi(l, logger.Func(func(level logger.Level, msg string, keyvals ...interface{}) {
+		onLogging(logger.Format(level, msg, keyvals...))
+	}))
+}
+
+// -------------------------------------------------------------------------
+// Config files
+
+// configFile is what's in a config file. Fields absent in a file are left nil.
+// The keys are also the names of environment variables with the prefix "VISUAL_" in upper case;
+// VISUAL_WIN_WIDTH for example.
+type configFile struct {
+	Bg                  *string  `json:"bg" yaml:"bg" toml:"bg"` // "#RRGGBB", "#RRGGBBAA" or a name in golang.org/x/image/colornames
+	WinCentered         *bool    `json:"win_centered" yaml:"win_centered" toml:"win_centered"`
+	Undecorated         *bool    `json:"undecorated" yaml:"undecorated" toml:"undecorated"`
+	Headless            *bool    `json:"headless" yaml:"headless" toml:"headless"`
+	FixedDt             *float64 `json:"fixed_dt" yaml:"fixed_dt" toml:"fixed_dt"`
+	Seed                *int64   `json:"seed" yaml:"seed" toml:"seed"`
+	Title               *string  `json:"title" yaml:"title" toml:"title"`
+	Version             *string  `json:"version" yaml:"version" toml:"version"`
+	Width               *float64 `json:"width" yaml:"width" toml:"width"`
+	Height              *float64 `json:"height" yaml:"height" toml:"height"`
+	WinWidth            *float64 `json:"win_width" yaml:"win_width" toml:"win_width"`
+	WinHeight           *float64 `json:"win_height" yaml:"win_height" toml:"win_height"`
+	InitialZoom         *float64 `json:"initial_zoom" yaml:"initial_zoom" toml:"initial_zoom"`
+	InitialZoomLevel    *float64 `json:"initial_zoom_level" yaml:"initial_zoom_level" toml:"initial_zoom_level"`
+	InitialRotateDegree *float64 `json:"initial_rotate_degree" yaml:"initial_rotate_degree" toml:"initial_rotate_degree"`
+	MetricsAddr         *string  `json:"metrics_addr" yaml:"metrics_addr" toml:"metrics_addr"`
+	RemoteAddr          *string  `json:"remote_addr" yaml:"remote_addr" toml:"remote_addr"`
+	DrawAddr            *string  `json:"draw_addr" yaml:"draw_addr" toml:"draw_addr"`
+	StreamAddr          *string  `json:"stream_addr" yaml:"stream_addr" toml:"stream_addr"`
+	StreamFPS           *float64 `json:"stream_fps" yaml:"stream_fps" toml:"stream_fps"`
+	LeadAddr            *string  `json:"lead_addr" yaml:"lead_addr" toml:"lead_addr"`
+	FollowAddr          *string  `json:"follow_addr" yaml:"follow_addr" toml:"follow_addr"`
+	ViewportX           *float64 `json:"viewport_x" yaml:"viewport_x" toml:"viewport_x"`
+	ViewportY           *float64 `json:"viewport_y" yaml:"viewport_y" toml:"viewport_y"`
+}
+
+// envPrefix is the prefix of environment variables that override config files.
+const envPrefix = "VISUAL_"
+
+// LoadConfig reads a config file in JSON, YAML or TOML, told by its extension;
+// .json, .yaml, .yml or .toml.
+// Environment variables such as VISUAL_TITLE or VISUAL_WIN_WIDTH override what's in the file,
+// and what's in neither falls back on defaults. (See DefaultConfig().)
+// Callbacks can't be in a file; set them to the Config returned.
+func LoadConfig(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var file configFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&file)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &file)
+	case ".toml":
+		var md toml.MetaData
+		if md, err = toml.Decode(string(data), &file); err == nil && len(md.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown keys %v", md.Undecoded())
+		}
+	default:
+		return Config{}, fmt.Errorf("visual: config file of an unknown extension %q", ext)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("visual: %s: %v", path, err)
+	}
+	if err := file.overrideWithEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	cfg, err := file.toConfig()
+	if err != nil {
+		return Config{}, fmt.Errorf("visual: %s: %v", path, err)
+	}
+	cfg = cfg.withDefaults()
+	return cfg, cfg.Validate()
+}
+
+// overrideWithEnv overrides fields with environment variables named after their keys.
+func (file *configFile) overrideWithEnv(lookupEnv func(key string) (string, bool)) error {
+	rv := reflect.ValueOf(file).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		key := envPrefix + strings.ToUpper(rv.Type().Field(i).Tag.Get("json"))
+		str, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+		field := rv.Field(i)
+		value := reflect.New(field.Type().Elem())
+		var err error
+		switch ptr := value.Interface().(type) {
+		case *string:
+			*ptr = str
+		case *bool:
+			*ptr, err = strconv.ParseBool(str)
+		case *float64:
+			*ptr, err = strconv.ParseFloat(str, 64)
+		case *int64:
+			*ptr, err = strconv.ParseInt(str, 10, 64)
+		}
+		if err != nil {
+			return fmt.Errorf("visual: %s: %v", key, err)
+		}
+		field.Set(value)
+	}
+	return nil
+}
+
+func (file configFile) toConfig() (cfg Config, err error) {
+	if file.Bg != nil {
+		if cfg.Bg, err = ParseColor(*file.Bg); err != nil {
+			return Config{}, err
+		}
+	}
+	setBool := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setFloat := func(dst *float64, src *float64) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setBool(&cfg.WinCentered, file.WinCentered)
+	setBool(&cfg.Undecorated, file.Undecorated)
+	setBool(&cfg.Headless, file.Headless)
+	setFloat(&cfg.FixedDt, file.FixedDt)
+	if file.Seed != nil {
+		cfg.Seed = *file.Seed
+	}
+	setString(&cfg.Title, file.Title)
+	setString(&cfg.Version, file.Version)
+	setFloat(&cfg.Width, file.Width)
+	setFloat(&cfg.Height, file.Height)
+	setFloat(&cfg.WinWidth, file.WinWidth)
+	setFloat(&cfg.WinHeight, file.WinHeight)
+	setFloat(&cfg.InitialZoom, file.InitialZoom)
+	setFloat(&cfg.InitialZoomLevel, file.InitialZoomLevel)
+	setFloat(&cfg.InitialRotateDegree, file.InitialRotateDegree)
+	setString(&cfg.MetricsAddr, file.MetricsAddr)
+	setString(&cfg.RemoteAddr, file.RemoteAddr)
+	setString(&cfg.DrawAddr, file.DrawAddr)
+	setString(&cfg.StreamAddr, file.StreamAddr)
+	setFloat(&cfg.StreamFPS, file.StreamFPS)
+	setString(&cfg.LeadAddr, file.LeadAddr)
+	setString(&cfg.FollowAddr, file.FollowAddr)
+	setFloat(&cfg.Viewport.X, file.ViewportX)
+	setFloat(&cfg.Viewport.Y, file.ViewportY)
+	return cfg, nil
+}
+
+// ParseColor parses "#RRGGBB", "#RRGGBBAA" or a name in golang.org/x/image/colornames, as config files do.
+func ParseColor(str string) (pixel.RGBA, error) {
+	if c, ok := colornames.Map[strings.ToLower(str)]; ok {
+		return pixel.ToRGBA(c), nil
+	}
+	if !strings.HasPrefix(str, "#") { // not to take a misspelled name made of hex digits, such as "faded", for a color
+		return pixel.RGBA{}, fmt.Errorf("invalid color %q", str)
+	}
+	hex := str[1:]
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return pixel.RGBA{}, fmt.Errorf("invalid color %q", str)
+	}
+	return pixel.ToRGBA(color.NRGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}), nil
+}
@@ -0,0 +1,123 @@
+package visual
+
+import (
+	"image/color"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "visual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.json": `{"title": "from file", "win_width": 800, "bg": "coral", "fixed_dt": 0.02}`,
+		"config.yaml": "title: from file\nwin_width: 800\nbg: coral\nfixed_dt: 0.02\n",
+		"config.toml": "title = \"from file\"\nwin_width = 800\nbg = \"coral\"\nfixed_dt = 0.02\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if cfg.Title != "from file" || cfg.WinWidth != 800 || cfg.FixedDt != 0.02 {
+			t.Errorf("%s: loaded %q %v %v", name, cfg.Title, cfg.WinWidth, cfg.FixedDt)
+		}
+		if cfg.Bg != pixel.ToRGBA(colornames.Coral) {
+			t.Errorf("%s: bg %v; want coral", name, cfg.Bg)
+		}
+		if cfg.WinHeight != DefaultWinHeight || cfg.Width != 800 || cfg.Height != DefaultWinHeight {
+			t.Errorf("%s: defaults %v %v %v", name, cfg.WinHeight, cfg.Width, cfg.Height)
+		}
+	}
+
+	// env overrides
+	os.Setenv("VISUAL_TITLE", "from env")
+	os.Setenv("VISUAL_HEADLESS", "true")
+	defer os.Unsetenv("VISUAL_TITLE")
+	defer os.Unsetenv("VISUAL_HEADLESS")
+	cfg, err := LoadConfig(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Title != "from env" || !cfg.Headless || cfg.WinWidth != 800 {
+		t.Errorf("env not overriding: %q %v %v", cfg.Title, cfg.Headless, cfg.WinWidth)
+	}
+
+	// invalid
+	for name, content := range map[string]string{
+		"unknown.json":  `{"titel": "typo"}`,
+		"unknown.toml":  "titel = \"typo\"\n",
+		"negative.yaml": "win_width: -1\n",
+		"color.json":    `{"bg": "#12345"}`,
+		"config.ini":    "title=ini",
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for str, want := range map[string]pixel.RGBA{
+		"Coral":     pixel.ToRGBA(colornames.Coral),
+		"#ff0000":   pixel.RGB(1, 0, 0),
+		"#00FF0080": pixel.ToRGBA(color.NRGBA{0, 255, 0, 128}),
+	} {
+		if got, err := ParseColor(str); err != nil || got != want {
+			t.Errorf("%q parsed as %v, %v; want %v", str, got, err, want)
+		}
+	}
+	for _, str := range []string{"", "#", "ff0000", "00ff0080", "#12345", "#ff00zz", "#ff0000ff00", "nocolor"} {
+		if _, err := ParseColor(str); err == nil {
+			t.Errorf("%q parsed", str)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("zero config: %v", err)
+	}
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("default config: %v", err)
+	}
+	for i, cfg := range []Config{
+		{Width: -1},
+		{WinHeight: math.NaN()},
+		{FixedDt: math.Inf(1)},
+		{InitialZoom: 2, InitialZoomLevel: 1},
+		{InitialRotateDegree: math.Inf(-1)},
+		{MetricsAddr: "0.0.0.0:9464"},
+		{RemoteAddr: "example.com:9465"},
+		{DrawAddr: "unix:"},
+		{StreamAddr: "10.0.0.1:9467"},
+		{StreamFPS: -1},
+		{LeadAddr: "9468"},
+		{FollowAddr: ":9468"},
+		{LeadAddr: ":9468", FollowAddr: "127.0.0.1:9468"},
+		{Viewport: pixel.V(math.NaN(), 0)},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("config %d: no error", i)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package visual
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/nanitefactory/visual/jukebox"
+)
+
+// -------------------------------------------------------------------------
+// Console
+
+// RegisterCommand adds a command to the console, or replaces the one of the same name.
+// The help is a line telling how to use it, and fn returns what's to be printed out.
+// Commands run on the mainthread while events are handled, with actors unlocked.
+func (v *Visualizer) RegisterCommand(name, help string, fn func(args []string) string) {
+	v.cons.Register(name, help, fn)
+}
+
+// Exec runs a console command line as if it were typed, and returns its output.
+// Just like commands typed, it must be called on the mainthread with actors unlocked.
+func (v *Visualizer) Exec(line string) string {
+	return v.cons.Exec(line)
+}
+
+// SetConsoleVisible drops the console down or pulls it up. (The backtick key toggles it.)
+// Other hotkeys are ignored while it's down.
+func (v *Visualizer) SetConsoleVisible(visible bool) {
+	v.cons.SetVisible(visible)
+}
+
+// IsConsoleVisible determines whether the console is down or not.
+func (v *Visualizer) IsConsoleVisible() bool {
+	return v.cons.IsVisible()
+}
+
+// SetTimeScale speeds up or slows down the game clock; 1 is the default, 0 freezes it.
+// The delta time scaled is what gets recorded, so that it's replayed as it was.
+func (v *Visualizer) SetTimeScale(scale float64) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.timeScale = scale
+}
+
+// TimeScale returns how fast the game clock goes; 1 is the default.
+func (v *Visualizer) TimeScale() float64 {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.timeScale
+}
+
+// Screenshot saves what's on the window as a PNG file at the path given.
+// It must be called on the mainthread while the visualizer is running. (The console command does so.)
+func (v *Visualizer) Screenshot(path string) error {
+	if v.window == nil {
+		return errors.New("visualizer is not running")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, v._Capture()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HandleConsole types and submits commands while the console is down.
+func (v *Visualizer) _HandleConsole(in Input) {
+	if typing, ok := in.(TextInput); ok {
+		v.cons.Type(typing.Typed())
+	}
+	if in.JustPressed(pixelgl.KeyBackspace) {
+		v.cons.Backspace()
+	}
+	if in.JustPressed(pixelgl.KeyTab) {
+		v.cons.Complete()
+	}
+	if in.JustPressed(pixelgl.KeyUp) {
+		v.cons.HistoryPrev(true)
+	}
+	if in.JustPressed(pixelgl.KeyDown) {
+		v.cons.HistoryPrev(false)
+	}
+	if in.JustPressed(pixelgl.KeyEnter) || in.JustPressed(pixelgl.KeyKPEnter) {
+		v.cons.Submit()
+	}
+	if in.JustReleased(pixelgl.KeyEscape) {
+		v.cons.SetVisible(false)
+	}
+}
+
+// RegisterBuiltinCommands registers the commands the console knows by default.
+func (v *Visualizer) _RegisterBuiltinCommands() {
+	// floats parses exactly n arguments.
+	floats := func(args []string, n int) ([]float64, error) {
+		if len(args) != n {
+			return nil, fmt.Errorf("%d arguments given; want %d", len(args), n)
+		}
+		ret := make([]float64, n)
+		for i, arg := range args {
+			f, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = f
+		}
+		return ret, nil
+	}
+	running := func() error {
+		if v.camera == nil {
+			return errors.New("visualizer is not running")
+		}
+		return nil
+	}
+
+	v.cons.Register("camera", "camera goto x y: moves the camera to a point in game coords", func(args []string) string {
+		if len(args) <= 0 || args[0] != "goto" {
+			return "usage: camera goto x y"
+		}
+		xy, err := floats(args[1:], 2)
+		if err == nil {
+			err = running()
+		}
+		if err != nil {
+			return err.Error()
+		}
+		v.camera.MoveTo(pixel.V(xy[0], xy[1]))
+		return ""
+	})
+	v.cons.Register("zoom", "zoom n: sets the zoom depth of the camera (1 is no zoom)", func(args []string) string {
+		n, err := floats(args, 1)
+		if err == nil {
+			err = running()
+		}
+		if err != nil {
+			return err.Error()
+		}
+		if n[0] <= 0 {
+			return "zoom must be a positive number"
+		}
+		v.camera.ZoomTo(n[0])
+		return ""
+	})
+	v.cons.Register("rotate", "rotate deg: rotates the camera counterclockwise by degrees", func(args []string) string {
+		deg, err := floats(args, 1)
+		if err == nil {
+			err = running()
+		}
+		if err != nil {
+			return err.Error()
+		}
+		v.camera.Rotate(deg[0])
+		return ""
+	})
+	v.cons.Register("explode", "explode x y: explodes at a point in game coords", func(args []string) string {
+		xy, err := floats(args, 2)
+		if err != nil {
+			return err.Error()
+		}
+		v.explosions.ExplodeAt(pixel.V(xy[0], xy[1]), pixel.V(10, 10))
+		return ""
+	})
+	v.cons.Register("fullscreen", "fullscreen: toggles the full screen mode", func(args []string) string {
+		if v.window == nil {
+			return "visualizer is not running"
+		}
+		v._SetFullScreenMode(v.window.Monitor() == nil)
+		return ""
+	})
+	v.cons.Register("music", "music on|off: plays or pauses the music", func(args []string) string {
+		switch strings.Join(args, " ") {
+		case "on":
+			if !jukebox.IsInitialized() {
+				return "jukebox is not initialized"
+			}
+			jukebox.Play()
+		case "off":
+			jukebox.Pause()
+		default:
+			return "usage: music on|off"
+		}
+		return ""
+	})
+	v.cons.Register("timescale", "timescale [scale]: tells or sets how fast the game clock goes (1 is the default)", func(args []string) string {
+		if len(args) <= 0 {
+			return strconv.FormatFloat(v.TimeScale(), 'g', -1, 64)
+		}
+		scale, err := floats(args, 1)
+		if err != nil {
+			return err.Error()
+		}
+		if scale[0] < 0 {
+			return "time scale must not be negative"
+		}
+		v.SetTimeScale(scale[0])
+		return ""
+	})
+	v.cons.Register("screenshot", "screenshot [path]: saves the window as a PNG file", func(args []string) string {
+		path := time.Now().Format("screenshot-20060102-150405.png")
+		if len(args) > 0 {
+			path = strings.Join(args, " ")
+		}
+		if err := v.Screenshot(path); err != nil {
+			return err.Error()
+		}
+		return "saved " + path
+	})
+	v.cons.Register("actors", "actors: counts actors and HUDs by type", func(args []string) string {
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+
+		count := map[string]int{}
+		for _, actor := range v.actors {
+			count[fmt.Sprintf("%T", actor)]++
+		}
+		for _, hud := range v.huds {
+			count[fmt.Sprintf("%T (HUD)", hud)]++
+		}
+		lines := make([]string, 0, len(count)+1)
+		for typ, n := range count {
+			lines = append(lines, fmt.Sprintf("%s x%d", typ, n))
+		}
+		sort.Strings(lines)
+		lines = append(lines, fmt.Sprintf("%d actors, %d HUDs", len(v.actors), len(v.huds)))
+		return strings.Join(lines, "\n")
+	})
+	v.cons.Register("types", "types [name]: lists actor types registered, or props of one", func(args []string) string {
+		if len(args) == 0 {
+			return strings.Join(ActorTypeNames(), " ")
+		}
+		schema, ok := ActorSchemaOf(args[0])
+		if !ok {
+			return "unknown type " + args[0]
+		}
+		lines := []string{schema.Name + " " + schema.GoType}
+		for _, p := range schema.Params {
+			line := fmt.Sprintf("  %s %s", p.Name, p.Type)
+			if p.Default != nil {
+				line += fmt.Sprintf(" = %v", p.Default)
+			}
+			if len(p.Values) > 0 {
+				line += " (" + strings.Join(p.Values, "|") + ")"
+			}
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n")
+	})
+}
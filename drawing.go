@@ -0,0 +1,275 @@
+package visual
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nanitefactory/visual/actors"
+	"github.com/nanitefactory/visual/drawproto"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// External drawing
+
+// DrawAddr returns the address the drawing protocol is served at, such as "127.0.0.1:9466".
+// It's empty unless serving. (See Config.DrawAddr and the drawproto package.)
+//
+// What clients draw is backed by actors.Shape, actors.Label and actors.Sprite;
+// general actors in the world space, or HUDs in the screen space.
+// They're tagged "drawproto" and with the name of each client, such as "client-1". (See SetVisibleByTag().)
+func (v *Visualizer) DrawAddr() string {
+	if v.drawServer == nil {
+		return ""
+	}
+	return v.drawServer.Addr().String()
+}
+
+// drawScene applies what clients draw to a visualizer on the mainthread.
+type drawScene struct {
+	v *Visualizer
+}
+
+// Apply changes on the mainthread, or not at all if it doesn't get to them in time.
+// Changes got to late are ignored, as the client has already been told they failed.
+func (sc drawScene) Apply(client string, changes []drawproto.Change) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	var (
+		mutex         sync.Mutex
+		applied, late bool
+		err           error
+	)
+	errCall := sc.v.tasks.Call(ctx, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if late {
+			return
+		}
+		err = sc.v._ApplyDrawn(client, changes)
+		applied = true
+	})
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if errCall != nil && !applied {
+		late = true
+		return errCall
+	}
+	return err
+}
+
+func (sc drawScene) Drop(client string) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	sc.v.tasks.Call(ctx, func() { sc.v._DropDrawn(client) })
+}
+
+// StartDraw starts serving the drawing protocol at Config.DrawAddr, if any.
+// It should be called on lazy init.
+func (v *Visualizer) _StartDraw() {
+	if v.drawAddr == "" {
+		return
+	}
+	ln, err := drawproto.Listen(v.drawAddr)
+	if err != nil {
+		v.logger.Error("drawing protocol failed to be served", "addr", v.drawAddr, "err", err)
+		return
+	}
+	v.drawServer = drawproto.Serve(ln, drawScene{v})
+	v.logger.Info("drawing protocol served", "addr", v.DrawAddr())
+}
+
+// StopDraw disconnects all clients and drops what they've drawn, if serving.
+// It must be called on the mainthread, which runs what's invoked meanwhile.
+func (v *Visualizer) _StopDraw() {
+	if v.drawServer == nil {
+		return
+	}
+	done := make(chan error, 1)
+	go func(s *drawproto.Server) { done <- s.Close() }(v.drawServer)
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				v.logger.Warn("drawing protocol failed to stop", "err", err)
+			}
+			v.drawServer = nil
+			return
+		case <-time.After(time.Millisecond):
+			v.tasks.Run() // Clients get dropped on the mainthread.
+		}
+	}
+}
+
+// ApplyDrawn creates, updates and deletes actors of a client, all or nothing.
+// Actors updated stay where they are in order, unless they move from one space to the other.
+func (v *Visualizer) _ApplyDrawn(client string, changes []drawproto.Change) error {
+	// Actors are made first, so that nothing is changed on an error.
+	made := make([]HUD, len(changes))
+	for i, c := range changes {
+		if c.Delete {
+			continue
+		}
+		actor, err := newDrawnActor(c.Prim)
+		if err != nil {
+			return err
+		}
+		made[i] = actor
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.drawn == nil {
+		v.drawn = map[string]map[string]drawnActor{}
+	}
+	if v.drawn[client] == nil {
+		v.drawn[client] = map[string]drawnActor{}
+	}
+	drawn := v.drawn[client]
+	for i, c := range changes {
+		old, exists := drawn[c.ID]
+		if c.Delete {
+			v._RemoveDrawn(old)
+			if exists {
+				delete(v.meta, old.actor)
+			}
+			delete(drawn, c.ID)
+			continue
+		}
+		now := drawnActor{made[i], c.Prim.IsScreen()}
+		if !exists || !v._ReplaceDrawn(old, now) {
+			v._RemoveDrawn(old)
+			if now.screen {
+				v.huds = append(v.huds, now.actor)
+			} else {
+				v.actors = append(v.actors, now.actor)
+			}
+		}
+		m := v._Meta(now.actor, true)
+		if exists {
+			if mOld := v._Meta(old.actor, false); mOld != nil && m != nil {
+				*m = *mOld // Flags and tags, such as those of SetVisibleByTag(), outlive updates.
+			}
+			delete(v.meta, old.actor)
+		}
+		if m != nil {
+			if m.tags == nil {
+				m.tags = map[string]bool{}
+			}
+			m.tags["drawproto"] = true
+			m.tags[client] = true
+		}
+		drawn[c.ID] = now
+	}
+	if v.window != nil {
+		// HUDs get told the screen size as usual.
+		width, height := v.window.Bounds().W(), v.window.Bounds().H()
+		for _, c := range changes {
+			if d, ok := drawn[c.ID]; ok && d.screen {
+				d.actor.PosOnScreen(width, height)
+			}
+		}
+	}
+	return nil
+}
+
+// DropDrawn deletes all actors of a client.
+func (v *Visualizer) _DropDrawn(client string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for _, d := range v.drawn[client] {
+		v._RemoveDrawn(d)
+		delete(v.meta, d.actor)
+	}
+	delete(v.drawn, client)
+}
+
+// drawnActor is an actor a client draws, and the space it's in.
+type drawnActor struct {
+	actor  HUD
+	screen bool
+}
+
+// ReplaceDrawn puts an actor where the old one is in the same space. It returns false if it's not found.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _ReplaceDrawn(old, now drawnActor) bool {
+	if old.screen != now.screen {
+		return false
+	}
+	if now.screen {
+		for i := range v.huds {
+			if v.huds[i] == HUD(old.actor) {
+				v._Unquarantine(old.actor, len(v.actors)+i)
+				v.huds[i] = now.actor
+				return true
+			}
+		}
+		return false
+	}
+	for i := range v.actors {
+		if v.actors[i] == Actor(old.actor) {
+			v._Unquarantine(old.actor, i)
+			v.actors[i] = now.actor
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveDrawn removes an actor from its space, if it's there.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _RemoveDrawn(d drawnActor) {
+	if d.actor == nil {
+		return
+	}
+	if d.screen {
+		for i := range v.huds {
+			if v.huds[i] == HUD(d.actor) {
+				v.huds = append(v.huds[:i], v.huds[i+1:]...)
+				v._Unquarantine(d.actor, len(v.actors)+i)
+				return
+			}
+		}
+		return
+	}
+	for i := range v.actors {
+		if v.actors[i] == Actor(d.actor) {
+			v.actors = append(v.actors[:i], v.actors[i+1:]...)
+			v._Unquarantine(d.actor, i)
+			return
+		}
+	}
+}
+
+// newDrawnActor makes an actor out of a primitive validated.
+func newDrawnActor(p drawproto.Primitive) (HUD, error) {
+	col, err := p.RGBA()
+	if err != nil {
+		return nil, err
+	}
+	switch p.Kind {
+	case drawproto.KindShape:
+		kind, err := super.ParseShapeKind(p.Shape)
+		if err != nil {
+			return nil, err
+		}
+		shape, err := actors.NewShape(kind, p.Vecs(), p.Radius, p.Thickness, col)
+		if err != nil {
+			return nil, err
+		}
+		return shape, nil
+	case drawproto.KindText:
+		return actors.NewLabel(p.Text, p.Vec(), p.Scale, col), nil
+	default: // drawproto.KindSprite
+		img, err := p.Image()
+		if err != nil {
+			return nil, err
+		}
+		return actors.NewSprite(img, p.Vec(), p.Scale, p.Angle), nil
+	}
+}
@@ -0,0 +1,416 @@
+// Package drawproto lets external processes draw on a visualizer,
+// over a line-delimited JSON protocol on a Unix socket or a local TCP connection.
+//
+// A client sends requests, one JSON object per line, and gets an acknowledgement line back
+// for every request with a non-zero "seq", or for any request that fails.
+//
+//	{"op": "create", "seq": 1, "id": "box", "prim": {"kind": "shape", "shape": "rect", "points": [[0, 0], [100, 50]], "color": "#ff0000"}}
+//	{"op": "update", "seq": 2, "id": "box", "prim": {"color": "#00ff00"}}
+//	{"op": "batch", "seq": 3, "ops": [{"op": "delete", "id": "box"}, {"op": "create", "id": "hi", "prim": {"kind": "text", "text": "hello", "pos": [10, 10], "space": "screen"}}]}
+//	{"op": "clear"}
+//	{"op": "ping", "seq": 4}
+//
+//	{"seq": 1, "ok": true}
+//	{"seq": 3, "ok": true, "n": 2}
+//
+// IDs are of each client; primitives of a client are all deleted when it disconnects.
+// An update changes only the fields given, and a batch is applied at once, or not at all on an error.
+package drawproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png" // to decode sprites
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/remote"
+)
+
+// -------------------------------------------------------------------------
+// Primitives
+
+// Kinds of a primitive.
+const (
+	KindShape  = "shape"
+	KindText   = "text"
+	KindSprite = "sprite"
+)
+
+// Spaces a primitive is drawn in.
+const (
+	SpaceWorld  = "world"  // in game coords, as a general actor
+	SpaceScreen = "screen" // in screen coords, as a HUD
+)
+
+// Primitive is a shape, a text or a sprite that a client draws.
+type Primitive struct {
+	Kind      string       `json:"kind"`                // shape, text or sprite
+	Space     string       `json:"space,omitempty"`     // world by default, or screen
+	Shape     string       `json:"shape,omitempty"`     // of a shape; rect, circle, line or polygon
+	Points    [][2]float64 `json:"points,omitempty"`    // of a shape
+	Radius    float64      `json:"radius,omitempty"`    // of a circle
+	Thickness float64      `json:"thickness,omitempty"` // of a shape; 0 to fill it in
+	Color     string       `json:"color,omitempty"`     // "#RRGGBB" or "#RRGGBBAA"; white by default
+	Text      string       `json:"text,omitempty"`      // of a text
+	Pos       [2]float64   `json:"pos,omitempty"`       // of a text or a sprite
+	Scale     float64      `json:"scale,omitempty"`     // of a text or a sprite; 1 by default
+	Angle     float64      `json:"angle,omitempty"`     // of a sprite in degrees counterclockwise
+	PNG       []byte       `json:"png,omitempty"`       // of a sprite, in base64 in JSON
+}
+
+// clone returns a deep copy, whose slices can be decoded over.
+func (p Primitive) clone() Primitive {
+	if p.Points != nil {
+		p.Points = append([][2]float64(nil), p.Points...)
+	}
+	if p.PNG != nil {
+		p.PNG = append([]byte(nil), p.PNG...)
+	}
+	return p
+}
+
+// Validate checks whether a primitive can be drawn.
+func (p Primitive) Validate() error {
+	switch p.Kind {
+	case KindShape:
+		if len(p.Points) <= 0 {
+			return errors.New("a shape without points")
+		}
+	case KindText:
+	case KindSprite:
+		if len(p.PNG) <= 0 {
+			return errors.New("a sprite without png")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", p.Kind)
+	}
+	if p.Space != "" && p.Space != SpaceWorld && p.Space != SpaceScreen {
+		return fmt.Errorf("unknown space %q", p.Space)
+	}
+	_, err := p.RGBA()
+	return err
+}
+
+// IsScreen tells whether it's drawn in screen coords.
+func (p Primitive) IsScreen() bool {
+	return p.Space == SpaceScreen
+}
+
+// Vecs returns the points of a shape.
+func (p Primitive) Vecs() []pixel.Vec {
+	ret := make([]pixel.Vec, len(p.Points))
+	for i, xy := range p.Points {
+		ret[i] = pixel.V(xy[0], xy[1])
+	}
+	return ret
+}
+
+// Vec returns the position of a text or a sprite.
+func (p Primitive) Vec() pixel.Vec {
+	return pixel.V(p.Pos[0], p.Pos[1])
+}
+
+// RGBA parses the color, which is white if empty.
+func (p Primitive) RGBA() (pixel.RGBA, error) {
+	if p.Color == "" {
+		return pixel.RGB(1, 1, 1), nil
+	}
+	hex := strings.TrimPrefix(p.Color, "#")
+	if (len(hex) != 6 && len(hex) != 8) || len(hex) == len(p.Color) {
+		return pixel.RGBA{}, fmt.Errorf("color %q is not #RRGGBB nor #RRGGBBAA", p.Color)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return pixel.RGBA{}, fmt.Errorf("color %q is not #RRGGBB nor #RRGGBBAA", p.Color)
+	}
+	c := func(shift uint) float64 { return float64(n>>shift&0xff) / 0xff }
+	return pixel.RGB(c(24), c(16), c(8)).Mul(pixel.Alpha(c(0))), nil // premultiplied
+}
+
+// Image decodes the PNG of a sprite.
+func (p Primitive) Image() (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(p.PNG))
+	return img, err
+}
+
+// -------------------------------------------------------------------------
+// Requests
+
+// Ops of a request.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+	OpClear  = "clear" // deletes all of the client
+	OpBatch  = "batch"
+	OpPing   = "ping"
+)
+
+// Request is a line a client sends.
+type Request struct {
+	Op   string          `json:"op"`
+	Seq  int64           `json:"seq,omitempty"`  // acknowledged if non-zero
+	ID   string          `json:"id,omitempty"`   // of create, update and delete
+	Prim json.RawMessage `json:"prim,omitempty"` // of create and update
+	Ops  []Request       `json:"ops,omitempty"`  // of batch; seqs of them are ignored
+}
+
+// Ack is a line a client gets back.
+type Ack struct {
+	Seq   int64  `json:"seq"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	N     int    `json:"n,omitempty"` // changes applied
+}
+
+// Change is what's to be applied to a Scene; a primitive set as a whole, or deleted.
+type Change struct {
+	ID     string
+	Delete bool
+	Prim   Primitive // as a whole, even after an update
+}
+
+// Scene is where changes get applied, such as a visualizer.
+type Scene interface {
+	// Apply changes of a client at once, such as in a single frame. Changes are validated before.
+	// An error fails the whole request, and the client sees the primitives as they were.
+	Apply(client string, changes []Change) error
+	// Drop all primitives of a client gone.
+	Drop(client string)
+}
+
+// session keeps primitives of a client as they are, to validate and merge requests.
+type session struct {
+	client string
+	prims  map[string]Primitive
+}
+
+// changes works out what a request changes, without changing the session yet. (See apply().)
+// Only primitives the request touches get copied, however many the session has.
+func (s *session) changes(req Request) (changes []Change, err error) {
+	touched := map[string]*Primitive{} // as they are after the changes so far; nil if deleted
+	lookup := func(id string) (Primitive, bool) {
+		if p, ok := touched[id]; ok {
+			if p == nil {
+				return Primitive{}, false
+			}
+			return *p, true
+		}
+		p, ok := s.prims[id]
+		return p.clone(), ok // to be decoded over, without touching what's kept
+	}
+	reqs := []Request{req}
+	if req.Op == OpBatch {
+		reqs = req.Ops
+	}
+	for _, r := range reqs {
+		switch r.Op {
+		case OpCreate, OpUpdate:
+			p, exists := lookup(r.ID)
+			if r.ID == "" {
+				return nil, fmt.Errorf("%s without id", r.Op)
+			}
+			if r.Op == OpCreate && exists {
+				return nil, fmt.Errorf("%q already exists", r.ID)
+			}
+			if r.Op == OpUpdate && !exists {
+				return nil, fmt.Errorf("%q does not exist", r.ID)
+			}
+			if r.Op == OpCreate {
+				p = Primitive{}
+			}
+			if err := json.Unmarshal(r.Prim, &p); err != nil { // over what it was, on update
+				return nil, fmt.Errorf("%q: %v", r.ID, err)
+			}
+			if err := p.Validate(); err != nil {
+				return nil, fmt.Errorf("%q: %v", r.ID, err)
+			}
+			touched[r.ID] = &p
+			changes = append(changes, Change{ID: r.ID, Prim: p})
+		case OpDelete:
+			if _, exists := lookup(r.ID); !exists {
+				return nil, fmt.Errorf("%q does not exist", r.ID)
+			}
+			touched[r.ID] = nil
+			changes = append(changes, Change{ID: r.ID, Delete: true})
+		case OpClear:
+			for id := range s.prims {
+				if _, ok := touched[id]; !ok {
+					touched[id] = nil
+					changes = append(changes, Change{ID: id, Delete: true})
+				}
+			}
+			for id, p := range touched {
+				if p != nil {
+					touched[id] = nil
+					changes = append(changes, Change{ID: id, Delete: true})
+				}
+			}
+		case OpPing:
+		default:
+			return nil, fmt.Errorf("unknown op %q", r.Op)
+		}
+	}
+	return changes, nil
+}
+
+// apply changes worked out and applied to the scene.
+func (s *session) apply(changes []Change) {
+	for _, c := range changes {
+		if c.Delete {
+			delete(s.prims, c.ID)
+		} else {
+			s.prims[c.ID] = c.Prim
+		}
+	}
+}
+
+// -------------------------------------------------------------------------
+// Server
+
+// LocalAddr checks an address to listen on, and returns the network and the address with the host filled in.
+// It's "unix:/path/to/socket", or a loopback host and port such as "127.0.0.1:9466" or "tcp:127.0.0.1:9466".
+// A port alone such as ":9466" is on 127.0.0.1. (See remote.LocalAddr().)
+func LocalAddr(addr string) (network, address string, err error) {
+	if path := strings.TrimPrefix(addr, "unix:"); path != addr {
+		if path == "" {
+			return "", "", errors.New("drawproto: unix socket without a path")
+		}
+		return "unix", path, nil
+	}
+	address, err = remote.LocalAddr(strings.TrimPrefix(addr, "tcp:"))
+	if err != nil {
+		return "", "", err
+	}
+	return "tcp", address, nil
+}
+
+// Listen on an address. (See LocalAddr().)
+func Listen(addr string) (net.Listener, error) {
+	network, address, err := LocalAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen(network, address)
+}
+
+// Server accepts clients and applies what they request to a scene.
+type Server struct {
+	ln    net.Listener
+	scene Scene
+	mutex sync.Mutex
+	conns map[net.Conn]bool
+	nConn int // ever accepted
+	wg    sync.WaitGroup
+}
+
+// Serve clients on a listener in the background.
+func Serve(ln net.Listener, scene Scene) *Server {
+	s := &Server{ln: ln, scene: scene, conns: map[net.Conn]bool{}}
+	s.wg.Add(1)
+	go s._Accept()
+	return s
+}
+
+// Addr returns the address it's listening on.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Close stops listening and disconnects all clients, whose primitives get dropped before it returns.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.mutex.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// unexported
+func (s *Server) _Accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.nConn++
+		client := fmt.Sprintf("client-%d", s.nConn)
+		s.conns[conn] = true
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go s._Serve(conn, client)
+	}
+}
+
+// unexported
+func (s *Server) _Serve(conn net.Conn, client string) {
+	defer s.wg.Done()
+	sess := &session{client: client, prims: map[string]Primitive{}}
+	defer func() {
+		conn.Close()
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		if len(sess.prims) > 0 {
+			s.scene.Drop(client)
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	enc := json.NewEncoder(w)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if ack, ok := s._Handle(sess, line); ok {
+				enc.Encode(ack)
+			}
+		}
+		if r.Buffered() <= 0 || err != nil { // acks go out once there's nothing more to read right away
+			if w.Flush() != nil {
+				return
+			}
+		}
+		if err != nil {
+			return // io.EOF or closed
+		}
+	}
+}
+
+// unexported
+func (s *Server) _Handle(sess *session, line []byte) (ack Ack, ok bool) {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return Ack{Error: err.Error()}, true
+	}
+	ack.Seq = req.Seq
+	changes, err := sess.changes(req)
+	if err == nil && len(changes) > 0 {
+		err = s.scene.Apply(sess.client, changes)
+	}
+	if err != nil {
+		ack.Error = err.Error()
+		return ack, true
+	}
+	sess.apply(changes)
+	ack.OK = true
+	ack.N = len(changes)
+	return ack, req.Seq != 0
+}
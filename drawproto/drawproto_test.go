@@ -0,0 +1,239 @@
+package drawproto
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+// scene records what's applied.
+type scene struct {
+	mutex   sync.Mutex
+	prims   map[string]map[string]Primitive // by clients and IDs
+	applied int
+	dropped chan string
+	fail    bool
+}
+
+func newScene() *scene {
+	return &scene{prims: map[string]map[string]Primitive{}, dropped: make(chan string, 1)}
+}
+
+func (s *scene) Apply(client string, changes []Change) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.fail {
+		return errors.New("failed")
+	}
+	if s.prims[client] == nil {
+		s.prims[client] = map[string]Primitive{}
+	}
+	for _, c := range changes {
+		if c.Delete {
+			delete(s.prims[client], c.ID)
+		} else {
+			s.prims[client][c.ID] = c.Prim
+		}
+	}
+	s.applied++
+	return nil
+}
+
+func (s *scene) Drop(client string) {
+	s.mutex.Lock()
+	delete(s.prims, client)
+	s.mutex.Unlock()
+	s.dropped <- client
+}
+
+func (s *scene) get(client, id string) (Primitive, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p, ok := s.prims[client][id]
+	return p, ok
+}
+
+func TestServe(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := newScene()
+	srv := Serve(ln, sc)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acks := bufio.NewScanner(conn)
+	// send lines and read nAcks back.
+	sendN := func(nAcks int, lines ...string) []Ack {
+		t.Helper()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := fmt.Fprintln(conn, strings.Join(lines, "\n")); err != nil {
+			t.Fatal(err)
+		}
+		var ret []Ack
+		for i := 0; i < nAcks; i++ {
+			if !acks.Scan() {
+				t.Fatalf("no ack: %v", acks.Err())
+			}
+			var ack Ack
+			if err := json.Unmarshal(acks.Bytes(), &ack); err != nil {
+				t.Fatal(err)
+			}
+			ret = append(ret, ack)
+		}
+		return ret
+	}
+	send := func(lines ...string) []Ack {
+		t.Helper()
+		return sendN(len(lines), lines...)
+	}
+
+	got := send(
+		`{"op": "create", "seq": 1, "id": "box", "prim": {"kind": "shape", "shape": "rect", "points": [[0, 0], [100, 50]], "color": "#ff000080"}}`,
+		`{"op": "update", "seq": 2, "id": "box", "prim": {"thickness": 2}}`,
+		`{"op": "create", "seq": 3, "id": "box", "prim": {"kind": "text"}}`,
+		`{"op": "ping", "seq": 4}`,
+	)
+	if !got[0].OK || !got[1].OK || got[2].OK || !got[3].OK || got[3].N != 0 {
+		t.Errorf("acks %+v; want ok, ok, an error of a duplicate and ok", got)
+	}
+	box, ok := sc.get("client-1", "box")
+	if !ok || box.Thickness != 2 || len(box.Points) != 2 {
+		t.Fatalf("box updated as %+v; want the rect 2 thick", box)
+	}
+	if c, _ := box.RGBA(); c != pixel.RGB(1, 0, 0).Mul(pixel.Alpha(128.0/255)) {
+		t.Errorf("color %v", c)
+	}
+
+	// A batch fails as a whole.
+	got = send(`{"op": "batch", "seq": 5, "ops": [{"op": "delete", "id": "box"}, {"op": "update", "id": "nope", "prim": {}}]}`)
+	if got[0].OK || got[0].Seq != 5 {
+		t.Errorf("ack %+v; want an error", got[0])
+	}
+	if _, ok := sc.get("client-1", "box"); !ok {
+		t.Error("box deleted by a batch failed")
+	}
+	applied := sc.applied
+	got = send(`{"op": "batch", "seq": 6, "ops": [{"op": "delete", "id": "box"}, {"op": "create", "id": "hi", "prim": {"kind": "text", "text": "hello", "space": "screen"}}]}`)
+	if !got[0].OK || got[0].N != 2 || sc.applied != applied+1 {
+		t.Errorf("ack %+v, applied %d times; want a batch of 2 applied once", got[0], sc.applied-applied)
+	}
+
+	// A scene failing fails the request, and the session stays as it was.
+	sc.mutex.Lock()
+	sc.fail = true
+	sc.mutex.Unlock()
+	if got := send(`{"op": "delete", "seq": 7, "id": "hi"}`); got[0].OK {
+		t.Error("a request the scene failed is acknowledged ok")
+	}
+	sc.mutex.Lock()
+	sc.fail = false
+	sc.mutex.Unlock()
+	if got := send(`{"op": "update", "seq": 8, "id": "hi", "prim": {"text": "bye"}}`); !got[0].OK {
+		t.Errorf("ack %+v after the scene failed; want hi still there", got[0])
+	}
+
+	// Errors get acknowledged even without seqs, but nothing else does.
+	if got := send(`{"op": "create", "id": "bad", "prim": {"kind": "shape"}}`); got[0].OK || got[0].Seq != 0 {
+		t.Errorf("ack %+v; want an error", got[0])
+	}
+	if got := sendN(1, `{"op": "create", "id": "ok", "prim": {"kind": "text"}}`, `{"op": "ping", "seq": 9}`)[0]; got.Seq != 9 {
+		t.Errorf("ack %+v; want only the ping acknowledged", got)
+	}
+
+	conn.Close()
+	select {
+	case client := <-sc.dropped:
+		if client != "client-1" {
+			t.Errorf("dropped %q", client)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not dropped on disconnect")
+	}
+}
+
+func TestSessionKeepsPrimsOnFailure(t *testing.T) {
+	sess := &session{client: "client-1", prims: map[string]Primitive{}}
+	request := func(line string) error {
+		var req Request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			t.Fatal(err)
+		}
+		changes, err := sess.changes(req)
+		if err == nil {
+			sess.apply(changes)
+		}
+		return err
+	}
+
+	if err := request(`{"op": "create", "id": "line", "prim": {"kind": "shape", "shape": "line", "points": [[1, 1], [2, 2], [3, 3]]}}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := request(`{"op": "update", "id": "line", "prim": {"points": [[9, 9]], "color": "nope"}}`); err == nil {
+		t.Fatal("an update of a bad color is applied")
+	}
+	if err := request(`{"op": "update", "id": "line", "prim": {"thickness": 1}}`); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(sess.prims["line"].Points), "[[1 1] [2 2] [3 3]]"; got != want {
+		t.Errorf("points %s after an update failed; want %s", got, want)
+	}
+}
+
+func TestListen(t *testing.T) {
+	if _, err := Listen("0.0.0.0:0"); err == nil {
+		t.Error("listening on all interfaces")
+	}
+	if _, err := Listen("9466"); err == nil {
+		t.Error("listening on an address without a port")
+	}
+
+	dir, err := ioutil.TempDir("", "drawproto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ln, err := Listen("unix:" + filepath.Join(dir, "visual.sock"))
+	if err != nil {
+		t.Skip(err) // not on this platform
+	}
+	defer ln.Close()
+	conn, err := net.Dial("unix", filepath.Join(dir, "visual.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestValidate(t *testing.T) {
+	for i, p := range []Primitive{
+		{Kind: "blob"},
+		{Kind: KindShape},
+		{Kind: KindSprite},
+		{Kind: KindText, Space: "ui"},
+		{Kind: KindText, Color: "red"},
+		{Kind: KindText, Color: "#12345"},
+		{Kind: KindText, Color: "#gggggg"},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("primitive %d: no error", i)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package visual
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Offscreen export
+
+// exportTileSize is the width and the height in pixels of a single offscreen canvas ExportRegion() renders on.
+// It is kept small enough to fit the max texture size of most of GPUs.
+const exportTileSize = 1024
+
+// ExportRegion renders a region of the game world into a single PNG file at the path given.
+// The region is rendered offscreen, tile by tile, with a temporary camera looking straight down at it,
+// so the output is not limited to the window size. HUDs are excluded.
+//
+// pixelsPerUnit is the number of pixels a single unit of the game world takes in the output image.
+// The whole image is held in memory until it's encoded,
+// so mind the size of it. (Width * Height * 4 bytes.)
+//
+// Config.OnExporting gets called after each tile is rendered.
+// This function must be called while the visualizer is running, but not from within
+// a callback invoked while actors are locked up, such as Config.OnDrawn.
+func (v *Visualizer) ExportRegion(rect pixel.Rect, pixelsPerUnit float64, path string) error {
+	img, err := v._RenderRegion(rect.Norm(), pixelsPerUnit)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RenderRegion renders a region of the game world tile by tile and stitches them into one image.
+func (v *Visualizer) _RenderRegion(rect pixel.Rect, pixelsPerUnit float64) (*image.RGBA, error) {
+	if v.window == nil {
+		return nil, errors.New("visualizer is not running")
+	}
+	if pixelsPerUnit <= 0 || math.IsNaN(pixelsPerUnit) || math.IsInf(pixelsPerUnit, 0) {
+		return nil, errors.New("pixels per unit must be a positive number")
+	}
+	width := int(math.Ceil(rect.W() * pixelsPerUnit))
+	height := int(math.Ceil(rect.H() * pixelsPerUnit))
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("region is empty")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	canvas := pixelgl.NewCanvas(pixel.R(0, 0, exportTileSize, exportTileSize))
+	canvas.SetSmooth(true)
+
+	nTilesX := (width + exportTileSize - 1) / exportTileSize
+	nTilesY := (height + exportTileSize - 1) / exportTileSize
+	nTiles := nTilesX * nTilesY
+	for ty := 0; ty < nTilesY; ty++ {
+		for tx := 0; tx < nTilesX; tx++ {
+			// The tile in pixels, bottom-up just as the game world is.
+			x0, y0 := tx*exportTileSize, ty*exportTileSize
+			tileCenter := pixel.V(float64(x0)+exportTileSize/2, float64(y0)+exportTileSize/2)
+
+			// A temporary camera with no rotation, with the zoom of pixelsPerUnit, centered at the tile.
+			camera := super.NewCamera(rect.Min.Add(tileCenter.Scaled(1/pixelsPerUnit)), canvas.Bounds())
+			camera.ZoomTo(pixelsPerUnit)
+			camera.Jump()
+
+			canvas.Clear(v.bg)
+			func() {
+				v.mutex.Lock()
+				defer v.mutex.Unlock()
+
+				v._DrawWorld(canvas, camera.Transform())
+			}()
+
+			// Copy the tile onto the image top-down; the canvas is read bottom-up.
+			pixels := canvas.Pixels()
+			for row := 0; row < exportTileSize; row++ {
+				yImg := height - 1 - (y0 + row)
+				if yImg < 0 {
+					break
+				}
+				nCols := exportTileSize
+				if x0+nCols > width {
+					nCols = width - x0
+				}
+				src := pixels[row*exportTileSize*4 : (row*exportTileSize+nCols)*4]
+				copy(img.Pix[img.PixOffset(x0, yImg):], src)
+			}
+
+			if v.onExporting != nil {
+				v.onExporting(ty*nTilesX+tx+1, nTiles)
+			}
+		}
+	}
+	return img, nil
+}
+
+// -------------------------------------------------------------------------
+// Capture
+
+// Capture returns a copy of what's been drawn on the window so far.
+func (v *Visualizer) _Capture() *image.RGBA {
+	canvas := v.window.Canvas()
+	pixels := canvas.Pixels()
+	height := int(canvas.Bounds().H())
+	if height <= 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	width := len(pixels) / 4 / height
+
+	// The canvas is read bottom-up.
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for row := 0; row < height; row++ {
+		copy(img.Pix[(height-1-row)*img.Stride:], pixels[row*width*4:(row+1)*width*4])
+	}
+	return img
+}
@@ -3,10 +3,11 @@ module github.com/nanitefactory/visual
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.4.1
 	github.com/TheTitanrain/w32 v0.0.0-20200114052255-2654d97dbd3d // indirect
 	github.com/faiface/beep v1.0.2
 	github.com/faiface/glhf v0.0.0-20181018222622-82a6317ac380 // indirect
-	github.com/faiface/mainthread v0.0.0-20171120011319-8b78f0a41ae3 // indirect
+	github.com/faiface/mainthread v0.0.0-20171120011319-8b78f0a41ae3
 	github.com/faiface/pixel v0.8.0
 	github.com/go-gl/gl v0.0.0-20190320180904-bf2b1f2f34d7 // indirect
 	github.com/go-gl/glfw v0.0.0-20191125211704-12ad95a8df72
@@ -22,4 +23,5 @@ require (
 	golang.org/x/image v0.0.0-20200119044424-58c23975cae1
 	golang.org/x/mobile v0.0.0-20200205170228-0df4eb238546 // indirect
 	golang.org/x/sys v0.0.0-20200202164722-d101bd2416d5 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,182 @@
+package visual
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+
+	"github.com/nanitefactory/visual/atlas"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Actor panics
+
+// Bounded is an Actor that tells where it is; in game coords for general actors, or in screen coords for HUDs.
+// It's optional, and lets an error marker be drawn in its place when it's quarantined.
+type Bounded interface {
+	Bounds() pixel.Rect
+}
+
+// ActorPanic is a panic recovered from an Actor's Update() or Draw().
+// The actor gets quarantined since; it's neither updated nor drawn, but an error marker is drawn instead.
+type ActorPanic struct {
+	Actor interface{} // The Actor or the HUD.
+	Type  string      // The type name of the actor.
+	Op    string      // "update" or "draw"
+	Value interface{} // What it panicked with.
+	Stack []byte      // The stack trace of the panic.
+	At    time.Time
+}
+
+func (p ActorPanic) Error() string {
+	return fmt.Sprintf("visual: %s panicked in %s: %v", p.Type, p.Op, p.Value)
+}
+
+// Quarantined returns the panics of actors quarantined, in the order they panicked.
+func (v *Visualizer) Quarantined() []ActorPanic {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	ret := make([]ActorPanic, len(v.quarantined))
+	for i, p := range v.quarantined {
+		ret[i] = *p
+	}
+	return ret
+}
+
+// Unquarantine lets a quarantined actor be updated and drawn again. It returns false if it's not quarantined.
+func (v *Visualizer) Unquarantine(actor interface{}) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v._Unquarantine(actor, -1)
+}
+
+// CallActor calls an actor's Update() or Draw() with the panic recovered, and profiled.
+// A hidden or inactive actor is skipped. A quarantined actor is skipped too, or marked in its place on draw.
+// The index is where the actor is in its list; it tells apart actors that can't be map keys.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _CallActor(actor interface{}, index int, op super.ProfileOp, t pixel.Target, do func()) {
+	if m := v._Meta(actor, false); m != nil && (op == super.ProfileDraw && m.hidden || op == super.ProfileUpdate && m.inactive) {
+		return
+	}
+	if p, ok := v.quarantine[quarantineKey(actor, index)]; ok {
+		if op == super.ProfileDraw {
+			v._DrawErrorMarker(t, p)
+		}
+		return
+	}
+	v.prof.Measure(actor, index, op, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				v._Quarantine(actor, index, op, r, debug.Stack())
+			}
+		}()
+		do()
+	})
+}
+
+// Quarantine records a panic of an actor. The caller is responsible for locking actors up.
+func (v *Visualizer) _Quarantine(actor interface{}, index int, op super.ProfileOp, value interface{}, stack []byte) {
+	p := &ActorPanic{
+		Actor: actor,
+		Type:  fmt.Sprintf("%T", actor),
+		Op:    op.String(),
+		Value: value,
+		Stack: stack,
+		At:    time.Now(),
+	}
+	if v.quarantine == nil {
+		v.quarantine = map[interface{}]*ActorPanic{}
+	}
+	v.quarantine[quarantineKey(actor, index)] = p
+	v.quarantined = append(v.quarantined, p)
+	v.panicsToReport = append(v.panicsToReport, *p)
+	v.logger.Error("actor panicked and got quarantined", "type", p.Type, "op", p.Op, "panic", p.Value, "stack", string(p.Stack))
+}
+
+// Unquarantine forgets the panic of an actor. The caller is responsible for locking actors up.
+func (v *Visualizer) _Unquarantine(actor interface{}, index int) bool {
+	p, ok := v.quarantine[quarantineKey(actor, index)]
+	if !ok {
+		return false
+	}
+	delete(v.quarantine, quarantineKey(actor, index))
+	for i := range v.quarantined {
+		if v.quarantined[i] == p {
+			v.quarantined = append(v.quarantined[:i], v.quarantined[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// ReportActorPanics calls back Config.OnActorPanic with panics recovered since the last report.
+// It must be called with actors unlocked, so that the callback can do whatever it wants.
+func (v *Visualizer) _ReportActorPanics() {
+	v.mutex.Lock()
+	panics := v.panicsToReport
+	v.panicsToReport = nil
+	v.mutex.Unlock()
+
+	if v.onActorPanic == nil {
+		return
+	}
+	for _, p := range panics {
+		v.onActorPanic(p)
+	}
+}
+
+// DrawErrorMarker draws a red cross in place of a quarantined actor if it's Bounded.
+func (v *Visualizer) _DrawErrorMarker(t pixel.Target, p *ActorPanic) {
+	b, ok := p.Actor.(Bounded)
+	if !ok {
+		return // listed by _DrawErrorList() instead
+	}
+	r := b.Bounds()
+	imd := imdraw.New(nil)
+	imd.Color = colornames.Red
+	imd.Push(r.Min, r.Max)
+	imd.Rectangle(2)
+	imd.Push(r.Min, r.Max)
+	imd.Line(2)
+	imd.Push(pixel.V(r.Min.X, r.Max.Y), pixel.V(r.Max.X, r.Min.Y))
+	imd.Line(2)
+	imd.Draw(t)
+}
+
+// DrawErrorList lists quarantined actors at the bottom left of the screen.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _DrawErrorList(t pixel.Target) {
+	if len(v.quarantined) <= 0 {
+		return
+	}
+	txt := text.New(pixel.V(4, 4), atlas.AtlasASCII18)
+	txt.Color = colornames.Red
+	for i := len(v.quarantined) - 1; i >= 0; i-- { // from the bottom up
+		p := v.quarantined[i]
+		txt.Dot = pixel.V(4, 4+txt.LineHeight*float64(len(v.quarantined)-1-i))
+		fmt.Fprintf(txt, "QUARANTINED %s: panic in %s: %v", p.Type, p.Op, p.Value)
+	}
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGBA{A: 0.75}
+	imd.Push(txt.Bounds().Min, txt.Bounds().Max)
+	imd.Rectangle(0)
+	imd.Draw(t)
+	txt.Draw(t, pixel.IM)
+}
+
+// quarantineKey is the actor itself, or its type and index if it can't be a map key.
+func quarantineKey(actor interface{}, index int) interface{} {
+	if actor != nil && !reflect.TypeOf(actor).Comparable() {
+		return fmt.Sprintf("%T#%d", actor, index)
+	}
+	return actor
+}
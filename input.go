@@ -0,0 +1,262 @@
+package visual
+
+import (
+	"sync"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// -------------------------------------------------------------------------
+// Input
+
+// Input is where a Visualizer reads user inputs from every frame.
+// A *pixelgl.Window is an Input, which is the default one.
+type Input interface {
+	Pressed(button pixelgl.Button) bool
+	JustPressed(button pixelgl.Button) bool
+	JustReleased(button pixelgl.Button) bool
+	MousePosition() pixel.Vec
+	MouseScroll() pixel.Vec
+	// UpdateInput moves on to the next frame.
+	// Visualizer calls this at the beginning of every frame before handling events,
+	// unless the Input is the window itself, which updates its input as it updates.
+	UpdateInput()
+}
+
+// TextInput is an Input that also tells what's been typed, which the console reads.
+// A *pixelgl.Window is a TextInput.
+type TextInput interface {
+	Typed() string
+}
+
+// -------------------------------------------------------------------------
+// VirtualInput
+
+// VirtualInput is an Input scripted by code rather than a user.
+// It's double buffered just as a window is;
+// what's done to it between two frames gets seen together from the next frame.
+// It is safe to use it concurrently.
+type VirtualInput struct {
+	mutex       sync.Mutex
+	prev, curr  virtualInputState
+	temp        virtualInputState
+	releaseNext []pixelgl.Button // to be released a frame after
+}
+
+type virtualInputState struct {
+	mouse   pixel.Vec
+	buttons map[pixelgl.Button]bool // pressed ones
+	scroll  pixel.Vec
+	typed   string
+}
+
+func (state virtualInputState) clone() virtualInputState {
+	buttons := make(map[pixelgl.Button]bool, len(state.buttons))
+	for button, pressed := range state.buttons {
+		if pressed {
+			buttons[button] = true
+		}
+	}
+	state.buttons = buttons
+	return state
+}
+
+// NewVirtualInput is a constructor.
+func NewVirtualInput() *VirtualInput {
+	return &VirtualInput{
+		prev: virtualInputState{buttons: map[pixelgl.Button]bool{}},
+		curr: virtualInputState{buttons: map[pixelgl.Button]bool{}},
+		temp: virtualInputState{buttons: map[pixelgl.Button]bool{}},
+	}
+}
+
+// Press buttons down. They stay pressed until released.
+func (vi *VirtualInput) Press(buttons ...pixelgl.Button) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	for _, button := range buttons {
+		vi.temp.buttons[button] = true
+	}
+}
+
+// Release buttons.
+func (vi *VirtualInput) Release(buttons ...pixelgl.Button) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	for _, button := range buttons {
+		vi.temp.buttons[button] = false
+	}
+}
+
+// ReleaseAfterSeen releases a button, but a frame after if it's been pressed without being seen yet,
+// so that a press and a release arriving together still make a click.
+func (vi *VirtualInput) _ReleaseAfterSeen(button pixelgl.Button) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	if vi.temp.buttons[button] && !vi.curr.buttons[button] {
+		vi.releaseNext = append(vi.releaseNext, button)
+		return
+	}
+	vi.temp.buttons[button] = false
+}
+
+// MoveMouse to a position in screen coords.
+func (vi *VirtualInput) MoveMouse(screenPos pixel.Vec) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	vi.temp.mouse = screenPos
+}
+
+// Scroll the mouse wheel. (Y: + ) Up, - ) Down)
+func (vi *VirtualInput) Scroll(by pixel.Vec) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	vi.temp.scroll = vi.temp.scroll.Add(by)
+}
+
+// Click moves the mouse to a position in screen coords and presses the button,
+// then releases it a frame after.
+// Use Visualizer.Project() to click at a position in game coords.
+func (vi *VirtualInput) Click(button pixelgl.Button, screenPos pixel.Vec) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	vi.temp.mouse = screenPos
+	vi.temp.buttons[button] = true
+	vi.releaseNext = append(vi.releaseNext, button)
+}
+
+// Type text as if it were typed on a keyboard.
+func (vi *VirtualInput) Type(text string) {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	vi.temp.typed += text
+}
+
+// Pressed implements Input.
+func (vi *VirtualInput) Pressed(button pixelgl.Button) bool {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	return vi.curr.buttons[button]
+}
+
+// JustPressed implements Input.
+func (vi *VirtualInput) JustPressed(button pixelgl.Button) bool {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	return vi.curr.buttons[button] && !vi.prev.buttons[button]
+}
+
+// JustReleased implements Input.
+func (vi *VirtualInput) JustReleased(button pixelgl.Button) bool {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	return !vi.curr.buttons[button] && vi.prev.buttons[button]
+}
+
+// MousePosition implements Input.
+func (vi *VirtualInput) MousePosition() pixel.Vec {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	return vi.curr.mouse
+}
+
+// MouseScroll implements Input.
+func (vi *VirtualInput) MouseScroll() pixel.Vec {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	return vi.curr.scroll
+}
+
+// Typed implements TextInput.
+func (vi *VirtualInput) Typed() string {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	return vi.curr.typed
+}
+
+// UpdateInput implements Input.
+func (vi *VirtualInput) UpdateInput() {
+	vi.mutex.Lock()
+	defer vi.mutex.Unlock()
+
+	vi.prev = vi.curr
+	vi.curr = vi.temp.clone()
+	vi.temp.scroll = pixel.ZV
+	vi.temp.typed = ""
+	for _, button := range vi.releaseNext {
+		vi.temp.buttons[button] = false
+	}
+	vi.releaseNext = nil
+}
+
+// -------------------------------------------------------------------------
+// mergedInput
+
+// mergedInput reads an Input along with a VirtualInput driven by others, such as viewers of the stream, as one.
+// Buttons pressed on either are pressed. The mouse is where either of them moved it last.
+type mergedInput struct {
+	Input                     // primary; replaced as the visualizer's input is
+	extra       *VirtualInput // updated by the merged one
+	extraMouse  bool          // whether the mouse is where the extra one put it
+	lastPrimary pixel.Vec     // where the primary one put the mouse
+}
+
+func (m *mergedInput) Pressed(button pixelgl.Button) bool {
+	return m.Input.Pressed(button) || m.extra.Pressed(button)
+}
+
+func (m *mergedInput) JustPressed(button pixelgl.Button) bool {
+	return m.Input.JustPressed(button) || m.extra.JustPressed(button)
+}
+
+func (m *mergedInput) JustReleased(button pixelgl.Button) bool {
+	return m.Input.JustReleased(button) || m.extra.JustReleased(button)
+}
+
+func (m *mergedInput) MousePosition() pixel.Vec {
+	if m.extraMouse {
+		return m.extra.MousePosition()
+	}
+	return m.Input.MousePosition()
+}
+
+func (m *mergedInput) MouseScroll() pixel.Vec {
+	return m.Input.MouseScroll().Add(m.extra.MouseScroll())
+}
+
+func (m *mergedInput) Typed() string {
+	if typing, ok := m.Input.(TextInput); ok {
+		return typing.Typed() + m.extra.Typed()
+	}
+	return m.extra.Typed()
+}
+
+// UpdateInput moves the extra one on to the next frame, but not the primary one, which the visualizer takes care of.
+func (m *mergedInput) UpdateInput() {
+	m.extra.UpdateInput()
+
+	m.extra.mutex.Lock()
+	extraMoved := m.extra.curr.mouse != m.extra.prev.mouse
+	m.extra.mutex.Unlock()
+	primary := m.Input.MousePosition()
+	if extraMoved {
+		m.extraMouse = true
+	} else if primary != m.lastPrimary {
+		m.extraMouse = false
+	}
+	m.lastPrimary = primary
+}
@@ -0,0 +1,91 @@
+package visual
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/nanitefactory/visual/super"
+	"golang.org/x/image/colornames"
+)
+
+// -------------------------------------------------------------------------
+// Inspector
+
+// SetInspectorVisible shows or hides the inspector listing actors and HUDs. (F1 toggles it.)
+// Actors get their fields inspected through super.Inspectable if they implement it, or by reflection otherwise.
+// The one selected gets its bounds highlighted if it's Bounded.
+func (v *Visualizer) SetInspectorVisible(visible bool) {
+	v.insp.SetVisible(visible)
+}
+
+// IsInspectorVisible determines whether the inspector is shown or not.
+func (v *Visualizer) IsInspectorVisible() bool {
+	return v.insp.IsVisible()
+}
+
+// InspectedItems lists actors and then HUDs in draw order, for the inspector.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _InspectedItems() []super.InspectedItem {
+	ret := make([]super.InspectedItem, 0, len(v.actors)+len(v.huds))
+	item := func(actor Actor, index int, hud bool) super.InspectedItem {
+		ret := super.InspectedItem{
+			Actor:   actor,
+			Type:    fmt.Sprintf("%T", actor),
+			HUD:     hud,
+			Visible: true,
+			Active:  true,
+		}
+		if m := v._Meta(actor, false); m != nil {
+			ret.Visible, ret.Active = !m.hidden, !m.inactive
+			for tag := range m.tags {
+				ret.Tags = append(ret.Tags, tag)
+			}
+			sort.Strings(ret.Tags)
+		}
+		_, ret.Quarantined = v.quarantine[quarantineKey(actor, index)]
+		return ret
+	}
+	for i, actor := range v.actors {
+		ret = append(ret, item(actor, i, false))
+	}
+	for i, hud := range v.huds {
+		ret = append(ret, item(hud, len(v.actors)+i, true))
+	}
+	return ret
+}
+
+// ToggleInspectedVisible shows or hides the actor selected in the inspector.
+func (v *Visualizer) _ToggleInspectedVisible() {
+	item, _, ok := v.insp.Selected()
+	if !ok {
+		return
+	}
+	if actor, ok := item.Actor.(Actor); ok {
+		v.SetVisible(actor, !v.IsVisible(actor))
+		v.insp.SelectNext(0) // refresh
+	}
+}
+
+// DrawInspectedBounds highlights the bounds of the actor selected in the inspector, if it's Bounded;
+// of a general actor on a target in game coords, or of a HUD in screen coords.
+func (v *Visualizer) _DrawInspectedBounds(t pixel.Target, hud bool) {
+	if !v.insp.IsVisible() {
+		return
+	}
+	item, _, ok := v.insp.Selected()
+	if !ok || item.HUD != hud {
+		return
+	}
+	b, ok := item.Actor.(Bounded)
+	if !ok {
+		return
+	}
+	r := b.Bounds()
+	imd := imdraw.New(nil)
+	imd.Color = colornames.Yellow
+	imd.Push(r.Min, r.Max)
+	imd.Rectangle(2)
+	imd.Draw(t)
+}
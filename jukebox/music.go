@@ -1,150 +1,215 @@
-package jukebox // singleton
-
-import (
-	"errors"
-	"io/ioutil"
-	"os"
-	"sync"
-	"time"
-
-	"github.com/faiface/beep"
-	"github.com/faiface/beep/speaker"
-	"github.com/faiface/beep/vorbis"
-	"github.com/nanitefactory/bindat/bindatkuji"
-)
-
-// -------------------------------------------------------------------------
-
-const nMusics = 2
-
-var (
-	mutex     sync.Mutex
-	isPlaying bool
-	musics    [nMusics]*_Music
-)
-
-// -------------------------------------------------------------------------
-
-// Initialize should be called only once on program startup.
-// This function creates temporary music files.
-func Initialize() error {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	// city pop favorites
-	musics[0] = _NewMusicFromAsset("nighttempo-purepresent1", "karaoke/kikuchimomoko-nightcruising.ogg")
-	musics[1] = _NewMusicFromAsset("nighttempo-purepresent2", "karaoke/takeuchimariya-plasticlove.ogg")
-
-	// speaker on
-	err := speaker.Init(musics[0].format.SampleRate, musics[0].format.SampleRate.N(time.Second))
-	if err != nil {
-		return err
-	}
-	speaker.Play(beep.Iterate(func() (soundtrack beep.Streamer) {
-		musics[0].stream.Seek(0)
-		musics[1].stream.Seek(0)
-		return beep.Seq(musics[0].stream, musics[1].stream)
-	}))
-	speaker.Lock()
-	return nil
-}
-
-// IsPlaying determines whether the soundtrack is currently playing or not.
-func IsPlaying() bool {
-	return isPlaying
-}
-
-// Play unlocks the speaker.
-func Play() {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	if !isPlaying {
-		isPlaying = true
-		speaker.Unlock()
-	}
-	return
-}
-
-// Pause locks the speaker.
-func Pause() {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	if isPlaying {
-		isPlaying = false
-		speaker.Lock()
-	}
-	return
-}
-
-// Finalize should be called on program exit.
-// This function deletes the temporary music file its package generates.
-func Finalize() error {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	if isPlaying {
-		isPlaying = false
-		speaker.Lock()
-	}
-
-	errs := ""
-	for _, music := range musics {
-		music.Close()
-		err := music._Destroy()
-		if err != nil {
-			errs += " " + err.Error()
-		}
-	}
-
-	speaker.Unlock()
-
-	if errs != "" {
-		return errors.New(errs)
-	}
-	return nil
-}
-
-// -------------------------------------------------------------------------
-
-// NewMusicFromAsset is a constructor.
-func _NewMusicFromAsset(nameMusic, nameAsset string) *_Music {
-	asset, err := bindatkuji.Asset(nameAsset)
-	if err != nil {
-		// log.Fatal(err) //
-	}
-	return _NewMusic(nameMusic, asset)
-}
-
-// Music is a temporary file to play a single background music. It should be destroyed on program exit.
-type _Music struct {
-	os.File
-	stream beep.StreamSeekCloser
-	format beep.Format
-}
-
-// NewMusic creates an instance of Music, a temporary file from which the speaker plays a music.
-// speaker.Lock() to pause.
-// speaker.Unlock() to resume/play.
-func _NewMusic(name string, asset []byte) *_Music {
-	tmpfile, err := ioutil.TempFile("", name)
-	if err != nil {
-		// log.Fatal(err) //
-	}
-	// log.Println(tmpfile.Name()) //
-	_, err = tmpfile.Write(asset)
-	if err != nil {
-		// log.Fatal(err) //
-	}
-	stream, format, err := vorbis.Decode(tmpfile)
-	if err != nil {
-		// log.Fatal(err) //
-	}
-	return &_Music{*tmpfile, stream, format}
-}
-
-// Destroy deletes the temporary music file.
-func (music *_Music) _Destroy() error {
-	return os.Remove(music.Name())
-}
+package jukebox // singleton
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/vorbis"
+	"github.com/nanitefactory/bindat/bindatkuji"
+	"github.com/nanitefactory/visual/logger"
+)
+
+// -------------------------------------------------------------------------
+
+const nMusics = 2
+
+var (
+	mutex     sync.Mutex
+	isPlaying bool
+	isReady   bool // initialized and not finalized yet
+	musics    [nMusics]*_Music
+	logTo     logger.Logger // nil for logger.Default()
+)
+
+// SetLogger sets where this package logs to. A nil Logger is logger.Default().
+func SetLogger(l logger.Logger) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	logTo = l
+}
+
+func _Logger() logger.Logger {
+	if logTo == nil {
+		return logger.Default()
+	}
+	return logTo
+}
+
+// -------------------------------------------------------------------------
+
+// Initialize should be called only once on program startup.
+// This function creates temporary music files.
+func Initialize() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// city pop favorites
+	for i, asset := range [nMusics][2]string{
+		{"nighttempo-purepresent1", "karaoke/kikuchimomoko-nightcruising.ogg"},
+		{"nighttempo-purepresent2", "karaoke/takeuchimariya-plasticlove.ogg"},
+	} {
+		music, err := _NewMusicFromAsset(asset[0], asset[1])
+		if err != nil {
+			_Logger().Error("jukebox: music failed to load", "asset", asset[1], "err", err)
+			_DestroyAll()
+			musics = [nMusics]*_Music{}
+			return err
+		}
+		musics[i] = music
+	}
+
+	// speaker on
+	err := speaker.Init(musics[0].format.SampleRate, musics[0].format.SampleRate.N(time.Second))
+	if err != nil {
+		_Logger().Error("jukebox: speaker failed to init", "err", err)
+		_DestroyAll()
+		musics = [nMusics]*_Music{}
+		return err
+	}
+	speaker.Play(beep.Iterate(func() (soundtrack beep.Streamer) {
+		musics[0].stream.Seek(0)
+		musics[1].stream.Seek(0)
+		return beep.Seq(musics[0].stream, musics[1].stream)
+	}))
+	speaker.Lock()
+	isReady = true
+	return nil
+}
+
+// IsInitialized determines whether Initialize() has succeeded and Finalize() is yet to come.
+func IsInitialized() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return isReady
+}
+
+// IsPlaying determines whether the soundtrack is currently playing or not.
+func IsPlaying() bool {
+	return isPlaying
+}
+
+// Play unlocks the speaker. It does nothing unless initialized.
+func Play() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !isPlaying && isReady {
+		isPlaying = true
+		speaker.Unlock()
+	}
+	return
+}
+
+// Pause locks the speaker.
+func Pause() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if isPlaying {
+		isPlaying = false
+		speaker.Lock()
+	}
+	return
+}
+
+// Finalize should be called on program exit.
+// This function deletes the temporary music file its package generates.
+func Finalize() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !isReady { // never initialized, failed to, or finalized already
+		return nil
+	}
+	isReady = false
+
+	if isPlaying {
+		isPlaying = false
+		speaker.Lock()
+	}
+
+	err := _DestroyAll()
+
+	speaker.Unlock()
+
+	return err
+}
+
+// DestroyAll closes and deletes all temporary music files created so far.
+func _DestroyAll() error {
+	errs := ""
+	for _, music := range musics {
+		if music == nil {
+			continue
+		}
+		music.Close()
+		err := music._Destroy()
+		if err != nil {
+			_Logger().Warn("jukebox: temporary music file not deleted", "file", music.Name(), "err", err)
+			errs += " " + err.Error()
+		}
+	}
+	if errs != "" {
+		return errors.New(errs)
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------
+
+// NewMusicFromAsset is a constructor.
+func _NewMusicFromAsset(nameMusic, nameAsset string) (*_Music, error) {
+	asset, err := bindatkuji.Asset(nameAsset)
+	if err != nil {
+		return nil, err
+	}
+	return _NewMusic(nameMusic, asset)
+}
+
+// Music is a temporary file to play a single background music. It should be destroyed on program exit.
+type _Music struct {
+	os.File
+	stream beep.StreamSeekCloser
+	format beep.Format
+}
+
+// NewMusic creates an instance of Music, a temporary file from which the speaker plays a music.
+// speaker.Lock() to pause.
+// speaker.Unlock() to resume/play.
+func _NewMusic(name string, asset []byte) (*_Music, error) {
+	tmpfile, err := ioutil.TempFile("", name)
+	if err != nil {
+		return nil, err
+	}
+	_Logger().Debug("jukebox: temporary music file created", "file", tmpfile.Name())
+	fail := func(err error) (*_Music, error) {
+		tmpfile.Close()
+		os.Remove(tmpfile.Name())
+		return nil, fmt.Errorf("%s: %v", tmpfile.Name(), err)
+	}
+	if _, err = tmpfile.Write(asset); err != nil {
+		return fail(err)
+	}
+	if _, err = tmpfile.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+	stream, format, err := vorbis.Decode(tmpfile)
+	if err != nil {
+		return fail(err)
+	}
+	return &_Music{*tmpfile, stream, format}, nil
+}
+
+// Destroy deletes the temporary music file.
+func (music *_Music) _Destroy() error {
+	return os.Remove(music.Name())
+}
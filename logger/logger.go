@@ -0,0 +1,171 @@
+// Package logger defines a leveled, structured Logger that Visualizer and its packages log to,
+// along with a few adapters of it.
+//
+// A record is a message and key/value pairs, such as:
+//
+//	l.Warn("recording stopped", "frame", 42, "err", err)
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// -------------------------------------------------------------------------
+// Logger
+
+// Logger logs a message along with key/value pairs at a level.
+// Keys are strings and values are anything. An odd one out is logged with the key "!BADKEY".
+// Implementations must be safe to use concurrently.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// Level is the severity of a record.
+type Level int
+
+// enum Level
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// -------------------------------------------------------------------------
+// Func
+
+// Func is a Logger that's a single function of all levels.
+type Func func(level Level, msg string, keyvals ...interface{})
+
+// Debug implements Logger.
+func (f Func) Debug(msg string, keyvals ...interface{}) { f(LevelDebug, msg, keyvals...) }
+
+// Info implements Logger.
+func (f Func) Info(msg string, keyvals ...interface{}) { f(LevelInfo, msg, keyvals...) }
+
+// Warn implements Logger.
+func (f Func) Warn(msg string, keyvals ...interface{}) { f(LevelWarn, msg, keyvals...) }
+
+// Error implements Logger.
+func (f Func) Error(msg string, keyvals ...interface{}) { f(LevelError, msg, keyvals...) }
+
+// Nop is a Logger that logs nothing.
+var Nop Logger = Func(func(Level, string, ...interface{}) {})
+
+// Multi returns a Logger that logs to all of the loggers given.
+func Multi(loggers ...Logger) Logger {
+	return Func(func(level Level, msg string, keyvals ...interface{}) {
+		for _, l := range loggers {
+			Log(l, level, msg, keyvals...)
+		}
+	})
+}
+
+// Log logs to a Logger at a level given as a value.
+func Log(l Logger, level Level, msg string, keyvals ...interface{}) {
+	switch {
+	case level <= LevelDebug:
+		l.Debug(msg, keyvals...)
+	case level == LevelInfo:
+		l.Info(msg, keyvals...)
+	case level == LevelWarn:
+		l.Warn(msg, keyvals...)
+	default:
+		l.Error(msg, keyvals...)
+	}
+}
+
+// -------------------------------------------------------------------------
+// Std
+
+// Std returns a Logger that writes records at the min level or above to a *log.Logger as text lines.
+// A nil *log.Logger is the standard logger of the package log.
+//
+//	WARN recording stopped frame=42 err="short write"
+func Std(l *log.Logger, min Level) Logger {
+	output := log.Output
+	if l != nil {
+		output = l.Output
+	}
+	return Func(func(level Level, msg string, keyvals ...interface{}) {
+		if level < min {
+			return
+		}
+		output(4, Format(level, msg, keyvals...))
+	})
+}
+
+// Format formats a record in a text line without a newline at the end.
+func Format(level Level, msg string, keyvals ...interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(level.String())
+	sb.WriteByte(' ')
+	sb.WriteString(msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, val := "!BADKEY", keyvals[i]
+		if i+1 < len(keyvals) {
+			key, val = fmt.Sprint(keyvals[i]), keyvals[i+1]
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(formatValue(val))
+	}
+	return sb.String()
+}
+
+func formatValue(val interface{}) string {
+	str := fmt.Sprint(val)
+	if str == "" || strings.ContainsAny(str, " =\"\t\r\n") {
+		return fmt.Sprintf("%q", str)
+	}
+	return str
+}
+
+// -------------------------------------------------------------------------
+// Default
+
+var (
+	mutex    sync.Mutex
+	fallback = Std(nil, LevelInfo)
+)
+
+// Default returns the Logger that packages log to unless told otherwise.
+// It is the standard logger of the package log at LevelInfo, until SetDefault() gets called.
+func Default() Logger {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return fallback
+}
+
+// SetDefault sets the Logger Default() returns. A nil Logger resets it.
+func SetDefault(l Logger) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if l == nil {
+		l = Std(nil, LevelInfo)
+	}
+	fallback = l
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestStd(t *testing.T) {
+	var buf bytes.Buffer
+	l := Std(log.New(&buf, "", 0), LevelInfo)
+	l.Debug("hidden")
+	l.Info("shown", "n", 1)
+	l.Error("failed", "err", errors.New("short write"), "odd")
+
+	want := "INFO shown n=1\n" +
+		"ERROR failed err=\"short write\" !BADKEY=odd\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logged %q; want %q", got, want)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var levels []string
+	f := Func(func(level Level, msg string, keyvals ...interface{}) {
+		levels = append(levels, level.String()+" "+msg)
+	})
+	l := Multi(f, Nop, f)
+	l.Warn("w")
+	l.Debug("d")
+	if got := strings.Join(levels, ","); got != "WARN w,WARN w,DEBUG d,DEBUG d" {
+		t.Errorf("logged %q", got)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	var n int
+	SetDefault(Func(func(Level, string, ...interface{}) { n++ }))
+	defer SetDefault(nil)
+	Default().Info("hi")
+	if n != 1 {
+		t.Errorf("logged %d times to the default; want 1", n)
+	}
+}
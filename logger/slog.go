@@ -0,0 +1,99 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Slog returns a Logger that logs to a *slog.Logger. A nil *slog.Logger is slog.Default().
+func Slog(l *slog.Logger) Logger {
+	return &slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s *slogLogger) logger() *slog.Logger {
+	if s.l == nil {
+		return slog.Default()
+	}
+	return s.l
+}
+
+func (s *slogLogger) Debug(msg string, keyvals ...interface{}) { s.logger().Debug(msg, keyvals...) }
+func (s *slogLogger) Info(msg string, keyvals ...interface{})  { s.logger().Info(msg, keyvals...) }
+func (s *slogLogger) Warn(msg string, keyvals ...interface{})  { s.logger().Warn(msg, keyvals...) }
+func (s *slogLogger) Error(msg string, keyvals ...interface{}) { s.logger().Error(msg, keyvals...) }
+
+// SlogHandler returns a slog.Handler that logs to a Logger,
+// so that a Logger can be used as a *slog.Logger with slog.New().
+// Groups are flattened into keys joined with dots.
+func SlogHandler(l Logger) slog.Handler {
+	return &handler{l: l}
+}
+
+type handler struct {
+	l      Logger
+	attrs  []interface{}
+	prefix string
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	keyvals := append([]interface{}(nil), h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		keyvals = appendAttr(keyvals, h.prefix, a)
+		return true
+	})
+	level := LevelError
+	switch {
+	case r.Level < slog.LevelInfo:
+		level = LevelDebug
+	case r.Level < slog.LevelWarn:
+		level = LevelInfo
+	case r.Level < slog.LevelError:
+		level = LevelWarn
+	}
+	Log(h.l, level, r.Message, keyvals...)
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]interface{}(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func appendAttr(keyvals []interface{}, prefix string, a slog.Attr) []interface{} {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			keyvals = appendAttr(keyvals, prefix, ga)
+		}
+		return keyvals
+	}
+	if a.Equal(slog.Attr{}) {
+		return keyvals
+	}
+	return append(keyvals, prefix+a.Key, v.Any())
+}
@@ -0,0 +1,25 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(SlogHandler(Std(log.New(&buf, "", 0), LevelDebug)))
+	l.WithGroup("cam").With("zoom", 2).Warn("moved", "x", 1)
+
+	Slog(l).Error("failed", "n", 3)
+
+	want := "WARN moved cam.zoom=2 cam.x=1\n" +
+		"ERROR failed n=3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logged %q; want %q", got, want)
+	}
+}
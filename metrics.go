@@ -0,0 +1,79 @@
+package visual
+
+import (
+	"time"
+
+	"github.com/nanitefactory/visual/jukebox"
+	"github.com/nanitefactory/visual/metrics"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Metrics
+
+// frameSecondsBounds are the buckets of the frame time histogram in seconds.
+var frameSecondsBounds = []float64{0.001, 0.002, 0.004, 0.0083, 0.0166, 0.0333, 0.05, 0.1, 0.25, 1}
+
+// MetricsAddr returns the address metrics are served at, such as "127.0.0.1:9464".
+// It's empty unless serving. (See Config.MetricsAddr.)
+func (v *Visualizer) MetricsAddr() string {
+	if v.metricsServer == nil {
+		return ""
+	}
+	return v.metricsServer.Addr()
+}
+
+// StartMetrics starts serving metrics at Config.MetricsAddr, if any.
+// It should be called on lazy init.
+func (v *Visualizer) _StartMetrics() {
+	if v.metricsAddr == "" {
+		return
+	}
+	s, err := metrics.Serve(v.metricsAddr, v._CollectMetrics)
+	if err != nil {
+		v.logger.Error("metrics failed to be served", "addr", v.metricsAddr, "err", err)
+		return
+	}
+	v.metricsServer = s
+	v.logger.Info("metrics served", "url", "http://"+s.Addr()+"/metrics")
+}
+
+// StopMetrics stops serving metrics, if serving.
+func (v *Visualizer) _StopMetrics() {
+	if v.metricsServer == nil {
+		return
+	}
+	if err := v.metricsServer.Close(); err != nil {
+		v.logger.Warn("metrics failed to stop", "err", err)
+	}
+	v.metricsServer = nil
+}
+
+// CollectMetrics writes metrics on a scrape, from a goroutine of the server.
+func (v *Visualizer) _CollectMetrics(w *metrics.Writer) {
+	bool01 := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	v.mutex.Lock()
+	nActors, nHUDs := len(v.actors), len(v.huds)
+	v.mutex.Unlock()
+
+	w.Gauge("visual_up_seconds", "Seconds since the visualizer started running.", time.Since(v.started).Seconds())
+	w.Gauge("visual_fps", "Frames per second, counted every second.", float64(v.fpsw.GetFPS()))
+	w.Histogram("visual_frame_seconds", "Frame times in seconds.", v.frameHist.Snapshot())
+	stats := v.frames.Stats()
+	phases := map[string]float64{}
+	for p, d := range stats.Phases {
+		phases[super.Phase(p).String()] = d.Avg.Seconds()
+	}
+	w.GaugeVec("visual_frame_phase_seconds", "Average time a phase takes a frame, over the last few seconds.", "phase", phases)
+	w.Gauge("visual_actors", "General actors in game coords.", float64(nActors))
+	w.Gauge("visual_huds", "HUDs in screen coords.", float64(nHUDs))
+	w.Gauge("visual_particles", "Particles of explosions alive.", float64(v.explosions.Particles()))
+	w.Gauge("visual_jukebox_initialized", "Whether the jukebox is ready to play or not.", bool01(jukebox.IsInitialized()))
+	w.Gauge("visual_jukebox_playing", "Whether the jukebox is playing or not.", bool01(jukebox.IsPlaying()))
+}
@@ -0,0 +1,218 @@
+// Package metrics exposes metrics in the Prometheus text format over HTTP, on localhost only.
+//
+//	srv, err := metrics.Serve("127.0.0.1:9464", func(w *metrics.Writer) {
+//		w.Gauge("visual_fps", "Frames per second.", float64(fps))
+//	})
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nanitefactory/visual/remote"
+)
+
+// ContentType is of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// -------------------------------------------------------------------------
+// Writer
+
+// Writer writes metrics in the Prometheus text format.
+// Labels are given as pairs of names and values, such as "phase", "draw".
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter is a constructor.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Gauge writes a value that goes up and down.
+func (mw *Writer) Gauge(name, help string, value float64, labels ...string) {
+	mw._Header(name, help, "gauge")
+	mw._Sample(name, labels, value)
+}
+
+// Counter writes a value that only goes up.
+func (mw *Writer) Counter(name, help string, value float64, labels ...string) {
+	mw._Header(name, help, "counter")
+	mw._Sample(name, labels, value)
+}
+
+// GaugeVec writes values of a gauge, a value for each value of a label.
+func (mw *Writer) GaugeVec(name, help, label string, values map[string]float64) {
+	mw._Header(name, help, "gauge")
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		mw._Sample(name, []string{label, k}, values[k])
+	}
+}
+
+// Histogram writes a snapshot of a histogram.
+func (mw *Writer) Histogram(name, help string, h HistogramSnapshot, labels ...string) {
+	mw._Header(name, help, "histogram")
+	cumulative := uint64(0)
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		mw._Sample(name+"_bucket", append(labels[:len(labels):len(labels)], "le", formatFloat(bound)), float64(cumulative))
+	}
+	mw._Sample(name+"_bucket", append(labels[:len(labels):len(labels)], "le", "+Inf"), float64(h.Count))
+	mw._Sample(name+"_sum", labels, h.Sum)
+	mw._Sample(name+"_count", labels, float64(h.Count))
+}
+
+// Flush writes any buffered data to the underlying io.Writer, and returns the first error so far.
+func (mw *Writer) Flush() error {
+	if mw.err != nil {
+		return mw.err
+	}
+	return mw.w.Flush()
+}
+
+// unexported
+func (mw *Writer) _Header(name, help, typ string) {
+	mw._Printf("# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+// unexported
+func (mw *Writer) _Sample(name string, labels []string, value float64) {
+	if len(labels) <= 0 {
+		mw._Printf("%s %s\n", name, formatFloat(value))
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+"="+strconv.Quote(labels[i+1]))
+	}
+	mw._Printf("%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+// unexported
+func (mw *Writer) _Printf(format string, args ...interface{}) {
+	if mw.err != nil {
+		return
+	}
+	_, mw.err = fmt.Fprintf(mw.w, format, args...)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// -------------------------------------------------------------------------
+// Histogram
+
+// Histogram counts observations in buckets. It is safe to use it concurrently.
+type Histogram struct {
+	mutex  sync.Mutex
+	bounds []float64 // upper bounds, ascending
+	counts []uint64  // non-cumulative; one for each bound
+	count  uint64
+	sum    float64
+}
+
+// HistogramSnapshot is a histogram at a point in time.
+type HistogramSnapshot struct {
+	Bounds []float64 // upper bounds, ascending
+	Counts []uint64  // non-cumulative; one for each bound
+	Count  uint64    // +Inf included
+	Sum    float64
+}
+
+// NewHistogram is a constructor. Bounds are upper bounds of buckets, +Inf excluded.
+func NewHistogram(bounds ...float64) *Histogram {
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+	return &Histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+// Observe a value.
+func (h *Histogram) Observe(v float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if i := sort.SearchFloat64s(h.bounds, v); i < len(h.bounds) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// Snapshot returns the histogram as of now.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: append([]uint64(nil), h.counts...),
+		Count:  h.count,
+		Sum:    h.sum,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Server
+
+// Server serves metrics at /metrics.
+type Server struct {
+	srv *remote.Server
+}
+
+// Serve starts listening on a local address and serving metrics in the background.
+// The address is checked by remote.LocalAddr(). The collect func is called on every scrape, from a goroutine of the server.
+func Serve(addr string, collect func(w *Writer)) (*Server, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(collect))
+	srv, err := remote.Serve(addr, mux)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{srv}, nil
+}
+
+// Addr returns the address it's listening on.
+func (s *Server) Addr() string {
+	return s.srv.Addr()
+}
+
+// Close stops serving.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
+
+// Handler returns an http.Handler that serves metrics collected on every request.
+func Handler(collect func(w *Writer)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		mw := NewWriter(w)
+		collect(mw)
+		mw.Flush()
+	})
+}
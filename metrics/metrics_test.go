@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	h := NewHistogram(0.01, 0.001)
+	h.Observe(0.0005)
+	h.Observe(0.005)
+	h.Observe(0.5)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Gauge("visual_fps", "Frames per second.", 60)
+	w.Counter("visual_frames_total", "Frames so far.", 3, "title", `a "quoted" one`)
+	w.GaugeVec("visual_phase_seconds", "Phases.", "phase", map[string]float64{"update": 0.25, "draw": 0.5})
+	w.Histogram("visual_frame_seconds", "Frame times.", h.Snapshot())
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP visual_fps Frames per second.
+# TYPE visual_fps gauge
+visual_fps 60
+# HELP visual_frames_total Frames so far.
+# TYPE visual_frames_total counter
+visual_frames_total{title="a \"quoted\" one"} 3
+# HELP visual_phase_seconds Phases.
+# TYPE visual_phase_seconds gauge
+visual_phase_seconds{phase="draw"} 0.5
+visual_phase_seconds{phase="update"} 0.25
+# HELP visual_frame_seconds Frame times.
+# TYPE visual_frame_seconds histogram
+visual_frame_seconds_bucket{le="0.001"} 1
+visual_frame_seconds_bucket{le="0.01"} 2
+visual_frame_seconds_bucket{le="+Inf"} 3
+visual_frame_seconds_sum 0.5055
+visual_frame_seconds_count 3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("written:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestServe(t *testing.T) {
+	s, err := Serve("127.0.0.1:0", func(w *Writer) {
+		w.Gauge("visual_actors", "Actors.", 2)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	resp, err := http.Get("http://" + s.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); ct != ContentType {
+		t.Errorf("content type %q", ct)
+	}
+	if !bytes.Contains(body, []byte("visual_actors 2\n")) {
+		t.Errorf("scraped %q", body)
+	}
+}
@@ -0,0 +1,124 @@
+package visual
+
+import (
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/mirror"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Mirroring
+
+// maxMirrorAge is the most latency compensated for; anything older is stale rather than late.
+const maxMirrorAge = time.Second
+
+// LeadAddr returns the address followers connect to, such as "192.168.0.10:9468".
+// It's empty unless leading. (See Config.LeadAddr and the mirror package.)
+//
+// Every frame the leader broadcasts its camera, time scale, pause and game clock,
+// and followers apply them as they'd be by now, compensating for the latency.
+// Each of them shows its part of the screen of the leader set by Config.Viewport,
+// so that a wall of screens of the same size shows what the leader shows, only larger.
+func (v *Visualizer) LeadAddr() string {
+	if v.leader == nil {
+		return ""
+	}
+	return v.leader.Addr()
+}
+
+// IsFollowing determines whether the visualizer is connected to a leader right now.
+func (v *Visualizer) IsFollowing() bool {
+	return v.follower != nil && v.follower.Connected()
+}
+
+// StartMirror starts leading or following, by Config.LeadAddr or Config.FollowAddr.
+// It should be called on lazy init.
+func (v *Visualizer) _StartMirror() {
+	if v.leadAddr != "" {
+		l, err := mirror.Listen(v.leadAddr)
+		if err != nil {
+			v.logger.Error("leader failed to listen", "addr", v.leadAddr, "err", err)
+			return
+		}
+		v.leader = l
+		v.logger.Info("leading followers", "addr", l.Addr())
+	}
+	if v.followAddr != "" {
+		v.follower = mirror.Follow(v.followAddr)
+		v.logger.Info("following a leader", "addr", v.followAddr)
+	}
+}
+
+// StopMirror disconnects from followers or the leader.
+func (v *Visualizer) _StopMirror() {
+	if v.leader != nil {
+		if err := v.leader.Close(); err != nil {
+			v.logger.Warn("leader failed to stop", "err", err)
+		}
+		v.leader = nil
+	}
+	if v.follower != nil {
+		v.follower.Close()
+		v.follower = nil
+	}
+}
+
+// Mirror broadcasts the state of this frame if leading, or applies the latest one if following.
+// It should be called after the update and before the draw.
+func (v *Visualizer) _Mirror() {
+	if v.leader != nil {
+		v.leader.Broadcast(mirror.State{
+			Camera:       mirrorCamera(v.camera.State()),
+			TimeScale:    v.TimeScale(),
+			Paused:       v.IsPaused(),
+			ClockStarted: v.dtw.GetTimeStarted().UnixNano(),
+		})
+	}
+	if v.follower == nil {
+		return
+	}
+	s, age, ok := v.follower.Latest()
+	if !ok {
+		return
+	}
+	if age > maxMirrorAge {
+		age = maxMirrorAge
+	}
+	if s.TimeScale != v.TimeScale() {
+		v.SetTimeScale(s.TimeScale)
+	}
+	v.SetPaused(s.Paused)
+	v.dtw.SetTimeStarted(v.follower.LocalTime(s.ClockStarted))
+
+	// The camera is where the leader's was, then moves on as it would've meanwhile.
+	v.camera.SetState(cameraMirrored(s.Camera))
+	v.camera.Update(age.Seconds() * s.TimeScale)
+}
+
+// mirrorCamera converts the state of a camera into what's broadcast.
+func mirrorCamera(state super.CameraState) mirror.Camera {
+	return mirror.Camera{
+		X:           state.Pos.X,
+		Y:           state.Pos.Y,
+		Zoom:        state.Zoom,
+		Angle:       state.Angle,
+		FollowX:     state.PosFollow.X,
+		FollowY:     state.PosFollow.Y,
+		FollowZoom:  state.ZoomFollow,
+		FollowAngle: state.AngleFollow,
+	}
+}
+
+// cameraMirrored converts what's broadcast back into the state of a camera.
+func cameraMirrored(c mirror.Camera) super.CameraState {
+	return super.CameraState{
+		Angle:       c.Angle,
+		AngleFollow: c.FollowAngle,
+		Zoom:        c.Zoom,
+		ZoomFollow:  c.FollowZoom,
+		Pos:         pixel.V(c.X, c.Y),
+		PosFollow:   pixel.V(c.FollowX, c.FollowY),
+	}
+}
@@ -0,0 +1,416 @@
+// Package mirror keeps visualizers on several machines showing the same thing, such as a wall of screens.
+// A leader broadcasts its state every frame over TCP, and followers mirror it, compensating for the latency.
+//
+// It's one JSON object per line both ways.
+// Followers ping the leader every once in a while to learn how far apart the two clocks are.
+//
+//	{"state": {"seq": 1, "sent": 1700000000000000000, "camera": {"x": 512, "y": 384, "zoom": 1, ...}, "time_scale": 1, "paused": false, "clock_started": 1699999990000000000}}
+//	{"ping": 1700000000000000000}
+//	{"pong": {"ping": 1700000000000000000, "at": 1700000000000500000}}
+//
+// There's no authentication; a leader should listen only on a network trusted.
+package mirror
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// -------------------------------------------------------------------------
+// Messages
+
+// Camera is the whole state of a camera, both physical and followed. The angles are in radians.
+type Camera struct {
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Zoom        float64 `json:"zoom"`
+	Angle       float64 `json:"angle"`
+	FollowX     float64 `json:"follow_x"`
+	FollowY     float64 `json:"follow_y"`
+	FollowZoom  float64 `json:"follow_zoom"`
+	FollowAngle float64 `json:"follow_angle"`
+}
+
+// State is what a leader broadcasts. Times are in Unix nanoseconds on the leader's clock.
+type State struct {
+	Seq          uint64  `json:"seq"`  // set by Broadcast()
+	Sent         int64   `json:"sent"` // set by Broadcast()
+	Camera       Camera  `json:"camera"`
+	TimeScale    float64 `json:"time_scale"`
+	Paused       bool    `json:"paused"`
+	ClockStarted int64   `json:"clock_started"` // when the game clock started
+}
+
+// Pong is a reply to a ping; the time the ping was sent on the follower's clock,
+// and the time it got to the leader on the leader's clock, both in Unix nanoseconds.
+type Pong struct {
+	Ping int64 `json:"ping"`
+	At   int64 `json:"at"`
+}
+
+// message is a line either way; one of the fields is set.
+type message struct {
+	State *State `json:"state,omitempty"`
+	Ping  int64  `json:"ping,omitempty"`
+	Pong  *Pong  `json:"pong,omitempty"`
+}
+
+// -------------------------------------------------------------------------
+// Leader
+
+// queueSize is how many lines wait to be sent to a follower before more are dropped.
+const queueSize = 8
+
+// Leader accepts followers and broadcasts states to them.
+type Leader struct {
+	ln     net.Listener
+	mutex  sync.Mutex
+	out    map[net.Conn]chan message
+	seq    uint64
+	closed bool // so that followers accepted while closing get disconnected as well
+	wg     sync.WaitGroup
+}
+
+// Listen for followers on a TCP address such as ":9468".
+func Listen(addr string) (*Leader, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	l := &Leader{ln: ln, out: map[net.Conn]chan message{}}
+	l.wg.Add(1)
+	go l._Accept()
+	return l, nil
+}
+
+// Addr returns the address it's listening on.
+func (l *Leader) Addr() string {
+	return l.ln.Addr().String()
+}
+
+// Followers returns how many followers are connected.
+func (l *Leader) Followers() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.out)
+}
+
+// Broadcast a state to all followers, numbered and stamped with the time now.
+// It never blocks; a follower too slow to keep up misses some.
+func (l *Leader) Broadcast(s State) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.seq++
+	s.Seq = l.seq
+	s.Sent = time.Now().UnixNano()
+	for _, out := range l.out {
+		select {
+		case out <- message{State: &s}:
+		default:
+		}
+	}
+}
+
+// Close stops listening and disconnects all followers.
+func (l *Leader) Close() error {
+	err := l.ln.Close()
+	l.mutex.Lock()
+	l.closed = true
+	for conn := range l.out {
+		conn.Close()
+	}
+	l.mutex.Unlock()
+	l.wg.Wait()
+	return err
+}
+
+// unexported
+func (l *Leader) _Accept() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			return
+		}
+		out := make(chan message, queueSize)
+		l.mutex.Lock()
+		if l.closed {
+			l.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		l.out[conn] = out
+		l.wg.Add(2)
+		l.mutex.Unlock()
+
+		go l._Send(conn, out)
+		go l._Receive(conn, out)
+	}
+}
+
+// unexported
+func (l *Leader) _Send(conn net.Conn, out chan message) {
+	defer l.wg.Done()
+	defer conn.Close() // which ends _Receive()
+
+	w := bufio.NewWriter(conn)
+	enc := json.NewEncoder(w)
+	for m := range out {
+		if enc.Encode(m) != nil {
+			return
+		}
+		if len(out) == 0 && w.Flush() != nil { // lines go out once there's nothing more to send right away
+			return
+		}
+	}
+}
+
+// unexported
+func (l *Leader) _Receive(conn net.Conn, out chan message) {
+	defer l.wg.Done()
+	defer func() {
+		l.mutex.Lock()
+		delete(l.out, conn)
+		close(out) // which ends _Send()
+		l.mutex.Unlock()
+	}()
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var m message
+		if dec.Decode(&m) != nil {
+			return // io.EOF, closed or garbage
+		}
+		if m.Ping == 0 {
+			continue
+		}
+		pong := message{Pong: &Pong{Ping: m.Ping, At: time.Now().UnixNano()}}
+		l.mutex.Lock()
+		select {
+		case out <- pong:
+		default:
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// -------------------------------------------------------------------------
+// Follower
+
+// Intervals of a follower.
+const (
+	RetryInterval = time.Second // to reconnect to a leader
+	PingInterval  = time.Second // to ping a leader, after a few pings in a row on connecting
+)
+
+const (
+	nSamples      = 8                     // pongs kept to estimate the clock offset
+	firstPings    = 4                     // in a row on connecting
+	firstInterval = 50 * time.Millisecond // between the first pings
+	dialTimeout   = 2 * time.Second       // to connect to a leader
+	maxRTT        = 10 * time.Second      // beyond which a pong makes no sense
+)
+
+// ErrClosed is returned by a Follower that's closed.
+var ErrClosed = errors.New("mirror: follower closed")
+
+// sample is the clock offset learned from a pong along with its round trip time.
+type sample struct {
+	offset, rtt time.Duration
+}
+
+// Follower connects to a leader and keeps the latest state it broadcasts.
+// It reconnects whenever disconnected until closed; the leader can start after or restart.
+type Follower struct {
+	addr      string
+	mutex     sync.Mutex
+	conn      net.Conn // nil unless connected
+	state     State
+	hasState  bool
+	samples   []sample // the latest ones
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Follow a leader at a TCP address such as "192.168.0.10:9468", in the background.
+func Follow(addr string) *Follower {
+	f := &Follower{addr: addr, closed: make(chan struct{})}
+	f.wg.Add(1)
+	go f._Run()
+	return f
+}
+
+// Connected determines whether it's connected to the leader right now.
+func (f *Follower) Connected() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.conn != nil
+}
+
+// Latest returns the latest state received, if any,
+// along with how long ago the leader sent it; the latency to compensate for.
+func (f *Follower) Latest() (s State, age time.Duration, ok bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.hasState {
+		return State{}, 0, false
+	}
+	age = time.Duration(f._LeaderNow(time.Now()) - f.state.Sent)
+	if age < 0 {
+		age = 0
+	}
+	return f.state, age, true
+}
+
+// Offset returns how far the leader's clock is ahead of this one, estimated from the pong of the shortest round trip lately.
+// It's zero until the first pong.
+func (f *Follower) Offset() time.Duration {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f._Best().offset
+}
+
+// RTT returns the shortest round trip time lately. It's zero until the first pong.
+func (f *Follower) RTT() time.Duration {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f._Best().rtt
+}
+
+// LocalTime converts a time on the leader's clock in Unix nanoseconds, such as State.ClockStarted, to this one.
+func (f *Follower) LocalTime(leaderTime int64) time.Time {
+	return time.Unix(0, leaderTime).Add(-f.Offset())
+}
+
+// Close disconnects from the leader and stops reconnecting.
+func (f *Follower) Close() error {
+	err := ErrClosed
+	f.closeOnce.Do(func() {
+		err = nil
+		close(f.closed)
+		f.mutex.Lock()
+		if f.conn != nil {
+			f.conn.Close()
+		}
+		f.mutex.Unlock()
+	})
+	f.wg.Wait()
+	return err
+}
+
+// unexported; The caller is responsible for locking it up.
+func (f *Follower) _Best() sample {
+	best := sample{}
+	for i, s := range f.samples {
+		if i == 0 || s.rtt < best.rtt {
+			best = s
+		}
+	}
+	return best
+}
+
+// unexported; The caller is responsible for locking it up.
+func (f *Follower) _LeaderNow(now time.Time) int64 {
+	return now.Add(f._Best().offset).UnixNano()
+}
+
+// unexported
+func (f *Follower) _Run() {
+	defer f.wg.Done()
+	for {
+		conn, err := net.DialTimeout("tcp", f.addr, dialTimeout)
+		if err == nil {
+			f._Serve(conn)
+		}
+		select {
+		case <-f.closed:
+			return
+		case <-time.After(RetryInterval):
+		}
+	}
+}
+
+// unexported
+func (f *Follower) _Serve(conn net.Conn) {
+	f.mutex.Lock()
+	select {
+	case <-f.closed:
+		f.mutex.Unlock()
+		conn.Close()
+		return
+	default:
+	}
+	f.conn = conn
+	f.samples = nil // The leader may have restarted on another machine.
+	f.mutex.Unlock()
+
+	stopPinging := make(chan struct{})
+	pinged := make(chan struct{})
+	go func() {
+		defer close(pinged)
+		f._Ping(conn, stopPinging)
+	}()
+	defer func() {
+		conn.Close()
+		close(stopPinging)
+		<-pinged
+		f.mutex.Lock()
+		f.conn = nil
+		f.mutex.Unlock()
+	}()
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var m message
+		if dec.Decode(&m) != nil {
+			return // io.EOF, closed or garbage
+		}
+		now := time.Now()
+		f.mutex.Lock()
+		if m.State != nil {
+			f.state = *m.State
+			f.hasState = true
+		}
+		if m.Pong != nil {
+			rtt := now.Sub(time.Unix(0, m.Pong.Ping))
+			if 0 <= rtt && rtt < maxRTT {
+				offset := time.Unix(0, m.Pong.At).Sub(time.Unix(0, m.Pong.Ping).Add(rtt / 2))
+				f.samples = append(f.samples, sample{offset, rtt})
+				if len(f.samples) > nSamples {
+					f.samples = f.samples[1:]
+				}
+			}
+		}
+		f.mutex.Unlock()
+	}
+}
+
+// unexported
+func (f *Follower) _Ping(conn net.Conn, stop <-chan struct{}) {
+	enc := json.NewEncoder(conn)
+	for i := 0; ; i++ {
+		if enc.Encode(message{Ping: time.Now().UnixNano()}) != nil {
+			return
+		}
+		interval := PingInterval
+		if i < firstPings {
+			interval = firstInterval
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package mirror
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// waitFor polls a condition for up to 5 seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestMirror(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Follow(l.Addr())
+	defer f.Close()
+
+	waitFor(t, "the follower", func() bool { return l.Followers() == 1 && f.Connected() })
+	if _, _, ok := f.Latest(); ok {
+		t.Error("a state before any broadcast")
+	}
+	started := time.Now().Add(-time.Minute).UnixNano()
+	l.Broadcast(State{Camera: Camera{X: 1, Y: 2, Zoom: 3}, TimeScale: 0.5, Paused: true, ClockStarted: started})
+	waitFor(t, "the state", func() bool { _, _, ok := f.Latest(); return ok })
+	s, age, _ := f.Latest()
+	if s.Seq != 1 || s.Camera.X != 1 || s.Camera.Zoom != 3 || s.TimeScale != 0.5 || !s.Paused {
+		t.Errorf("received %+v", s)
+	}
+	if age < 0 || age > time.Second {
+		t.Errorf("age %v", age)
+	}
+
+	// On the same machine the clocks are the same.
+	waitFor(t, "pongs", func() bool { return f.RTT() > 0 })
+	if off := f.Offset(); off < -50*time.Millisecond || off > 50*time.Millisecond {
+		t.Errorf("offset %v; want about zero", off)
+	}
+	if d := f.LocalTime(started).Sub(time.Unix(0, started)); d < -50*time.Millisecond || d > 50*time.Millisecond {
+		t.Errorf("local time off by %v", d)
+	}
+
+	// The follower reconnects to a leader restarted.
+	addr := l.Addr()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the disconnection", func() bool { return !f.Connected() })
+	if l, err = Listen(addr); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	waitFor(t, "the reconnection", func() bool { return l.Followers() == 1 })
+	l.Broadcast(State{Camera: Camera{X: 4}})
+	waitFor(t, "the state after reconnecting", func() bool { s, _, _ := f.Latest(); return s.Camera.X == 4 })
+
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := f.Close(); err != ErrClosed {
+		t.Errorf("closed twice: %v", err)
+	}
+	waitFor(t, "the follower gone", func() bool { return l.Followers() == 0 })
+}
+
+func TestLeaderCloseWhileAccepting(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		l, err := Listen("127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 5; j++ {
+			go func() {
+				if conn, err := net.Dial("tcp", l.Addr()); err == nil {
+					defer conn.Close()
+					conn.Read(make([]byte, 1)) // until disconnected
+				}
+			}()
+		}
+		closed := make(chan struct{})
+		go func() {
+			l.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("a leader closed while accepting followers never returns")
+		}
+		if n := l.Followers(); n != 0 {
+			t.Errorf("%d followers after closed; want 0", n)
+		}
+	}
+}
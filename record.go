@@ -0,0 +1,149 @@
+package visual
+
+import (
+	"io"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/nanitefactory/visual/replay"
+)
+
+// -------------------------------------------------------------------------
+// Record and replay
+
+// replayInput is an Input that plays a replay back, frame by frame.
+type replayInput struct {
+	r          *replay.Reader
+	prev, curr replayInputState
+	done       bool
+	err        error // other than io.EOF
+}
+
+type replayInputState struct {
+	replay.Frame
+	pressed map[pixelgl.Button]bool
+}
+
+func (ri *replayInput) Pressed(button pixelgl.Button) bool {
+	return ri.curr.pressed[button]
+}
+
+func (ri *replayInput) JustPressed(button pixelgl.Button) bool {
+	return ri.curr.pressed[button] && !ri.prev.pressed[button]
+}
+
+func (ri *replayInput) JustReleased(button pixelgl.Button) bool {
+	return !ri.curr.pressed[button] && ri.prev.pressed[button]
+}
+
+func (ri *replayInput) MousePosition() pixel.Vec {
+	return ri.curr.Mouse
+}
+
+func (ri *replayInput) MouseScroll() pixel.Vec {
+	return ri.curr.Scroll
+}
+
+func (ri *replayInput) Typed() string {
+	return ri.curr.Typed
+}
+
+func (ri *replayInput) UpdateInput() {
+	if ri.done {
+		return
+	}
+	f, err := ri.r.ReadFrame()
+	if err != nil {
+		ri.done = true
+		if err != io.EOF {
+			ri.err = err
+		}
+		return
+	}
+	ri.prev = ri.curr
+	ri.curr = replayInputState{f, make(map[pixelgl.Button]bool, len(f.Pressed))}
+	for _, button := range f.Pressed {
+		ri.curr.pressed[pixelgl.Button(button)] = true
+	}
+}
+
+// StartRecordAndReplay sets up Config.ReplayFrom and Config.RecordTo, if any.
+// It should be called on lazy init before the very first frame.
+func (v *Visualizer) _StartRecordAndReplay() {
+	if v.replayFrom != nil {
+		r, err := replay.NewReader(v.replayFrom)
+		if err != nil {
+			v.logger.Error("replay failed to start", "err", err)
+		} else {
+			v.seed = r.Seed()
+			v.explosions.Seed(v.seed)
+			v.replaying = &replayInput{r: r}
+			v.inputBeforeReplay = v.input
+			v.input = v.replaying
+		}
+	}
+	if v.recordTo != nil {
+		if v.seed == 0 { // Randomness must be seeded so that it can be replayed.
+			v.seed = time.Now().UnixNano()
+			v.explosions.Seed(v.seed)
+		}
+		w, err := replay.NewWriter(v.recordTo, v.seed)
+		if err != nil {
+			v.logger.Error("recording failed to start", "err", err)
+		} else {
+			v.recording = w
+		}
+	}
+}
+
+// StopReplay hands user inputs back to where they were read from before the replay.
+func (v *Visualizer) _StopReplay() {
+	if v.replaying.err != nil {
+		v.logger.Warn("replay stopped", "err", v.replaying.err)
+	}
+	if v.input == Input(v.replaying) {
+		v.input = v.inputBeforeReplay
+	}
+	v.replaying = nil
+	v.inputBeforeReplay = nil
+}
+
+// RecordFrame writes dt and user inputs of this frame.
+func (v *Visualizer) _RecordFrame(dt float64) {
+	in := v._Input()
+	f := replay.Frame{
+		Dt:     dt,
+		Mouse:  in.MousePosition(),
+		Scroll: in.MouseScroll(),
+	}
+	for button := pixelgl.Button(0); button <= pixelgl.KeyLast; button++ {
+		if in.Pressed(button) {
+			f.Pressed = append(f.Pressed, int(button))
+		}
+	}
+	if typing, ok := in.(TextInput); ok {
+		f.Typed = typing.Typed()
+	}
+	err := v.recording.WriteFrame(f)
+	if err == nil {
+		if v.nRecorded++; v.nRecorded%60 == 0 { // Every once in a while, not to lose much on a crash.
+			err = v.recording.Flush()
+		}
+	}
+	if err != nil {
+		v.logger.Error("recording stopped", "frame", v.nRecorded, "err", err)
+		v.recording = nil
+	}
+}
+
+// StopRecording flushes what's recorded so far.
+func (v *Visualizer) _StopRecording() {
+	if v.recording == nil {
+		return
+	}
+	if err := v.recording.Flush(); err != nil {
+		v.logger.Error("recording stopped", "frame", v.nRecorded, "err", err)
+	}
+	v.recording = nil
+}
@@ -0,0 +1,516 @@
+package visual
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // to decode sprites
+	_ "image/png"  // to decode sprites
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/actors"
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Actor types
+
+// ActorFactory creates an actor out of its properties.
+// Props can be nil, in which case the factory should create an actor of its default.
+type ActorFactory func(props map[string]interface{}) (Actor, error)
+
+// Types of a Param.
+const (
+	ParamNumber = "number"
+	ParamString = "string"
+	ParamBool   = "bool"
+	ParamVec    = "vec"    // [x, y]
+	ParamVecs   = "vecs"   // [[x, y], ...]
+	ParamColor  = "color"  // "#RRGGBB", "#RRGGBBAA" or a name in golang.org/x/image/colornames
+	ParamColors = "colors" // [color, ...]
+)
+
+// Param describes a prop an actor type takes, for tools to list. (See ActorSchemas().)
+type Param struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Default interface{} `json:"default,omitempty"` // in JSON types, such as []float64{0, 0} for a vec
+	Values  []string    `json:"values,omitempty"`  // that a string can only be, if limited
+	Doc     string      `json:"doc,omitempty"`
+}
+
+// ActorSchema describes an actor type registered; what it's called, what it creates and what props it takes.
+type ActorSchema struct {
+	Name   string  `json:"name"`
+	GoType string  `json:"go_type"` // of actors it creates, such as "*actors.Shape"; empty if unknown
+	HUD    bool    `json:"hud"`     // whether they're HUDs as well
+	Params []Param `json:"params"`
+}
+
+var registry = struct {
+	sync.Mutex
+	factories map[string]ActorFactory
+	names     map[reflect.Type]string
+	schemas   map[string]ActorSchema
+}{
+	factories: map[string]ActorFactory{},
+	names:     map[reflect.Type]string{},
+	schemas:   map[string]ActorSchema{},
+}
+
+// RegisterActorType makes an actor type known by a name, so that it can be created by name;
+// as it gets restored from a state saved, or read from a scene file for example.
+// Params describe the props its factory takes, for tools to list; they're optional.
+// The factory gets called once with nil props right away to find out the type of actors it creates;
+// if it fails or panics on that, the type is still registered but its Go type is left unknown.
+// It panics if the name is already taken, just as the standard library does on registering things twice.
+func RegisterActorType(name string, factory ActorFactory, params ...Param) {
+	// Not locked yet, so that the factory can do anything with the registry.
+	sample := sampleActor(factory)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.factories[name]; ok {
+		panic(fmt.Errorf("visual: actor type %q registered twice", name))
+	}
+	registry.factories[name] = factory
+	schema := ActorSchema{Name: name, Params: append([]Param{}, params...)}
+	if sample != nil {
+		registry.names[reflect.TypeOf(sample)] = name
+		schema.GoType = reflect.TypeOf(sample).String()
+		_, schema.HUD = sample.(HUD)
+	}
+	registry.schemas[name] = schema
+}
+
+// sampleActor creates an actor of its default with a factory, or returns nil if it can't.
+func sampleActor(factory ActorFactory) (sample Actor) {
+	defer func() {
+		if recover() != nil {
+			sample = nil
+		}
+	}()
+	sample, err := factory(nil)
+	if err != nil {
+		return nil
+	}
+	return sample
+}
+
+// NewActor creates an actor of a type registered by name.
+func NewActor(typeName string, props map[string]interface{}) (Actor, error) {
+	registry.Lock()
+	factory, ok := registry.factories[typeName]
+	registry.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("visual: actor type %q is not registered", typeName)
+	}
+	actor, err := factory(props)
+	if err != nil {
+		return nil, fmt.Errorf("visual: %s: %v", typeName, err)
+	}
+	if actor == nil {
+		return nil, fmt.Errorf("visual: %s: factory created nothing", typeName)
+	}
+
+	registry.Lock()
+	registry.names[reflect.TypeOf(actor)] = typeName
+	registry.Unlock()
+	return actor, nil
+}
+
+// ActorTypeName returns the name an actor's type is registered by.
+func ActorTypeName(actor Actor) (name string, ok bool) {
+	if actor == nil {
+		return "", false
+	}
+	registry.Lock()
+	defer registry.Unlock()
+
+	name, ok = registry.names[reflect.TypeOf(actor)]
+	return name, ok
+}
+
+// ActorSchemaOf returns the schema of an actor type registered by name.
+func ActorSchemaOf(typeName string) (schema ActorSchema, ok bool) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	schema, ok = registry.schemas[typeName]
+	schema.Params = append([]Param{}, schema.Params...)
+	return schema, ok
+}
+
+// ActorSchemas returns the schemas of all actor types registered, in order of their names.
+func ActorSchemas() []ActorSchema {
+	names := ActorTypeNames()
+	ret := make([]ActorSchema, 0, len(names))
+	for _, name := range names {
+		if schema, ok := ActorSchemaOf(name); ok {
+			ret = append(ret, schema)
+		}
+	}
+	return ret
+}
+
+// ActorTypeNames returns the names of all actor types registered, in order.
+func ActorTypeNames() []string {
+	registry.Lock()
+	defer registry.Unlock()
+
+	ret := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// -------------------------------------------------------------------------
+// Built-in actor types
+
+// Params of built-in actor types.
+var (
+	shapeParams = []Param{
+		{Name: "shape", Type: ParamString, Default: "rect", Values: []string{"rect", "circle", "line", "polygon"}},
+		{Name: "points", Type: ParamVecs, Default: [][]float64{{0, 0}, {100, 100}}, Doc: "the min and max corners of a rect, the center of a circle, or the points of a line or a polygon"},
+		{Name: "radius", Type: ParamNumber, Default: 0.0, Doc: "of a circle"},
+		{Name: "thickness", Type: ParamNumber, Default: 0.0, Doc: "of the outline; 0 fills it in"},
+		{Name: "color", Type: ParamColor, Default: "white"},
+	}
+	labelParams = []Param{
+		{Name: "text", Type: ParamString, Default: ""},
+		{Name: "pos", Type: ParamVec, Default: []float64{0, 0}, Doc: "the bottom left corner"},
+		{Name: "scale", Type: ParamNumber, Default: 1.0, Doc: "1 is 18 points"},
+		{Name: "color", Type: ParamColor, Default: "white"},
+	}
+	spriteParams = []Param{
+		{Name: "image", Type: ParamString, Default: "", Doc: "a path to a PNG or JPEG file from the working directory; a transparent pixel if empty"},
+		{Name: "pos", Type: ParamVec, Default: []float64{0, 0}, Doc: "the center"},
+		{Name: "scale", Type: ParamNumber, Default: 1.0},
+		{Name: "angle", Type: ParamNumber, Default: 0.0, Doc: "in degrees"},
+	}
+	explosionsParams = []Param{
+		{Name: "width", Type: ParamNumber, Default: DefaultWinWidth, Doc: "of the bound particles bounce off"},
+		{Name: "height", Type: ParamNumber, Default: DefaultWinHeight},
+		{Name: "colors", Type: ParamColors, Doc: "of particles; a set of its own by default"},
+		{Name: "precision", Type: ParamNumber, Default: 4.0, Doc: "of circles particles are drawn as"},
+		{Name: "seed", Type: ParamNumber, Default: 0.0, Doc: "of the randomness; random if 0"},
+	}
+	fpsWatchParams = []Param{
+		{Name: "caption", Type: ParamString, Default: "", Doc: "after the FPS"},
+		{Name: "anchor_y", Type: ParamString, Default: "top", Values: []string{"top", "middle", "bottom"}},
+		{Name: "anchor_x", Type: ParamString, Default: "right", Values: []string{"left", "center", "right"}},
+		{Name: "bg", Type: ParamColor, Default: "black"},
+		{Name: "color", Type: ParamColor, Default: "white"},
+	}
+)
+
+func init() {
+	RegisterActorType("shape", newShapeActor, shapeParams...)
+	RegisterActorType("label", newLabelActor, labelParams...)
+	RegisterActorType("sprite", newSpriteActor, spriteParams...)
+	RegisterActorType("explosions", newExplosionsActor, explosionsParams...)
+	RegisterActorType("fpswatch", newFPSWatchActor, fpsWatchParams...)
+}
+
+// newShapeActor creates an actors.Shape out of props.
+func newShapeActor(props map[string]interface{}) (Actor, error) {
+	r := readProps(props, shapeParams)
+	kind := r.str("shape")
+	points := r.vecs("points")
+	radius := r.float("radius")
+	thickness := r.float("thickness")
+	col := r.color("color")
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	shapeKind, err := super.ParseShapeKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	return actors.NewShape(shapeKind, points, radius, thickness, col)
+}
+
+// newLabelActor creates an actors.Label out of props.
+func newLabelActor(props map[string]interface{}) (Actor, error) {
+	r := readProps(props, labelParams)
+	str := r.str("text")
+	pos := r.vec("pos")
+	scale := r.float("scale")
+	col := r.color("color")
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return actors.NewLabel(str, pos, scale, col), nil
+}
+
+// newSpriteActor creates an actors.Sprite out of props.
+func newSpriteActor(props map[string]interface{}) (Actor, error) {
+	r := readProps(props, spriteParams)
+	path := r.str("image")
+	pos := r.vec("pos")
+	scale := r.float("scale")
+	angle := r.float("angle")
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	var img image.Image = image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if img, _, err = image.Decode(f); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return actors.NewSprite(img, pos, scale, angle), nil
+}
+
+// newExplosionsActor creates an actors.Explosions out of props.
+func newExplosionsActor(props map[string]interface{}) (Actor, error) {
+	r := readProps(props, explosionsParams)
+	width := r.float("width")
+	height := r.float("height")
+	colors := r.colors("colors")
+	precision := r.float("precision")
+	seed := r.float("seed")
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	if precision < 0 || precision != math.Trunc(precision) {
+		return nil, fmt.Errorf("prop \"precision\" must be a whole number, not %v", precision)
+	}
+	explosions := actors.NewExplosions(width, height, colors, int(precision))
+	if seed != 0 {
+		explosions.Seed(int64(seed))
+	}
+	return explosions, nil
+}
+
+// newFPSWatchActor creates an actors.FPSWatch out of props, which polls by itself.
+func newFPSWatchActor(props map[string]interface{}) (Actor, error) {
+	r := readProps(props, fpsWatchParams)
+	caption := r.str("caption")
+	anchorY := super.AnchorY(1 + indexOf(fpsWatchParams[1].Values, r.str("anchor_y")))
+	anchorX := super.AnchorX(1 + indexOf(fpsWatchParams[2].Values, r.str("anchor_x")))
+	bg := r.color("bg")
+	col := r.color("color")
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	watch := actors.NewFPSWatch(caption, pixel.ZV, anchorY, anchorX, bg, col)
+	watch.SetSelfPolling(true)
+	return watch, nil
+}
+
+func indexOf(strs []string, s string) int {
+	for i, str := range strs {
+		if str == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// -------------------------------------------------------------------------
+// Props
+
+// propReader reads props given to an ActorFactory in JSON or YAML types, falling back on defaults of params.
+// It keeps the first error of a prop of a wrong type, and fails on props unknown as well.
+type propReader struct {
+	props  map[string]interface{}
+	params map[string]Param
+	err    error
+}
+
+func readProps(props map[string]interface{}, params []Param) *propReader {
+	r := &propReader{props: props, params: make(map[string]Param, len(params))}
+	for _, p := range params {
+		r.params[p.Name] = p
+	}
+	return r
+}
+
+// get returns a prop, or its default.
+func (r *propReader) get(key string) interface{} {
+	if value, ok := r.props[key]; ok && value != nil {
+		return value
+	}
+	return r.params[key].Default
+}
+
+func (r *propReader) fail(key string, value interface{}, want string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("prop %q must be %s, not %v", key, want, value)
+	}
+}
+
+func (r *propReader) float(key string) float64 {
+	value := r.get(key)
+	f, ok := toFloat(value)
+	if !ok && value != nil {
+		r.fail(key, value, "a number")
+	}
+	return f
+}
+
+func (r *propReader) str(key string) string {
+	value := r.get(key)
+	s, ok := value.(string)
+	if !ok && value != nil {
+		r.fail(key, value, "a string")
+	}
+	if values := r.params[key].Values; ok && len(values) > 0 && indexOf(values, s) < 0 {
+		r.fail(key, s, fmt.Sprintf("one of %q", values))
+		return r.params[key].Default.(string)
+	}
+	return s
+}
+
+func (r *propReader) vec(key string) pixel.Vec {
+	value := r.get(key)
+	vec, ok := toVec(value)
+	if !ok && value != nil {
+		r.fail(key, value, "[x, y]")
+	}
+	return vec
+}
+
+func (r *propReader) vecs(key string) []pixel.Vec {
+	value := r.get(key)
+	list, ok := toList(value)
+	if !ok {
+		if value != nil {
+			r.fail(key, value, "[[x, y], ...]")
+		}
+		return nil
+	}
+	vecs := make([]pixel.Vec, len(list))
+	for i := range list {
+		if vecs[i], ok = toVec(list[i]); !ok {
+			r.fail(key, value, "[[x, y], ...]")
+			return nil
+		}
+	}
+	return vecs
+}
+
+func (r *propReader) color(key string) pixel.RGBA {
+	s := r.str(key)
+	col, err := ParseColor(s)
+	if err != nil {
+		r.fail(key, s, `"#RRGGBB", "#RRGGBBAA" or a color name`)
+	}
+	return col
+}
+
+func (r *propReader) colors(key string) []color.Color {
+	value := r.get(key)
+	list, ok := toList(value)
+	if !ok {
+		if value != nil {
+			r.fail(key, value, "[color, ...]")
+		}
+		return nil
+	}
+	colors := make([]color.Color, len(list))
+	for i := range list {
+		s, _ := list[i].(string)
+		col, err := ParseColor(s)
+		if err != nil {
+			r.fail(key, value, "[color, ...]")
+			return nil
+		}
+		colors[i] = col
+	}
+	return colors
+}
+
+// done returns the first error, or an error of props unknown.
+func (r *propReader) done() error {
+	if r.err != nil {
+		return r.err
+	}
+	unknown := []string{}
+	for key := range r.props {
+		if _, ok := r.params[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown props %q", unknown)
+	}
+	return nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// toList converts a list in JSON or YAML types, or of a default, to []interface{}.
+func toList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = v[i]
+		}
+		return list, true
+	case [][]float64:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = v[i]
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// toVec converts [x, y] or {"x": x, "y": y} to a vector.
+func toVec(value interface{}) (vec pixel.Vec, ok bool) {
+	switch v := value.(type) {
+	case []float64:
+		if len(v) != 2 {
+			return pixel.ZV, false
+		}
+		return pixel.V(v[0], v[1]), true
+	case []interface{}:
+		if len(v) != 2 {
+			return pixel.ZV, false
+		}
+		x, okX := toFloat(v[0])
+		y, okY := toFloat(v[1])
+		return pixel.V(x, y), okX && okY
+	case map[string]interface{}:
+		if len(v) != 2 {
+			return pixel.ZV, false
+		}
+		x, okX := toFloat(v["x"])
+		y, okY := toFloat(v["y"])
+		return pixel.V(x, y), okX && okY
+	}
+	return pixel.ZV, false
+}
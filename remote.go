@@ -0,0 +1,242 @@
+package visual
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/remote"
+)
+
+// -------------------------------------------------------------------------
+// Remote control
+
+// remoteTimeout is how long a request waits for the mainthread to get to it.
+const remoteTimeout = 5 * time.Second
+
+// RemoteAddr returns the address the remote control API is served at, such as "127.0.0.1:9465".
+// It's empty unless serving. (See Config.RemoteAddr.)
+//
+// Endpoints, all in JSON but the screenshot:
+//
+//	GET  /camera      {"x", "y", "z", "angle"} where the angle is in degrees
+//	POST /camera      {"move_to": {"x", "y"}, "zoom": levels, "rotate": degrees}, each optional
+//	GET  /actors      [{"index", "type", "hud", "visible", "active", "quarantined", "tags"}] in draw order
+//	GET  /types       [{"name", "go_type", "hud", "params"}] of actor types registered (See ActorSchemas().)
+//	POST /explode     {"x", "y"} in game coords
+//	GET  /pause       {"paused"}
+//	POST /pause       {"paused"}, which toggles it if omitted
+//	GET  /title       {"fullname", "title", "version"}
+//	POST /title       {"title", "version"}
+//	GET  /screenshot  the window in PNG
+func (v *Visualizer) RemoteAddr() string {
+	if v.remoteServer == nil {
+		return ""
+	}
+	return v.remoteServer.Addr()
+}
+
+// Invoke runs a func on the mainthread before events are handled in the next frame, without waiting for it.
+// It's how anything not safe for concurrent use, such as the camera, gets touched from other goroutines.
+// It blocks while too many of them are waiting, so it must not be called from the mainthread in a loop.
+func (v *Visualizer) Invoke(fn func()) {
+	v.tasks.Post(context.Background(), fn)
+}
+
+// StartRemote starts serving the remote control API at Config.RemoteAddr, if any.
+// It should be called on lazy init.
+func (v *Visualizer) _StartRemote() {
+	if v.remoteAddr == "" {
+		return
+	}
+	s, err := remote.Serve(v.remoteAddr, v._RemoteHandler())
+	if err != nil {
+		v.logger.Error("remote control failed to be served", "addr", v.remoteAddr, "err", err)
+		return
+	}
+	v.remoteServer = s
+	v.logger.Info("remote control served", "url", "http://"+s.Addr()+"/")
+}
+
+// StopRemote stops serving the remote control API, if serving.
+// Requests waiting for the mainthread fail as their connections get closed.
+func (v *Visualizer) _StopRemote() {
+	if v.remoteServer == nil {
+		return
+	}
+	if err := v.remoteServer.Close(); err != nil {
+		v.logger.Warn("remote control failed to stop", "err", err)
+	}
+	v.remoteServer = nil
+}
+
+type remoteVec struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type remoteCamera struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Z     float64 `json:"z"`
+	Angle float64 `json:"angle"` // in degrees
+}
+
+type remoteActor struct {
+	Index       int      `json:"index"`
+	Type        string   `json:"type"`
+	HUD         bool     `json:"hud"`
+	Visible     bool     `json:"visible"`
+	Active      bool     `json:"active"`
+	Quarantined bool     `json:"quarantined"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type remoteTitle struct {
+	Fullname string `json:"fullname,omitempty"`
+	Title    string `json:"title"`
+	Version  string `json:"version"`
+}
+
+// RemoteHandler routes the remote control API. Handlers run what they do on the mainthread.
+// Requests a web page is able to forge are refused. (See remote.Guard().)
+func (v *Visualizer) _RemoteHandler() http.Handler {
+	// call runs a func on the mainthread and responds with what it returns in JSON, or an error if it never gets to run.
+	call := func(w http.ResponseWriter, r *http.Request, fn func() interface{}) {
+		ctx, cancel := context.WithTimeout(r.Context(), remoteTimeout)
+		defer cancel()
+		var ret interface{}
+		if err := v.tasks.Call(ctx, func() { ret = fn() }); err != nil {
+			remote.WriteError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		remote.WriteJSON(w, http.StatusOK, ret)
+	}
+	// route handles GET and POST of a path; a nil post is a read-only one.
+	// A post reads and checks the request where it's served, and returns what's to be done on the mainthread.
+	mux := http.NewServeMux()
+	route := func(path string, get func() interface{}, post func(r *http.Request) (func(), error)) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && get != nil:
+				call(w, r, get)
+			case r.Method == http.MethodPost && post != nil:
+				do, err := post(r)
+				if err != nil {
+					remote.WriteError(w, http.StatusBadRequest, err)
+					return
+				}
+				call(w, r, func() interface{} {
+					do()
+					if get == nil {
+						return struct{}{}
+					}
+					return get()
+				})
+			default:
+				remote.WriteError(w, http.StatusMethodNotAllowed, errors.New(r.Method+" not allowed"))
+			}
+		})
+	}
+
+	getCamera := func() interface{} {
+		x, y, z := v.camera.XYZ()
+		return remoteCamera{x, y, z, (v.camera.Angle() / math.Pi) * 180}
+	}
+	route("/camera", getCamera, func(r *http.Request) (func(), error) {
+		var req struct {
+			MoveTo *remoteVec `json:"move_to"`
+			Zoom   *float64   `json:"zoom"`
+			Rotate *float64   `json:"rotate"`
+		}
+		if err := remote.ReadJSON(r, &req); err != nil {
+			return nil, err
+		}
+		return func() {
+			if req.MoveTo != nil {
+				v.camera.MoveTo(pixel.V(req.MoveTo.X, req.MoveTo.Y))
+			}
+			if req.Zoom != nil {
+				v.camera.Zoom(*req.Zoom)
+			}
+			if req.Rotate != nil {
+				v.camera.Rotate(*req.Rotate)
+			}
+		}, nil
+	})
+	route("/actors", func() interface{} {
+		v.mutex.Lock()
+		items := v._InspectedItems()
+		v.mutex.Unlock()
+
+		ret := make([]remoteActor, len(items))
+		for i, item := range items {
+			ret[i] = remoteActor{i, item.Type, item.HUD, item.Visible, item.Active, item.Quarantined, item.Tags}
+		}
+		return ret
+	}, nil)
+	route("/types", func() interface{} {
+		return ActorSchemas()
+	}, nil)
+	route("/explode", nil, func(r *http.Request) (func(), error) {
+		var req remoteVec
+		if err := remote.ReadJSON(r, &req); err != nil {
+			return nil, err
+		}
+		return func() {
+			v.explosions.ExplodeAt(pixel.V(req.X, req.Y), pixel.V(10, 10))
+		}, nil
+	})
+	getPause := func() interface{} {
+		return map[string]bool{"paused": v.IsPaused()}
+	}
+	route("/pause", getPause, func(r *http.Request) (func(), error) {
+		var req struct {
+			Paused *bool `json:"paused"`
+		}
+		if err := remote.ReadJSON(r, &req); err != nil {
+			return nil, err
+		}
+		return func() {
+			paused := !v.IsPaused()
+			if req.Paused != nil {
+				paused = *req.Paused
+			}
+			v.SetPaused(paused)
+		}, nil
+	})
+	getTitle := func() interface{} {
+		fullname, title, version := v.Title()
+		return remoteTitle{fullname, title, version}
+	}
+	route("/title", getTitle, func(r *http.Request) (func(), error) {
+		var req remoteTitle
+		if err := remote.ReadJSON(r, &req); err != nil {
+			return nil, err
+		}
+		return func() {
+			v.SetTitle(req.Title, req.Version)
+		}, nil
+	})
+	mux.HandleFunc("/screenshot", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			remote.WriteError(w, http.StatusMethodNotAllowed, errors.New(r.Method+" not allowed"))
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), remoteTimeout)
+		defer cancel()
+		var img *image.RGBA
+		if err := v.tasks.Call(ctx, func() { img = v._Capture() }); err != nil {
+			remote.WriteError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		png.Encode(w, img) // off the mainthread
+	})
+	return remote.Guard(mux)
+}
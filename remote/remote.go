@@ -0,0 +1,234 @@
+// Package remote serves a JSON API over HTTP on localhost only,
+// and runs what's requested on the mainthread through a Queue.
+//
+//	q := remote.NewQueue(64)
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/title", func(w http.ResponseWriter, r *http.Request) {
+//		var title string
+//		err := q.Call(r.Context(), func() { title = win.Title() })
+//		...
+//		remote.WriteJSON(w, http.StatusOK, title)
+//	})
+//	srv, err := remote.Serve("127.0.0.1:9465", mux)
+//	...
+//	for !win.Closed() { // on the mainthread
+//		q.Run()
+//		...
+//	}
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// -------------------------------------------------------------------------
+// Queue
+
+// ErrClosed is returned by a Queue that's closed.
+var ErrClosed = errors.New("remote: queue closed")
+
+// Queue hands funcs over from other goroutines to the one that runs them, the mainthread.
+type Queue struct {
+	tasks  chan func()
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewQueue is a constructor. Up to size funcs wait to be run before Call() and Post() block.
+func NewQueue(size int) *Queue {
+	return &Queue{
+		tasks:  make(chan func(), size),
+		closed: make(chan struct{}),
+	}
+}
+
+// Post a func to be run without waiting for it. It blocks while the queue is full.
+// It must not be called from the goroutine that runs the queue, unless it's sure not to be full.
+func (q *Queue) Post(ctx context.Context, fn func()) error {
+	select {
+	case q.tasks <- fn:
+		return nil
+	case <-q.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Call a func and wait for it to be run. A panic in it is returned as an error.
+// It must not be called from the goroutine that runs the queue.
+func (q *Queue) Call(ctx context.Context, fn func()) error {
+	done := make(chan error, 1)
+	err := q.Post(ctx, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("remote: panic: %v", r)
+			}
+		}()
+		fn()
+		done <- nil
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-q.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err() // It may still run later on.
+	}
+}
+
+// Run funcs waiting in the queue, and returns how many of them are run.
+// Funcs posted while running wait for the next call, so that it returns in time.
+func (q *Queue) Run() (n int) {
+	for max := len(q.tasks); n < max; n++ {
+		select {
+		case fn := <-q.tasks:
+			fn()
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// Close the queue. Funcs waiting in it are not run, and their calls fail.
+func (q *Queue) Close() {
+	q.once.Do(func() { close(q.closed) })
+}
+
+// -------------------------------------------------------------------------
+// JSON
+
+// WriteJSON responds with a value in JSON.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError responds with an error in JSON such as {"error": "..."}.
+func WriteError(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// ReadJSON reads a request body in JSON into a value. Unknown fields are refused, but an empty body is not.
+func ReadJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// Guard wraps a handler to refuse requests a web page open in a browser is able to forge:
+// those to a host other than localhost, as by DNS rebinding, those from pages of other origins,
+// and requests with bodies in anything but JSON, which browsers send to other origins without asking first.
+func Guard(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsSameOrigin(r) {
+			WriteError(w, http.StatusForbidden, errors.New("cross-origin requests not allowed"))
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				WriteError(w, http.StatusUnsupportedMediaType, errors.New("application/json required"))
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// IsSameOrigin determines whether a request is to localhost from a page served by itself, if from a page at all.
+func IsSameOrigin(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if !isLoopback(host) {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" { // not from a browser
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// -------------------------------------------------------------------------
+// Server
+
+// LocalAddr checks an address to listen on, and returns it with the host filled in.
+// A port alone such as ":9465" is on 127.0.0.1. Hosts other than loopback ones are refused.
+// Servers of the other packages, such as metrics and drawproto, check their addresses with it too.
+func LocalAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("remote: %v", err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if !isLoopback(host) {
+		return "", fmt.Errorf("remote: %q is not a loopback address", host)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// ReadTimeout is how long a request may take to be read; a client stalling longer gets dropped.
+const ReadTimeout = 10 * time.Second
+
+// Server serves a handler on localhost.
+type Server struct {
+	srv *http.Server
+	ln  net.Listener
+}
+
+// Serve starts listening on a local address and serving a handler in the background.
+func Serve(addr string, handler http.Handler) (*Server, error) {
+	addr, err := LocalAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{srv: &http.Server{Handler: handler, ReadTimeout: ReadTimeout}, ln: ln}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// Addr returns the address it's listening on.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close stops serving.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
@@ -0,0 +1,124 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewQueue(4)
+	stop := make(chan struct{})
+	go func() {
+		for { // the mainthread
+			select {
+			case <-time.After(time.Millisecond):
+				q.Run()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var got int
+	if err := q.Call(context.Background(), func() { got = 42 }); err != nil || got != 42 {
+		t.Errorf("called and got %d, %v; want 42", got, err)
+	}
+	if err := q.Call(context.Background(), func() { panic("oops") }); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("a panic called back as %v", err)
+	}
+	close(stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	time.Sleep(5 * time.Millisecond) // until the mainthread stops
+	if err := q.Call(ctx, func() {}); err != context.DeadlineExceeded {
+		t.Errorf("a call not run returned %v; want the deadline exceeded", err)
+	}
+	q.Close()
+	if err := q.Call(context.Background(), func() {}); err != ErrClosed {
+		t.Errorf("a call after closed returned %v; want ErrClosed", err)
+	}
+}
+
+func TestLocalAddr(t *testing.T) {
+	for addr, want := range map[string]string{
+		":9465":          "127.0.0.1:9465",
+		"localhost:0":    "localhost:0",
+		"[::1]:9465":     "[::1]:9465",
+		"0.0.0.0:9465":   "",
+		"example.com:80": "",
+		"9465":           "",
+	} {
+		got, err := LocalAddr(addr)
+		if got != want || (err == nil) != (want != "") {
+			t.Errorf("LocalAddr(%q) = %q, %v; want %q", addr, got, err, want)
+		}
+	}
+}
+
+func TestServeJSON(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ X float64 }
+		if err := ReadJSON(r, &req); err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, req)
+	})
+	s, err := Serve("127.0.0.1:0", mux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for body, want := range map[string]int{
+		`{"X": 1}`: http.StatusOK,
+		``:         http.StatusOK,
+		`{"Y": 1}`: http.StatusBadRequest,
+	} {
+		resp, err := http.Post("http://"+s.Addr()+"/echo", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("posted %q and got %d; want %d", body, resp.StatusCode, want)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("content type %q", ct)
+		}
+	}
+}
+
+func TestGuard(t *testing.T) {
+	h := Guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for _, c := range []struct {
+		method, host, origin, contentType string
+		want                              int
+	}{
+		{"POST", "127.0.0.1:9465", "", "application/json", http.StatusNoContent},
+		{"POST", "localhost:9465", "http://localhost:9465", "application/json; charset=utf-8", http.StatusNoContent},
+		{"GET", "[::1]:9465", "", "", http.StatusNoContent},
+		{"POST", "127.0.0.1:9465", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"POST", "127.0.0.1:9465", "http://evil.example", "application/json", http.StatusForbidden},
+		{"GET", "evil.example:9465", "", "", http.StatusForbidden}, // rebound
+	} {
+		req := httptest.NewRequest(c.method, "http://"+c.host+"/", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", c.contentType)
+		if c.origin != "" {
+			req.Header.Set("Origin", c.origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("%+v handled %d", c, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Package replay reads and writes frames of a session, that is dt and user inputs of each frame,
+// so that the session can be replayed later on.
+//
+// The format is compact; a frame takes 9 bytes when nothing but time goes on.
+//
+//	header: "VISREPLAY" | version (1 byte) | seed (8 bytes)
+//	frame:  flags (1 byte) | dt (8 bytes) | [mouse (16 bytes)] | [scroll (16 bytes)] | [toggled buttons (uvarints)] | [typed (uvarint length | UTF-8)]
+//
+// All numbers are in little endian. Replays of version 1, which have no text typed, are still read.
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/faiface/pixel"
+)
+
+const (
+	magic   = "VISREPLAY"
+	version = 2
+)
+
+const (
+	flagMouse   = 1 << iota // The mouse moved.
+	flagScroll              // Scrolled.
+	flagButtons             // Some buttons got pressed or released.
+	flagTyped               // Some text got typed.
+)
+
+// maxTyped is the most bytes of text a frame can have typed, not to allocate whatever a broken replay says.
+const maxTyped = 1 << 16
+
+// ErrFormat is returned when what's read is not a replay.
+var ErrFormat = errors.New("replay: invalid format")
+
+// Frame is what a single frame takes in.
+type Frame struct {
+	Dt      float64   // Delta time in seconds.
+	Mouse   pixel.Vec // Mouse position in screen coords.
+	Scroll  pixel.Vec // Mouse scroll.
+	Pressed []int     // Buttons being pressed, in ascending order.
+	Typed   string    // Text typed.
+}
+
+// -------------------------------------------------------------------------
+// Writer
+
+// Writer writes frames.
+type Writer struct {
+	w       *bufio.Writer
+	mouse   pixel.Vec
+	pressed map[int]bool
+	buf     []byte
+}
+
+// NewWriter writes a header with the seed of randomness the session runs with, and returns a Writer.
+func NewWriter(w io.Writer, seed int64) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	header := make([]byte, len(magic)+1+8)
+	copy(header, magic)
+	header[len(magic)] = version
+	binary.LittleEndian.PutUint64(header[len(magic)+1:], uint64(seed))
+	if _, err := bw.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw, pressed: map[int]bool{}}, nil
+}
+
+// WriteFrame writes a frame. Only what's changed from the last frame is written.
+func (rw *Writer) WriteFrame(f Frame) error {
+	// buttons toggled
+	var toggled []int
+	pressed := make(map[int]bool, len(f.Pressed))
+	for _, button := range f.Pressed {
+		pressed[button] = true
+		if !rw.pressed[button] {
+			toggled = append(toggled, button)
+		}
+	}
+	for button := range rw.pressed {
+		if !pressed[button] {
+			toggled = append(toggled, button)
+		}
+	}
+	sort.Ints(toggled)
+
+	flags := byte(0)
+	if f.Mouse != rw.mouse {
+		flags |= flagMouse
+	}
+	if f.Scroll != pixel.ZV {
+		flags |= flagScroll
+	}
+	if len(toggled) > 0 {
+		flags |= flagButtons
+	}
+	if f.Typed != "" {
+		flags |= flagTyped
+	}
+
+	buf := append(rw.buf[:0], flags)
+	buf = appendFloat64(buf, f.Dt)
+	if flags&flagMouse != 0 {
+		buf = appendFloat64(buf, f.Mouse.X)
+		buf = appendFloat64(buf, f.Mouse.Y)
+	}
+	if flags&flagScroll != 0 {
+		buf = appendFloat64(buf, f.Scroll.X)
+		buf = appendFloat64(buf, f.Scroll.Y)
+	}
+	if flags&flagButtons != 0 {
+		buf = appendUvarint(buf, uint64(len(toggled)))
+		for _, button := range toggled {
+			buf = appendUvarint(buf, uint64(button))
+		}
+	}
+	if flags&flagTyped != 0 {
+		buf = appendUvarint(buf, uint64(len(f.Typed)))
+		buf = append(buf, f.Typed...)
+	}
+	rw.buf = buf
+
+	if _, err := rw.w.Write(buf); err != nil {
+		return err
+	}
+	rw.mouse = f.Mouse
+	rw.pressed = pressed
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (rw *Writer) Flush() error {
+	return rw.w.Flush()
+}
+
+func appendFloat64(buf []byte, f float64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	return append(buf, b[:]...)
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+// -------------------------------------------------------------------------
+// Reader
+
+// Reader reads frames.
+type Reader struct {
+	r       *bufio.Reader
+	seed    int64
+	mouse   pixel.Vec
+	pressed map[int]bool
+}
+
+// NewReader reads a header and returns a Reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+1+8)
+	if _, err := io.ReadFull(br, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrFormat
+		}
+		return nil, err
+	}
+	if string(header[:len(magic)]) != magic || header[len(magic)] < 1 || header[len(magic)] > version {
+		return nil, ErrFormat
+	}
+	return &Reader{
+		r:       br,
+		seed:    int64(binary.LittleEndian.Uint64(header[len(magic)+1:])),
+		pressed: map[int]bool{},
+	}, nil
+}
+
+// Seed returns the seed of randomness the session ran with.
+func (rr *Reader) Seed() int64 {
+	return rr.seed
+}
+
+// ReadFrame reads the next frame. It returns io.EOF when there are no more frames.
+func (rr *Reader) ReadFrame() (Frame, error) {
+	flags, err := rr.r.ReadByte()
+	if err != nil {
+		return Frame{}, err // io.EOF at the end
+	}
+	f := Frame{Mouse: rr.mouse}
+	if f.Dt, err = rr.readFloat64(); err != nil {
+		return Frame{}, err
+	}
+	if flags&flagMouse != 0 {
+		if f.Mouse.X, err = rr.readFloat64(); err != nil {
+			return Frame{}, err
+		}
+		if f.Mouse.Y, err = rr.readFloat64(); err != nil {
+			return Frame{}, err
+		}
+	}
+	if flags&flagScroll != 0 {
+		if f.Scroll.X, err = rr.readFloat64(); err != nil {
+			return Frame{}, err
+		}
+		if f.Scroll.Y, err = rr.readFloat64(); err != nil {
+			return Frame{}, err
+		}
+	}
+	if flags&flagButtons != 0 {
+		n, err := rr.readUvarint()
+		if err != nil {
+			return Frame{}, err
+		}
+		for i := uint64(0); i < n; i++ {
+			button, err := rr.readUvarint()
+			if err != nil {
+				return Frame{}, err
+			}
+			rr.pressed[int(button)] = !rr.pressed[int(button)]
+		}
+	}
+	if flags&flagTyped != 0 {
+		n, err := rr.readUvarint()
+		if err != nil {
+			return Frame{}, err
+		}
+		if n > maxTyped {
+			return Frame{}, ErrFormat
+		}
+		typed := make([]byte, n)
+		if _, err := io.ReadFull(rr.r, typed); err != nil {
+			if err == io.EOF {
+				return Frame{}, io.ErrUnexpectedEOF
+			}
+			return Frame{}, err
+		}
+		f.Typed = string(typed)
+	}
+	for button, pressed := range rr.pressed {
+		if pressed {
+			f.Pressed = append(f.Pressed, button)
+		}
+	}
+	sort.Ints(f.Pressed)
+	rr.mouse = f.Mouse
+	return f, nil
+}
+
+func (rr *Reader) readFloat64() (float64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(rr.r, b[:]); err != nil {
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+}
+
+func (rr *Reader) readUvarint() (uint64, error) {
+	x, err := binary.ReadUvarint(rr.r)
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return x, err
+}
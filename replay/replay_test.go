@@ -0,0 +1,73 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestReplay(t *testing.T) {
+	frames := []Frame{
+		{Dt: 1.0 / 60},
+		{Dt: 1.0 / 61, Mouse: pixel.V(10, 20), Pressed: []int{0}},
+		{Dt: 1.0 / 59, Mouse: pixel.V(10, 20), Pressed: []int{0, 262}},
+		{Dt: 0.5, Mouse: pixel.V(-3, 4.5), Scroll: pixel.V(0, -1), Pressed: []int{262}},
+		{Dt: 1.0 / 60, Mouse: pixel.V(-3, 4.5), Typed: "hé"},
+		{Dt: 1.0 / 60, Mouse: pixel.V(-3, 4.5)},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, -42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Seed() != -42 {
+		t.Errorf("seed %d; want -42", r.Seed())
+	}
+	for i, want := range frames {
+		got, err := r.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("frame %d is %+v; want %+v", i, got, want)
+		}
+	}
+	if _, err := r.ReadFrame(); err != io.EOF {
+		t.Errorf("%v after the last frame; want io.EOF", err)
+	}
+}
+
+func TestReaderFormat(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("not a replay at all"))); err != ErrFormat {
+		t.Errorf("%v; want ErrFormat", err)
+	}
+	if _, err := NewReader(bytes.NewReader(nil)); err != ErrFormat {
+		t.Errorf("%v on empty input; want ErrFormat", err)
+	}
+	v1 := append([]byte(magic), 1, 0, 0, 0, 0, 0, 0, 0, 0)
+	v1 = append(v1, 0, 0, 0, 0, 0, 0, 0, 0xe0, 0x3f) // a frame of dt 0.5
+	r, err := NewReader(bytes.NewReader(v1))
+	if err != nil {
+		t.Fatalf("%v on version 1", err)
+	}
+	if f, err := r.ReadFrame(); err != nil || f.Dt != 0.5 {
+		t.Errorf("frame %+v, %v of version 1; want dt 0.5", f, err)
+	}
+}
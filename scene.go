@@ -0,0 +1,266 @@
+package visual
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/super"
+	"gopkg.in/yaml.v2"
+)
+
+// -------------------------------------------------------------------------
+// Scene files
+
+// Scene is what a scene file describes;
+// a Config, where the camera starts, and actors and HUDs in layers. (See ReadScene().)
+type Scene struct {
+	Config Config
+	Camera *super.CameraState // where the camera starts, if given
+	Layers []SceneLayer
+}
+
+// SceneLayer is a group of actors pushed in order, all tagged with the name of the layer.
+// Actors of a layer in the screen space are HUDs.
+type SceneLayer struct {
+	Name    string
+	Screen  bool
+	Visible bool
+	Actors  []SceneActor
+}
+
+// SceneActor is an actor in a layer, along with its own tags.
+type SceneActor struct {
+	Actor Actor
+	Tags  []string
+}
+
+// sceneFile is what's in a scene file; what's in a config file, the camera and layers.
+type sceneFile struct {
+	configFile `yaml:",inline"`
+	Camera     *sceneCamera `json:"camera" yaml:"camera"`
+	Layers     []sceneLayer `json:"layers" yaml:"layers"`
+}
+
+type sceneCamera struct {
+	X     *float64 `json:"x" yaml:"x"` // the center of the world by default
+	Y     *float64 `json:"y" yaml:"y"`
+	Zoom  *float64 `json:"zoom" yaml:"zoom"`   // 1 by default
+	Angle float64  `json:"angle" yaml:"angle"` // in degrees
+}
+
+type sceneLayer struct {
+	Name    string       `json:"name" yaml:"name"`
+	Space   string       `json:"space" yaml:"space"`     // "world" by default, or "screen"
+	Visible *bool        `json:"visible" yaml:"visible"` // true by default
+	Actors  []sceneActor `json:"actors" yaml:"actors"`
+}
+
+type sceneActor struct {
+	Type  string                 `json:"type" yaml:"type"` // registered by RegisterActorType()
+	Props map[string]interface{} `json:"props" yaml:"props"`
+	Tags  []string               `json:"tags" yaml:"tags"`
+}
+
+// ReadScene reads a scene file in JSON or YAML, told by its extension; .json, .yaml or .yml.
+// It has what a config file has, along with the camera and layers of actors of types registered by name.
+// (See LoadConfig() and RegisterActorType().)
+//
+//	title: Harbor
+//	bg: "#0b1a2a"
+//	win_width: 1280
+//	win_height: 720
+//	width: 4000
+//	height: 3000
+//	camera: {x: 2000, y: 1500, zoom: 0.5, angle: 0}
+//	layers:
+//	  - name: ground
+//	    actors:
+//	      - type: shape
+//	        props: {shape: rect, points: [[0, 0], [4000, 3000]], color: "#203040"}
+//	  - name: legend
+//	    space: screen
+//	    visible: false
+//	    actors:
+//	      - type: label
+//	        props: {text: Harbor, pos: [10, 10], scale: 2}
+//	        tags: [title]
+//
+// Built-in types are "shape", "label", "sprite", "explosions" and "fpswatch". (See ActorSchemas() for their props.)
+// Environment variables override what's in the file just as they do a config file.
+func ReadScene(path string) (*Scene, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file sceneFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&file)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &file)
+	default:
+		return nil, fmt.Errorf("visual: scene file of an unknown extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("visual: %s: %v", path, err)
+	}
+	if err := file.overrideWithEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	scene, err := file.toScene()
+	if err != nil {
+		return nil, fmt.Errorf("visual: %s: %v", path, err)
+	}
+	return scene, nil
+}
+
+func (file sceneFile) toScene() (*Scene, error) {
+	cfg, err := file.toConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	scene := &Scene{Config: cfg}
+
+	if c := file.Camera; c != nil {
+		pos := pixel.V(cfg.Width/2, cfg.Height/2)
+		if c.X != nil {
+			pos.X = *c.X
+		}
+		if c.Y != nil {
+			pos.Y = *c.Y
+		}
+		zoom := 1.0
+		if c.Zoom != nil {
+			zoom = *c.Zoom
+		}
+		if !(zoom > 0) || math.IsInf(zoom, 0) {
+			return nil, fmt.Errorf("camera: zoom must be a positive number, not %v", zoom)
+		}
+		angle := c.Angle * math.Pi / 180
+		scene.Camera = &super.CameraState{
+			Angle: angle, AngleFollow: angle,
+			Zoom: zoom, ZoomFollow: zoom,
+			Pos: pos, PosFollow: pos,
+		}
+	}
+
+	for i, l := range file.Layers {
+		layer := SceneLayer{Name: l.Name, Visible: l.Visible == nil || *l.Visible}
+		switch l.Space {
+		case "", "world":
+		case "screen":
+			layer.Screen = true
+		default:
+			return nil, fmt.Errorf("layers[%d]: unknown space %q", i, l.Space)
+		}
+		for j, a := range l.Actors {
+			props, _ := normalizeYAML(a.Props).(map[string]interface{})
+			actor, err := NewActor(a.Type, props)
+			if err != nil {
+				return nil, fmt.Errorf("layers[%d].actors[%d]: %v", i, j, err)
+			}
+			if _, ok := actor.(HUD); layer.Screen && !ok {
+				return nil, fmt.Errorf("layers[%d].actors[%d]: %s is not a HUD", i, j, a.Type)
+			}
+			layer.Actors = append(layer.Actors, SceneActor{actor, a.Tags})
+		}
+		scene.Layers = append(scene.Layers, layer)
+	}
+	return scene, nil
+}
+
+// normalizeYAML converts maps YAML decodes into those JSON does, all the way down.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(elem)
+		}
+		return m
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = normalizeYAML(elem)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeYAML(v[i])
+		}
+		return v
+	}
+	return value
+}
+
+// PushScene pushes actors and HUDs of a scene into this visualizer, after those it already has,
+// tagged with the names of their layers and their own tags, and hidden if their layers are.
+// The camera gets where the scene says on the mainthread, in the next frame or as soon as it runs.
+// The config of the scene is not applied; it's for NewVisualizer(). (See LoadScene().)
+func (v *Visualizer) PushScene(scene *Scene) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	// All at once, so that no frame ever has them shown before their layers are hidden.
+	for _, layer := range scene.Layers {
+		for _, a := range layer.Actors {
+			flagged := layer.Name != "" || len(a.Tags) > 0 || !layer.Visible
+			if m := v._Meta(a.Actor, flagged); m != nil {
+				if m.tags == nil {
+					m.tags = map[string]bool{}
+				}
+				if layer.Name != "" {
+					m.tags[layer.Name] = true
+				}
+				for _, tag := range a.Tags {
+					m.tags[tag] = true
+				}
+				if !layer.Visible {
+					m.hidden = true
+				}
+			}
+			if layer.Screen {
+				hud := a.Actor.(HUD)
+				v.huds = append(v.huds, hud)
+				v.hudsToPlace = append(v.hudsToPlace, hud)
+			} else {
+				v.actors = append(v.actors, a.Actor)
+			}
+		}
+	}
+
+	if scene.Camera == nil {
+		return
+	}
+	if v.loadedState == nil {
+		v.loadedState = &savedState{}
+	}
+	cam := *scene.Camera
+	v.loadedState.Camera = &cam
+}
+
+// LoadScene reads a scene file and creates a visualizer of it. (See ReadScene().)
+func LoadScene(path string) (*Visualizer, error) {
+	scene, err := ReadScene(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewVisualizer(scene.Config, nil)
+	if err != nil {
+		return nil, err
+	}
+	v.PushScene(scene)
+	return v, nil
+}
@@ -0,0 +1,127 @@
+package visual
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/nanitefactory/visual/actors"
+)
+
+func TestReadScene(t *testing.T) {
+	dir, err := ioutil.TempDir("", "visual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"scene.yaml": `
+title: harbor
+width: 4000
+camera: {y: 1500, zoom: 0.5, angle: 90}
+layers:
+  - name: ground
+    actors:
+      - type: shape
+        props: {shape: rect, points: [[0, 0], {x: 4000, y: 3000}], color: "#203040"}
+  - name: legend
+    space: screen
+    visible: false
+    actors:
+      - type: label
+        props: {text: harbor, pos: [10, 10], scale: 2}
+        tags: [title]
+`,
+		"scene.json": `{
+	"title": "harbor", "width": 4000,
+	"camera": {"y": 1500, "zoom": 0.5, "angle": 90},
+	"layers": [
+		{"name": "ground", "actors": [{"type": "shape", "props": {"shape": "rect", "points": [[0, 0], {"x": 4000, "y": 3000}], "color": "#203040"}}]},
+		{"name": "legend", "space": "screen", "visible": false, "actors": [{"type": "label", "props": {"text": "harbor", "pos": [10, 10], "scale": 2}, "tags": ["title"]}]}
+	]
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		scene, err := ReadScene(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if scene.Config.Title != "harbor" || scene.Config.Width != 4000 || scene.Config.Height != DefaultWinHeight {
+			t.Errorf("%s: config %q %v %v", name, scene.Config.Title, scene.Config.Width, scene.Config.Height)
+		}
+		if cam := scene.Camera; cam == nil || cam.Pos != pixel.V(2000, 1500) || cam.Zoom != 0.5 || math.Abs(cam.Angle-math.Pi/2) > 1e-9 {
+			t.Errorf("%s: camera %+v", name, cam)
+		}
+		if len(scene.Layers) != 2 || len(scene.Layers[0].Actors) != 1 || len(scene.Layers[1].Actors) != 1 {
+			t.Fatalf("%s: layers %+v", name, scene.Layers)
+		}
+		ground, legend := scene.Layers[0], scene.Layers[1]
+		if ground.Name != "ground" || ground.Screen || !ground.Visible {
+			t.Errorf("%s: ground %+v", name, ground)
+		}
+		if shape, ok := ground.Actors[0].Actor.(*actors.Shape); !ok || shape.Bounds() != pixel.R(0, 0, 4000, 3000) {
+			t.Errorf("%s: ground of %T", name, ground.Actors[0].Actor)
+		}
+		if legend.Name != "legend" || !legend.Screen || legend.Visible || fmt.Sprint(legend.Actors[0].Tags) != "[title]" {
+			t.Errorf("%s: legend %+v", name, legend)
+		}
+		if _, ok := legend.Actors[0].Actor.(*actors.Label); !ok {
+			t.Errorf("%s: legend of %T", name, legend.Actors[0].Actor)
+		}
+	}
+
+	// invalid
+	for name, content := range map[string]string{
+		"unknown.yaml": "layers: [{actors: [{type: nothing}]}]\n",
+		"props.yaml":   "layers: [{actors: [{type: shape, props: {colour: red}}]}]\n",
+		"points.json":  `{"layers": [{"actors": [{"type": "shape", "props": {"points": [[0]]}}]}]}`,
+		"space.yaml":   "layers: [{space: sky}]\n",
+		"zoom.yaml":    "camera: {zoom: 0}\n",
+		"config.yaml":  "win_width: -1\n",
+		"typo.json":    `{"layer": []}`,
+		"scene.toml":   "title = \"toml\"\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadScene(path); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestPushScene(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ground, legend, plain := &counter{}, &hud{}, &counter{}
+	v.PushScene(&Scene{Layers: []SceneLayer{
+		{Name: "ground", Visible: true, Actors: []SceneActor{{Actor: ground}, {Actor: plain}}},
+		{Name: "legend", Screen: true, Actors: []SceneActor{{Actor: legend, Tags: []string{"title"}}}},
+		{Visible: true, Actors: []SceneActor{{Actor: plain}}},
+	}})
+	if len(v.actors) != 3 || len(v.huds) != 1 || len(v.hudsToPlace) != 1 {
+		t.Fatalf("%d actors, %d HUDs and %d to place; want 3, 1 and 1", len(v.actors), len(v.huds), len(v.hudsToPlace))
+	}
+	if !v.IsVisible(ground) || v.IsVisible(legend) {
+		t.Error("actors hidden are not the ones of the layer hidden")
+	}
+	if tags := fmt.Sprint(v.Tags(legend)); tags != "[legend title]" {
+		t.Errorf("tags %s; want [legend title]", tags)
+	}
+	if got := v.ActorsByTag("ground"); len(got) != 3 || got[0] != ground || got[1] != plain {
+		t.Errorf("actors by tag %v; want [ground plain plain]", got)
+	}
+}
@@ -0,0 +1,193 @@
+package visual
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// State
+
+// Snapshotter is an Actor that's able to save and restore its own state.
+// Actors that are not Snapshotters get saved by their type names only,
+// and restored as what their factories create out of nil props.
+type Snapshotter interface {
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
+// stateVersion is the version of the format SaveState() writes.
+const stateVersion = 1
+
+type savedState struct {
+	Version int                `json:"version"`
+	Camera  *super.CameraState `json:"camera,omitempty"`
+	Clock   *float64           `json:"clock,omitempty"` // Seconds since the game clock started.
+	Actors  []savedActor       `json:"actors"`
+	HUDs    []savedActor       `json:"huds"`
+}
+
+type savedActor struct {
+	Type string `json:"type"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// SaveState writes the state of this visualizer in JSON:
+// the camera, the game clock, and all actors and HUDs in order by their registered type names.
+// Actors that implement Snapshotter get their own states saved along with them.
+// The camera and the game clock are those of the last frame, or omitted if it's not running yet.
+// It fails if any actor is of a type not registered with RegisterActorType().
+func (v *Visualizer) SaveState(w io.Writer) error {
+	state := savedState{Version: stateVersion}
+	err := func() (err error) {
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+
+		if v.shownCamera != nil {
+			cam := *v.shownCamera
+			state.Camera = &cam
+		}
+		if !v.shownClock.IsZero() {
+			clock := time.Since(v.shownClock).Seconds()
+			state.Clock = &clock
+		}
+		if state.Actors, err = saveActors(v.actors); err != nil {
+			return err
+		}
+		huds := make([]Actor, len(v.huds))
+		for i := range v.huds {
+			huds[i] = v.huds[i]
+		}
+		state.HUDs, err = saveActors(huds)
+		return err
+	}()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(state)
+}
+
+// LoadState reads a state written by SaveState() and replaces that of this visualizer with it.
+// Nothing changes if it fails.
+// Actors and HUDs there were go away along with their flags, tags and quarantine, and so do those drawn by clients.
+// Panics not reported yet to Config.OnActorPanic still get reported.
+// The camera and the game clock get restored on the mainthread, in the next frame or as soon as it runs.
+func (v *Visualizer) LoadState(r io.Reader) error {
+	var state savedState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("visual: state version %d is not supported", state.Version)
+	}
+	actors, err := loadActors(state.Actors)
+	if err != nil {
+		return err
+	}
+	hudActors, err := loadActors(state.HUDs)
+	if err != nil {
+		return err
+	}
+	huds := make([]HUD, len(hudActors))
+	for i, actor := range hudActors {
+		hud, ok := actor.(HUD)
+		if !ok {
+			return fmt.Errorf("visual: %s is not a HUD", state.HUDs[i].Type)
+		}
+		huds[i] = hud
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.actors = actors
+	v.huds = huds
+	v.hudsToPlace = append([]HUD{}, huds...)
+	v.loadedState = &state
+	v.drawn = nil
+	v.meta = nil
+	v.quarantine = nil
+	v.quarantined = nil
+	return nil
+}
+
+// RestoreLoadedState restores the camera and the game clock loaded, and positions HUDs pushed along with them.
+// It's called on the mainthread on lazy init and every frame.
+func (v *Visualizer) _RestoreLoadedState() {
+	v.mutex.Lock()
+	state, huds := v.loadedState, v.hudsToPlace
+	v.loadedState, v.hudsToPlace = nil, nil
+	v.mutex.Unlock()
+
+	for i := range huds {
+		huds[i].PosOnScreen(v.window.Bounds().W(), v.window.Bounds().H())
+	}
+	if state == nil {
+		return
+	}
+	if state.Camera != nil {
+		v.camera.SetState(*state.Camera)
+	}
+	if state.Clock != nil {
+		v.dtw.SetTimeStarted(time.Now().Add(-time.Duration(*state.Clock * float64(time.Second))))
+	}
+}
+
+// ShowState keeps the camera and the game clock of the frame for SaveState() to read from other goroutines.
+// It's called on the mainthread every frame.
+func (v *Visualizer) _ShowState() {
+	cam := v.camera.State()
+	var clock time.Time
+	if v.dtw.IsStarted() {
+		clock = v.dtw.GetTimeStarted()
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.shownCamera = &cam
+	v.shownClock = clock
+}
+
+func saveActors(actors []Actor) ([]savedActor, error) {
+	ret := make([]savedActor, len(actors))
+	for i, actor := range actors {
+		name, ok := ActorTypeName(actor)
+		if !ok {
+			return nil, fmt.Errorf("visual: actor type %T is not registered", actor)
+		}
+		ret[i].Type = name
+		if s, ok := actor.(Snapshotter); ok {
+			data, err := s.Snapshot()
+			if err != nil {
+				return nil, fmt.Errorf("visual: %s: %v", name, err)
+			}
+			ret[i].Data = data
+		}
+	}
+	return ret, nil
+}
+
+func loadActors(saved []savedActor) ([]Actor, error) {
+	ret := make([]Actor, len(saved))
+	for i := range saved {
+		actor, err := NewActor(saved[i].Type, nil)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := actor.(Snapshotter); ok && saved[i].Data != nil {
+			if err := s.Restore(saved[i].Data); err != nil {
+				return nil, fmt.Errorf("visual: %s: %v", saved[i].Type, err)
+			}
+		}
+		ret[i] = actor
+	}
+	return ret, nil
+}
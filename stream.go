@@ -0,0 +1,174 @@
+package visual
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/nanitefactory/visual/remote"
+	"github.com/nanitefactory/visual/stream"
+)
+
+// -------------------------------------------------------------------------
+// Streaming
+
+// streamQuality is the JPEG quality of frames streamed.
+const streamQuality = 75
+
+// StreamAddr returns the address frames are streamed at, such as "127.0.0.1:9467".
+// It's empty unless streaming. (See Config.StreamAddr.)
+//
+// A browser opening it gets a viewer page showing /stream.mjpg,
+// and mouse and keyboard events on the page are forwarded back into the input of the visualizer.
+// Frames are captured only while someone's watching, up to Config.StreamFPS a second.
+func (v *Visualizer) StreamAddr() string {
+	if v.streamServer == nil {
+		return ""
+	}
+	return v.streamServer.Addr()
+}
+
+// StartStream starts streaming at Config.StreamAddr, if any.
+// It should be called on lazy init.
+func (v *Visualizer) _StartStream() {
+	if v.streamAddr == "" {
+		return
+	}
+	if v.viewerInput == nil { // lazy init
+		v.viewerInput = &mergedInput{extra: NewVirtualInput()}
+	}
+	b := stream.NewBroadcaster(v.streamFPS, streamQuality)
+	_, title, _ := v.Title()
+	mux := http.NewServeMux()
+	mux.Handle("/", stream.Page(title))
+	mux.Handle("/stream.mjpg", b)
+	mux.Handle("/input", stream.InputHandler(v._OnViewerEvents))
+	s, err := remote.Serve(v.streamAddr, mux)
+	if err != nil {
+		b.Close()
+		v.logger.Error("stream failed to be served", "addr", v.streamAddr, "err", err)
+		return
+	}
+	v.streamer = b
+	v.streamServer = s
+	v.logger.Info("stream served", "url", "http://"+s.Addr()+"/")
+}
+
+// StopStream disconnects all viewers, if streaming.
+func (v *Visualizer) _StopStream() {
+	if v.streamServer == nil {
+		return
+	}
+	v.streamer.Close()
+	if err := v.streamServer.Close(); err != nil {
+		v.logger.Warn("stream failed to stop", "err", err)
+	}
+	v.streamer = nil
+	v.streamServer = nil
+}
+
+// StreamFrame publishes what's been drawn on the window, if it's due.
+func (v *Visualizer) _StreamFrame() {
+	if v.streamer != nil && v.streamer.Due(time.Now()) {
+		v.streamer.Publish(v._Capture())
+	}
+}
+
+// Input returns where user inputs are read from, along with what viewers of the stream input if streaming.
+func (v *Visualizer) _Input() Input {
+	if v.viewerInput == nil {
+		return v.input
+	}
+	v.viewerInput.Input = v.input // It may have been replaced since.
+	return v.viewerInput
+}
+
+// OnViewerEvents forwards events from viewers of the stream, on the mainthread.
+func (v *Visualizer) _OnViewerEvents(events []stream.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	v.tasks.Post(ctx, func() {
+		vi := v.viewerInput.extra
+		size := v.window.Bounds().Size()
+		for _, e := range events {
+			pos := pixel.V(e.X*size.X, (1-e.Y)*size.Y) // from the top left to the bottom left
+			switch e.Type {
+			case stream.EventDown, stream.EventUp:
+				button, ok := viewerButton(e)
+				if !ok {
+					continue
+				}
+				if e.Code == "" {
+					vi.MoveMouse(pos)
+				}
+				if e.Type == stream.EventDown {
+					vi.Press(button)
+				} else {
+					vi._ReleaseAfterSeen(button)
+				}
+			case stream.EventMove:
+				vi.MoveMouse(pos)
+			case stream.EventWheel:
+				vi.Scroll(pixel.V(e.DX, e.DY))
+			case stream.EventText:
+				vi.Type(e.Text)
+			}
+		}
+	})
+}
+
+// viewerKeys maps KeyboardEvent.code of browsers to keys, other than letters, digits and function keys.
+var viewerKeys = map[string]pixelgl.Button{
+	"Space":        pixelgl.KeySpace,
+	"Enter":        pixelgl.KeyEnter,
+	"NumpadEnter":  pixelgl.KeyKPEnter,
+	"Escape":       pixelgl.KeyEscape,
+	"Tab":          pixelgl.KeyTab,
+	"Backspace":    pixelgl.KeyBackspace,
+	"Backquote":    pixelgl.KeyGraveAccent,
+	"ArrowLeft":    pixelgl.KeyLeft,
+	"ArrowRight":   pixelgl.KeyRight,
+	"ArrowUp":      pixelgl.KeyUp,
+	"ArrowDown":    pixelgl.KeyDown,
+	"PageUp":       pixelgl.KeyPageUp,
+	"PageDown":     pixelgl.KeyPageDown,
+	"ShiftLeft":    pixelgl.KeyLeftShift,
+	"ShiftRight":   pixelgl.KeyRightShift,
+	"ControlLeft":  pixelgl.KeyLeftControl,
+	"ControlRight": pixelgl.KeyRightControl,
+	"AltLeft":      pixelgl.KeyLeftAlt,
+	"AltRight":     pixelgl.KeyRightAlt,
+}
+
+// viewerButton returns the key or the mouse button of an event from a viewer.
+func viewerButton(e stream.Event) (pixelgl.Button, bool) {
+	if e.Code == "" {
+		switch e.Button {
+		case 0:
+			return pixelgl.MouseButtonLeft, true
+		case 1:
+			return pixelgl.MouseButtonMiddle, true
+		case 2:
+			return pixelgl.MouseButtonRight, true
+		}
+		return 0, false
+	}
+	if button, ok := viewerKeys[e.Code]; ok {
+		return button, true
+	}
+	switch {
+	case len(e.Code) == 4 && strings.HasPrefix(e.Code, "Key") && 'A' <= e.Code[3] && e.Code[3] <= 'Z':
+		return pixelgl.KeyA + pixelgl.Button(e.Code[3]-'A'), true
+	case len(e.Code) == 6 && strings.HasPrefix(e.Code, "Digit") && '0' <= e.Code[5] && e.Code[5] <= '9':
+		return pixelgl.Key0 + pixelgl.Button(e.Code[5]-'0'), true
+	case strings.HasPrefix(e.Code, "F"):
+		if n, err := strconv.Atoi(e.Code[1:]); err == nil && 1 <= n && n <= 12 {
+			return pixelgl.KeyF1 + pixelgl.Button(n-1), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,280 @@
+// Package stream broadcasts frames as MJPEG over HTTP to browsers,
+// along with a tiny viewer page that sends mouse and keyboard events back.
+//
+//	b := stream.NewBroadcaster(15, 75)
+//	mux := http.NewServeMux()
+//	mux.Handle("/", stream.Page("my visualizer"))
+//	mux.Handle("/stream.mjpg", b)
+//	mux.Handle("/input", stream.InputHandler(func(events []stream.Event) { ... }))
+//	...
+//	if b.Due(time.Now()) { // every frame
+//		b.Publish(capture())
+//	}
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nanitefactory/visual/remote"
+)
+
+// -------------------------------------------------------------------------
+// Broadcaster
+
+// Boundary separates frames in the MJPEG stream.
+const Boundary = "frame"
+
+// Broadcaster encodes frames published in JPEG, and streams them to every viewer connected.
+// Frames are encoded off the goroutine publishing them, and dropped rather than queued if it can't keep up.
+type Broadcaster struct {
+	mutex    sync.Mutex
+	quality  int
+	interval time.Duration
+	last     time.Time // published
+	frames   chan image.Image
+	viewers  map[chan []byte]bool
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// NewBroadcaster is a constructor. It streams up to fps frames a second in the JPEG quality given (1 to 100).
+func NewBroadcaster(fps float64, quality int) *Broadcaster {
+	b := &Broadcaster{
+		quality:  quality,
+		interval: time.Duration(float64(time.Second) / fps),
+		frames:   make(chan image.Image, 1),
+		viewers:  map[chan []byte]bool{},
+		closed:   make(chan struct{}),
+	}
+	go b._Encode()
+	return b
+}
+
+// Viewers returns how many viewers are connected.
+func (b *Broadcaster) Viewers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.viewers)
+}
+
+// Due tells whether a frame is to be published now; there are viewers and it's been long enough since the last one.
+// It's cheap enough to be called every frame, so that frames are captured only when they're due.
+func (b *Broadcaster) Due(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.viewers) > 0 && now.Sub(b.last) >= b.interval
+}
+
+// Publish a frame to be streamed. It never blocks. The image must not be modified afterwards.
+func (b *Broadcaster) Publish(img image.Image) {
+	b.mutex.Lock()
+	b.last = time.Now()
+	b.mutex.Unlock()
+
+	select {
+	case b.frames <- img:
+	default: // The encoder is still busy with the previous one.
+	}
+}
+
+// Close disconnects all viewers.
+func (b *Broadcaster) Close() {
+	b.once.Do(func() { close(b.closed) })
+}
+
+// ServeHTTP streams frames in MJPEG, as multipart/x-mixed-replace, until the viewer is gone.
+func (b *Broadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	frames := make(chan []byte, 1)
+	b.mutex.Lock()
+	b.viewers[frames] = true
+	b.mutex.Unlock()
+	defer func() {
+		b.mutex.Lock()
+		delete(b.viewers, frames)
+		b.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+Boundary)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case data := <-frames:
+			_, err := fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", Boundary, len(data))
+			if err == nil {
+				_, err = w.Write(data) // shared by viewers; not to be appended to
+			}
+			if err == nil {
+				_, err = w.Write([]byte("\r\n"))
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-b.closed:
+			return
+		}
+	}
+}
+
+// unexported
+func (b *Broadcaster) _Encode() {
+	for {
+		select {
+		case img := <-b.frames:
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: b.quality}); err != nil {
+				continue
+			}
+			b.mutex.Lock()
+			for viewer := range b.viewers {
+				select {
+				case <-viewer: // The viewer hasn't caught up with the previous one, which gets replaced.
+				default:
+				}
+				viewer <- buf.Bytes()
+			}
+			b.mutex.Unlock()
+		case <-b.closed:
+			return
+		}
+	}
+}
+
+// -------------------------------------------------------------------------
+// Input
+
+// Types of an Event.
+const (
+	EventDown  = "down"  // a key or a mouse button pressed
+	EventUp    = "up"    // a key or a mouse button released
+	EventMove  = "move"  // the mouse moved
+	EventWheel = "wheel" // the mouse wheel scrolled
+	EventText  = "text"  // a character typed
+)
+
+// Event is a mouse or keyboard event on the viewer page.
+type Event struct {
+	Type   string  `json:"type"`
+	Code   string  `json:"code,omitempty"`   // of a key; KeyboardEvent.code such as "KeyA" or "ArrowLeft"
+	Button int     `json:"button,omitempty"` // of a mouse button if there's no code; 0 left, 1 middle, 2 right
+	X      float64 `json:"x"`                // of the mouse from the left edge, 0 to 1
+	Y      float64 `json:"y"`                // of the mouse from the top edge, 0 to 1
+	DX     float64 `json:"dx,omitempty"`     // of the wheel; positive to the right
+	DY     float64 `json:"dy,omitempty"`     // of the wheel; positive upward
+	Text   string  `json:"text,omitempty"`   // typed
+}
+
+// maxEvents is how many events a single request can have.
+const maxEvents = 1024
+
+// InputHandler returns an http.Handler that takes events the viewer page POSTs in a JSON array.
+// Requests from other origins, to a host other than localhost, or not in JSON are refused; (See remote.Guard().)
+// otherwise any web page open in the browser would be able to type into the visualizer.
+func InputHandler(handle func(events []Event)) http.Handler {
+	return remote.Guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, r.Method+" not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var events []Event
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(events) > maxEvents {
+			http.Error(w, "too many events", http.StatusRequestEntityTooLarge)
+			return
+		}
+		handle(events)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+// -------------------------------------------------------------------------
+// Viewer page
+
+// Page returns an http.Handler that serves the viewer page,
+// which shows "stream.mjpg" and POSTs events to "input" relative to it.
+func Page(title string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.Execute(w, title)
+	})
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+html, body { margin: 0; height: 100%; background: #222; }
+img { display: block; margin: auto; max-width: 100%; max-height: 100%; outline: none; cursor: crosshair; }
+</style>
+</head>
+<body>
+<img id="stream" src="stream.mjpg" tabindex="0" alt="{{.}}">
+<script>
+(function () {
+	var img = document.getElementById("stream");
+	var queue = [];
+	function at(e, ev) {
+		var r = img.getBoundingClientRect();
+		ev.x = (e.clientX - r.left) / r.width;
+		ev.y = (e.clientY - r.top) / r.height;
+		return ev;
+	}
+	function send(ev) { queue.push(ev); }
+	setInterval(function () {
+		if (queue.length === 0) { return; }
+		var events = queue;
+		queue = [];
+		fetch("input", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(events) });
+	}, 30);
+	img.addEventListener("contextmenu", function (e) { e.preventDefault(); });
+	img.addEventListener("dragstart", function (e) { e.preventDefault(); });
+	img.addEventListener("mousedown", function (e) { img.focus(); e.preventDefault(); send(at(e, { type: "down", button: e.button })); });
+	img.addEventListener("mouseup", function (e) { send(at(e, { type: "up", button: e.button })); });
+	img.addEventListener("mousemove", function (e) {
+		if (queue.length > 0 && queue[queue.length - 1].type === "move") { queue.pop(); }
+		send(at(e, { type: "move" }));
+	});
+	img.addEventListener("wheel", function (e) {
+		e.preventDefault();
+		send(at(e, { type: "wheel", dx: Math.sign(e.deltaX), dy: -Math.sign(e.deltaY) }));
+	}, { passive: false });
+	img.addEventListener("keydown", function (e) {
+		e.preventDefault();
+		if (!e.repeat) { send({ type: "down", code: e.code }); }
+		if (e.key.length === 1) { send({ type: "text", text: e.key }); }
+	});
+	img.addEventListener("keyup", function (e) { e.preventDefault(); send({ type: "up", code: e.code }); });
+	img.focus();
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBroadcaster(t *testing.T) {
+	b := NewBroadcaster(1, 75)
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+	defer b.Close() // before the server, which waits for the stream to end
+
+	if b.Due(time.Now()) {
+		t.Error("due without viewers")
+	}
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	for i := 0; b.Viewers() != 1; i++ {
+		if i > 1000 {
+			t.Fatal("the viewer is not counted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/x-mixed-replace" || params["boundary"] != Boundary {
+		t.Fatalf("content type %q", resp.Header.Get("Content-Type"))
+	}
+	parts := multipart.NewReader(resp.Body, Boundary)
+	for i := 0; i < 2; i++ {
+		if !b.Due(time.Now().Add(2 * time.Second)) {
+			t.Fatal("not due with a viewer")
+		}
+		b.Publish(image.NewRGBA(image.Rect(0, 0, 32+i, 24)))
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := jpeg.Decode(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w := img.Bounds().Dx(); w != 32+i {
+			t.Errorf("frame %d streamed %d wide; want %d", i, w, 32+i)
+		}
+	}
+	if b.Due(time.Now()) {
+		t.Error("due right after published")
+	}
+}
+
+func TestInputHandler(t *testing.T) {
+	var got []Event
+	h := InputHandler(func(events []Event) { got = events })
+
+	post := func(host, origin, contentType string) int {
+		req := httptest.NewRequest("POST", "http://"+host+"/input", strings.NewReader(`[{"type": "down", "code": "KeyA"}, {"type": "move", "x": 0.5, "y": 0.25}]`))
+		req.Header.Set("Content-Type", contentType)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post("127.0.0.1:9467", "http://127.0.0.1:9467", "application/json"); code != http.StatusNoContent || len(got) != 2 || got[0].Code != "KeyA" || got[1].Y != 0.25 {
+		t.Errorf("handled %d: %+v", code, got)
+	}
+	got = nil
+	for _, req := range [][3]string{
+		{"127.0.0.1:9467", "http://evil.example", "application/json"},
+		{"evil.example:9467", "http://evil.example:9467", "application/json"}, // rebound
+		{"localhost:9467", "", "text/plain"},
+	} {
+		if code := post(req[0], req[1], req[2]); code/100 != 4 || got != nil {
+			t.Errorf("%q handled %d", req, code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "http://127.0.0.1:9467/input", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET handled %d", rec.Code)
+	}
+}
+
+func TestPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Page("<my> visualizer").ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	if !strings.Contains(string(body), "<title>&lt;my&gt; visualizer</title>") || !strings.Contains(string(body), `src="stream.mjpg"`) {
+		t.Errorf("page served:\n%s", body)
+	}
+}
@@ -0,0 +1,368 @@
+package super
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"github.com/nanitefactory/visual/atlas"
+	"golang.org/x/image/colornames"
+)
+
+// -------------------------------------------------------------------------
+// Console
+
+// CommandFunc runs a console command with the arguments typed after its name,
+// and returns what's to be printed out.
+type CommandFunc func(args []string) string
+
+type consoleCommand struct {
+	help string
+	run  CommandFunc
+}
+
+// Console is a drop-down command line with history and autocomplete.
+// It is hidden until it's shown. (See SetVisible().)
+type Console struct {
+	txt   *text.Text     // shared variable
+	atlas *text.Atlas    // borrowed atlas for txt
+	imd   *imdraw.IMDraw // shared variable
+	mutex sync.Mutex     // synchronize
+	//
+	commands map[string]consoleCommand
+	lines    []string // output
+	nLines   int      // kept in output
+	input    []rune
+	history  []string
+	histPos  int     // len(history) when not browsing it
+	blink    float64 // seconds of the cursor blinking
+	visible  bool
+	isDirty  bool
+	//
+	bounds   pixel.Rect // on screen
+	colorBg  color.Color
+	colorTxt color.Color
+	colorIn  color.Color
+}
+
+// NewConsole is a constructor. It keeps up to nLines of output, and knows "help" and "clear" by default.
+func NewConsole(nLines int) *Console {
+	console := &Console{
+		atlas:    atlas.AtlasASCII18,
+		commands: map[string]consoleCommand{},
+		nLines:   nLines,
+		isDirty:  true,
+		colorBg:  color.RGBA{0, 0, 0, 0xd0},
+		colorTxt: colornames.White,
+		colorIn:  colornames.Yellow,
+	}
+	console.Register("help", "help [command]: lists commands, or tells how to use one", console._Help)
+	console.Register("clear", "clear: clears the output", func([]string) string {
+		console.mutex.Lock()
+		defer console.mutex.Unlock()
+
+		console.lines = nil
+		return ""
+	})
+	return console
+}
+
+// SetBounds to a rectangle in screen coords.
+func (console *Console) SetBounds(bounds pixel.Rect) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	console.bounds = bounds
+	console.isDirty = true
+}
+
+// SetVisible shows or hides this console.
+func (console *Console) SetVisible(visible bool) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	console.visible = visible
+	console.isDirty = true
+}
+
+// IsVisible determines whether this console is shown or not.
+func (console *Console) IsVisible() bool {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	return console.visible
+}
+
+// Toggle shows or hides this console.
+func (console *Console) Toggle() {
+	console.SetVisible(!console.IsVisible())
+}
+
+// Register a command. It replaces what's registered under the same name.
+// The help is a line telling how to use it.
+func (console *Console) Register(name, help string, run CommandFunc) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	console.commands[name] = consoleCommand{help, run}
+}
+
+// Commands returns the names of the commands registered in lexical order.
+func (console *Console) Commands() []string {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	return console._Commands()
+}
+
+// Exec runs a command line and returns its output. It's not printed out nor kept in history.
+func (console *Console) Exec(line string) string {
+	args := strings.Fields(line)
+	if len(args) <= 0 {
+		return ""
+	}
+	console.mutex.Lock()
+	cmd, ok := console.commands[args[0]]
+	console.mutex.Unlock()
+	if !ok {
+		return fmt.Sprintf("unknown command: %s (try help)", args[0])
+	}
+	return cmd.run(args[1:]) // without the lock since it may call the console back
+}
+
+// Print lines out.
+func (console *Console) Print(s string) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	console._Print(s)
+}
+
+// Lines returns what's printed out so far.
+func (console *Console) Lines() []string {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	return append([]string(nil), console.lines...)
+}
+
+// Input returns the command line being typed.
+func (console *Console) Input() string {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	return string(console.input)
+}
+
+// Type text into the command line. Control characters and backticks are ignored.
+func (console *Console) Type(s string) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	for _, r := range s {
+		if unicode.IsControl(r) || r == '`' {
+			continue
+		}
+		console.input = append(console.input, r)
+		console.isDirty = true
+	}
+}
+
+// Backspace deletes the last character typed.
+func (console *Console) Backspace() {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	if len(console.input) > 0 {
+		console.input = console.input[:len(console.input)-1]
+		console.isDirty = true
+	}
+}
+
+// Submit runs the command line typed, prints it out along with its output, and keeps it in history.
+func (console *Console) Submit() {
+	console.mutex.Lock()
+	line := strings.TrimSpace(string(console.input))
+	console.input = nil
+	console._Print("> " + line)
+	if line != "" && (len(console.history) <= 0 || console.history[len(console.history)-1] != line) {
+		console.history = append(console.history, line)
+	}
+	console.histPos = len(console.history)
+	console.mutex.Unlock()
+
+	if out := console.Exec(line); out != "" {
+		console.Print(out)
+	}
+}
+
+// HistoryPrev brings back the previous command line in history, or the next one if it's false.
+func (console *Console) HistoryPrev(prev bool) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	if prev && console.histPos > 0 {
+		console.histPos--
+	} else if !prev && console.histPos < len(console.history) {
+		console.histPos++
+	} else {
+		return
+	}
+	console.input = nil
+	if console.histPos < len(console.history) {
+		console.input = []rune(console.history[console.histPos])
+	}
+	console.isDirty = true
+}
+
+// Complete the command name being typed.
+// It's completed if there's only one candidate, or otherwise as far as they have in common with the candidates listed.
+func (console *Console) Complete() {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	typed := string(console.input)
+	if strings.ContainsRune(typed, ' ') {
+		return // only command names
+	}
+	var candidates []string
+	for _, name := range console._Commands() {
+		if strings.HasPrefix(name, typed) {
+			candidates = append(candidates, name)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		console.input = []rune(candidates[0] + " ")
+	default:
+		common := candidates[0]
+		for _, c := range candidates[1:] {
+			for !strings.HasPrefix(c, common) {
+				common = common[:len(common)-1]
+			}
+		}
+		console.input = []rune(common)
+		console._Print(strings.Join(candidates, "  "))
+	}
+	console.isDirty = true
+}
+
+// Update blinks the cursor. Nothing happens while it's hidden.
+func (console *Console) Update(dt float64) {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	if !console.visible {
+		return
+	}
+	wasOn := console._CursorOn()
+	console.blink += dt
+	if console.isDirty || console._CursorOn() != wasOn {
+		console.isDirty = false
+		console._Update()
+	}
+}
+
+// Draw Console.
+func (console *Console) Draw(t pixel.Target) {
+	// lock before accessing txt & imdraw
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	if !console.visible || console.imd == nil {
+		return
+	}
+
+	console.imd.Draw(t)
+	console.txt.Draw(t, pixel.IM)
+}
+
+// unexported
+func (console *Console) _Help(args []string) string {
+	console.mutex.Lock()
+	defer console.mutex.Unlock()
+
+	if len(args) > 0 {
+		if cmd, ok := console.commands[args[0]]; ok {
+			return cmd.help
+		}
+		return fmt.Sprintf("unknown command: %s", args[0])
+	}
+	return strings.Join(console._Commands(), " ")
+}
+
+// unexported
+func (console *Console) _Commands() []string {
+	ret := make([]string, 0, len(console.commands))
+	for name := range console.commands {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// unexported
+func (console *Console) _Print(s string) {
+	console.lines = append(console.lines, strings.Split(strings.TrimSuffix(s, "\n"), "\n")...)
+	if over := len(console.lines) - console.nLines; over > 0 {
+		console.lines = append(console.lines[:0], console.lines[over:]...)
+	}
+	console.isDirty = true
+}
+
+// unexported
+func (console *Console) _CursorOn() bool {
+	return int(console.blink*2)%2 == 0 // twice a second
+}
+
+// unexported
+func (console *Console) _Update() {
+	// text label (a state machine)
+	if console.txt == nil { // lazy creation
+		console.txt = text.New(pixel.ZV, console.atlas)
+	}
+	txt := console.txt
+	txt.Clear()
+
+	// The command line goes at the bottom, and the output above it from the bottom up as far as it fits.
+	b := console.bounds
+	nFit := int(b.H()/txt.LineHeight) - 1
+	lines := console.lines
+	if nFit < 0 {
+		nFit = 0
+	}
+	if len(lines) > nFit {
+		lines = lines[len(lines)-nFit:]
+	}
+	txt.Orig = pixel.V(b.Min.X+4, b.Min.Y+4+txt.LineHeight*float64(len(lines))+txt.Atlas().Descent())
+	txt.Dot = txt.Orig
+	txt.Color = console.colorTxt
+	for _, line := range lines {
+		txt.WriteString(line + "\n")
+	}
+	txt.Color = console.colorIn
+	cursor := " "
+	if console._CursorOn() {
+		cursor = "_"
+	}
+	txt.WriteString("> " + string(console.input) + cursor)
+
+	// imdraw (a state machine)
+	if console.imd == nil { // lazy creation
+		console.imd = imdraw.New(nil)
+	}
+	imd := console.imd
+	imd.Clear()
+
+	imd.Color = console.colorBg
+	imd.Push(b.Min, b.Max)
+	imd.Rectangle(0)
+}
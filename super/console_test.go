@@ -0,0 +1,65 @@
+package super
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConsole(t *testing.T) {
+	console := NewConsole(100)
+	var got []string
+	console.Register("echo", "echo [args...]", func(args []string) string {
+		got = args
+		return strings.Join(args, " ")
+	})
+	console.Register("explode", "explode x y", func([]string) string { return "" })
+
+	console.Type("ec\x08`")
+	console.Complete()
+	if in := console.Input(); in != "echo " {
+		t.Fatalf("completed %q; want %q", in, "echo ")
+	}
+	console.Type("hello  world")
+	console.Submit()
+	if len(got) != 2 || got[0] != "hello" || got[1] != "world" {
+		t.Errorf("run with %q; want [hello world]", got)
+	}
+	if lines := console.Lines(); len(lines) != 2 || lines[0] != "> echo hello  world" || lines[1] != "hello world" {
+		t.Errorf("printed %q", lines)
+	}
+
+	console.Type("e")
+	console.Complete() // echo or explode
+	if in := console.Input(); in != "e" {
+		t.Errorf("completed %q out of two candidates; want %q", in, "e")
+	}
+	console.Type("xx")
+	console.Backspace()
+	console.Complete()
+	if in := console.Input(); in != "explode " {
+		t.Errorf("completed %q; want %q", in, "explode ")
+	}
+	console.Submit()
+
+	console.HistoryPrev(true)
+	console.HistoryPrev(true)
+	if in := console.Input(); in != "echo hello  world" {
+		t.Errorf("history brought back %q; want the first command", in)
+	}
+	console.HistoryPrev(false)
+	console.HistoryPrev(false)
+	if in := console.Input(); in != "" {
+		t.Errorf("history brought back %q past the last one; want nothing", in)
+	}
+
+	if out := console.Exec("nope"); !strings.HasPrefix(out, "unknown command") {
+		t.Errorf("an unknown command outputs %q", out)
+	}
+	if out := console.Exec("help echo"); out != "echo [args...]" {
+		t.Errorf("help outputs %q", out)
+	}
+	console.Exec("clear")
+	if n := len(console.Lines()); n != 0 {
+		t.Errorf("%d lines left after clear", n)
+	}
+}
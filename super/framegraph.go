@@ -0,0 +1,232 @@
+package super
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"github.com/nanitefactory/visual/atlas"
+	"golang.org/x/image/colornames"
+)
+
+// Frame budgets drawn as lines on a FrameGraph.
+const (
+	Budget60FPS  = time.Second / 60  // 16.6ms
+	Budget120FPS = time.Second / 120 // 8.3ms
+)
+
+// PhaseColors are the colors of phases stacked up in a FrameGraph.
+var PhaseColors = [NPhases]color.Color{
+	PhaseEvents: colornames.Deepskyblue,
+	PhaseUpdate: colornames.Limegreen,
+	PhaseDraw:   colornames.Orange,
+	PhaseSwap:   colornames.Mediumpurple,
+	PhaseVsync:  colornames.Dimgray,
+}
+
+// FrameGraph is a scrolling graph of frame times, where each frame is a bar of its phases stacked up.
+// Frames over the budget of 60 FPS are highlighted as spikes.
+// It is hidden until it's shown. (See SetVisible().)
+type FrameGraph struct {
+	txt   *text.Text     // shared variable
+	atlas *text.Atlas    // borrowed atlas for txt
+	imd   *imdraw.IMDraw // shared variable
+	mutex sync.Mutex     // synchronize
+	//
+	stats   func() FrameStats
+	visible bool
+	//
+	size     pixel.Vec     // of the graph without labels
+	ceiling  time.Duration // The frame time at the top of the graph.
+	pos      pixel.Vec
+	anchorX  AnchorX
+	anchorY  AnchorY
+	colorBg  color.Color
+	colorTxt color.Color
+	colorHot color.Color // spikes
+}
+
+// NewFrameGraph is a constructor. A graph shows a bar per frame of what the stats func returns every frame.
+func NewFrameGraph(
+	stats func() FrameStats, size pixel.Vec, _pos pixel.Vec,
+	_anchorY AnchorY, _anchorX AnchorX, // This is because the order is usually Y then X in spoken language.
+) *FrameGraph {
+	return &FrameGraph{
+		atlas:    atlas.AtlasASCII18,
+		stats:    stats,
+		size:     size,
+		ceiling:  2 * Budget60FPS,
+		pos:      _pos,
+		anchorX:  _anchorX,
+		anchorY:  _anchorY,
+		colorBg:  color.RGBA{0, 0, 0, 0xc0},
+		colorTxt: colornames.White,
+		colorHot: colornames.Red,
+	}
+}
+
+// SetPos to a position in screen coords.
+func (graph *FrameGraph) SetPos(pos pixel.Vec, anchorY AnchorY, anchorX AnchorX) {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph.pos = pos
+	graph.anchorX = anchorX
+	graph.anchorY = anchorY
+}
+
+// SetVisible shows or hides this graph.
+func (graph *FrameGraph) SetVisible(visible bool) {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph.visible = visible
+}
+
+// IsVisible determines whether this graph is shown or not.
+func (graph *FrameGraph) IsVisible() bool {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	return graph.visible
+}
+
+// Toggle shows this graph if hidden, or hides it if shown.
+func (graph *FrameGraph) Toggle() {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph.visible = !graph.visible
+}
+
+// Bounds returns the rect of this graph in screen coords, labels excluded.
+func (graph *FrameGraph) Bounds() pixel.Rect {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	return graph._Bounds()
+}
+
+// Update the graph with the latest stats. Nothing happens while it's hidden.
+func (graph *FrameGraph) Update(_ float64) {
+	if !graph.IsVisible() {
+		return
+	}
+	stats := graph.stats()
+
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph._Update(stats)
+}
+
+// Draw FrameGraph.
+func (graph *FrameGraph) Draw(t pixel.Target) {
+	// lock before accessing txt & imdraw
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	if !graph.visible || graph.imd == nil {
+		return
+	}
+
+	graph.imd.Draw(t)
+	graph.txt.Draw(t, pixel.IM)
+}
+
+// unexported
+func (graph *FrameGraph) _Bounds() pixel.Rect {
+	return anchoredRect(graph.pos, graph.size, graph.anchorY, graph.anchorX)
+}
+
+// unexported
+func (graph *FrameGraph) _Update(stats FrameStats) {
+	r := graph._Bounds()
+	yOf := func(d time.Duration) float64 {
+		if d > graph.ceiling {
+			d = graph.ceiling
+		}
+		return r.Min.Y + r.H()*float64(d)/float64(graph.ceiling)
+	}
+
+	// imdraw (a state machine)
+	if graph.imd == nil { // lazy creation
+		graph.imd = imdraw.New(nil)
+	}
+	imd := graph.imd
+	imd.Clear()
+
+	// background, labels included
+	lineHeight := graph.atlas.LineHeight()
+	imd.Color = graph.colorBg
+	imd.Push(pixel.V(r.Min.X, r.Min.Y-2*lineHeight-4), r.Max)
+	imd.Rectangle(0)
+
+	// a bar per frame from the right, the latest first
+	barW := 1.0
+	if n := len(stats.Frames); n > 0 && r.W()/float64(n) > barW {
+		barW = r.W() / float64(n)
+	}
+	for i := len(stats.Frames) - 1; i >= 0; i-- {
+		x := r.Max.X - barW*float64(len(stats.Frames)-i)
+		if x < r.Min.X {
+			break
+		}
+		f := stats.Frames[i]
+		sum := time.Duration(0)
+		for p, d := range f.Phases {
+			if d <= 0 {
+				continue
+			}
+			y0, y1 := yOf(sum), yOf(sum+d)
+			sum += d
+			if y1 <= y0 {
+				continue
+			}
+			imd.Color = PhaseColors[p]
+			imd.Push(pixel.V(x, y0), pixel.V(x+barW, y1))
+			imd.Rectangle(0)
+		}
+		if f.Total > Budget60FPS { // spike
+			imd.Color = graph.colorHot
+			imd.Push(pixel.V(x, yOf(f.Total)-2), pixel.V(x+barW, yOf(f.Total)))
+			imd.Rectangle(0)
+			imd.Push(pixel.V(x, r.Min.Y), pixel.V(x+barW, r.Min.Y+2))
+			imd.Rectangle(0)
+		}
+	}
+
+	// budget lines
+	for _, budget := range []time.Duration{Budget60FPS, Budget120FPS} {
+		imd.Color = graph.colorTxt
+		imd.Push(pixel.V(r.Min.X, yOf(budget)), pixel.V(r.Max.X, yOf(budget)))
+		imd.Line(1)
+	}
+
+	// text label (a state machine)
+	if graph.txt == nil { // lazy creation
+		graph.txt = text.New(pixel.ZV, graph.atlas)
+	}
+	txt := graph.txt
+	txt.Clear()
+	txt.Color = graph.colorTxt
+	for _, budget := range []time.Duration{Budget60FPS, Budget120FPS} {
+		str := fmt.Sprintf("%.1fms", float64(budget)/float64(time.Millisecond))
+		txt.Dot = pixel.V(r.Min.X+2, yOf(budget)+2)
+		txt.WriteString(str)
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	str := fmt.Sprintf("avg %.1f  p99 %.1f  max %.1f ms",
+		ms(stats.Total.Avg), ms(stats.Total.P99), ms(stats.Total.Max))
+	txt.Dot = pixel.V(r.Min.X+2, r.Min.Y-lineHeight)
+	txt.WriteString(str)
+	txt.Dot = pixel.V(r.Min.X+2, r.Min.Y-2*lineHeight)
+	for p := Phase(0); p < NPhases; p++ {
+		txt.Color = PhaseColors[p]
+		txt.WriteString(p.String() + " ")
+	}
+}
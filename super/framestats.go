@@ -0,0 +1,189 @@
+package super
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phase is a part of a single frame that takes its time.
+type Phase int
+
+// enum Phase
+const (
+	PhaseEvents Phase = iota // Getting and handling user inputs.
+	PhaseUpdate              // Updating actors.
+	PhaseDraw                // Drawing actors.
+	PhaseSwap                // Updating the window; swapping buffers and polling events.
+	PhaseVsync               // Waiting on the vsync.
+	NPhases                  // The number of phases, not a phase.
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseEvents:
+		return "events"
+	case PhaseUpdate:
+		return "update"
+	case PhaseDraw:
+		return "draw"
+	case PhaseSwap:
+		return "swap"
+	case PhaseVsync:
+		return "vsync"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// FrameTiming is how long a single frame took, phase by phase.
+type FrameTiming struct {
+	Start  time.Time
+	Total  time.Duration
+	Phases [NPhases]time.Duration
+}
+
+// Durations sums up a set of durations.
+type Durations struct {
+	Min, Avg, Max time.Duration
+	P95, P99      time.Duration // 95th and 99th percentiles.
+}
+
+// FrameStats sums up the frames recorded lately.
+type FrameStats struct {
+	Frames []FrameTiming      // From the oldest to the latest.
+	Total  Durations          // Frame times.
+	Phases [NPhases]Durations // Each phase of frames.
+}
+
+// Last returns the latest frame recorded. It's zero if there's none.
+func (fs FrameStats) Last() FrameTiming {
+	if len(fs.Frames) <= 0 {
+		return FrameTiming{}
+	}
+	return fs.Frames[len(fs.Frames)-1]
+}
+
+// -------------------------------------------------------------------------
+
+// FrameTimer records frame timings in a ring buffer, of a limited number of frames lately.
+// Only a single goroutine (the mainthread) may record, but it is safe to read Stats() from any goroutine.
+type FrameTimer struct {
+	now func() time.Time
+	// the frame being recorded; mainthread only
+	curr     FrameTiming
+	lastMark time.Time
+	inFrame  bool
+	// recorded
+	mutex  sync.Mutex
+	frames []FrameTiming // ring buffer
+	next   int
+	n      int
+}
+
+// NewFrameTimer is a constructor. It keeps up to capacity frames.
+func NewFrameTimer(capacity int) *FrameTimer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &FrameTimer{
+		now:    time.Now,
+		frames: make([]FrameTiming, capacity),
+	}
+}
+
+// BeginFrame starts recording a frame. A frame begun but not ended yet gets discarded.
+func (ft *FrameTimer) BeginFrame() {
+	now := ft.now()
+	ft.curr = FrameTiming{Start: now}
+	ft.lastMark = now
+	ft.inFrame = true
+}
+
+// Mark ends a phase; the time since the last mark, or since the frame began, is taken by the phase.
+// It does nothing outside of a frame.
+func (ft *FrameTimer) Mark(phase Phase) {
+	if !ft.inFrame || phase < 0 || phase >= NPhases {
+		return
+	}
+	now := ft.now()
+	ft.curr.Phases[phase] += now.Sub(ft.lastMark)
+	ft.lastMark = now
+}
+
+// EndFrame ends the frame and records it. It does nothing outside of a frame.
+func (ft *FrameTimer) EndFrame() {
+	if !ft.inFrame {
+		return
+	}
+	ft.inFrame = false
+	ft.curr.Total = ft.now().Sub(ft.curr.Start)
+
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	ft.frames[ft.next] = ft.curr
+	ft.next = (ft.next + 1) % len(ft.frames)
+	if ft.n < len(ft.frames) {
+		ft.n++
+	}
+}
+
+// Last returns the latest frame recorded. It's zero if there's none.
+func (ft *FrameTimer) Last() FrameTiming {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	if ft.n <= 0 {
+		return FrameTiming{}
+	}
+	return ft.frames[(ft.next-1+len(ft.frames))%len(ft.frames)]
+}
+
+// Stats sums up the frames recorded so far.
+func (ft *FrameTimer) Stats() FrameStats {
+	ft.mutex.Lock()
+	frames := make([]FrameTiming, ft.n)
+	for i := range frames {
+		frames[i] = ft.frames[(ft.next-ft.n+i+len(ft.frames))%len(ft.frames)]
+	}
+	ft.mutex.Unlock()
+
+	stats := FrameStats{Frames: frames}
+	ds := make([]time.Duration, len(frames))
+	for i, f := range frames {
+		ds[i] = f.Total
+	}
+	stats.Total = sumUp(ds)
+	for p := range stats.Phases {
+		for i, f := range frames {
+			ds[i] = f.Phases[p]
+		}
+		stats.Phases[p] = sumUp(ds)
+	}
+	return stats
+}
+
+// sumUp sorts durations in place and sums them up.
+func sumUp(ds []time.Duration) Durations {
+	if len(ds) <= 0 {
+		return Durations{}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	sum := time.Duration(0)
+	for _, d := range ds {
+		sum += d
+	}
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p * float64(len(ds)))) // nearest rank
+		return ds[rank-1]
+	}
+	return Durations{
+		Min: ds[0],
+		Avg: sum / time.Duration(len(ds)),
+		Max: ds[len(ds)-1],
+		P95: percentile(0.95),
+		P99: percentile(0.99),
+	}
+}
@@ -0,0 +1,49 @@
+package super
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameTimer(t *testing.T) {
+	clock := time.Unix(0, 0)
+	ft := NewFrameTimer(100)
+	ft.now = func() time.Time { return clock }
+	tick := func(d time.Duration) { clock = clock.Add(d) }
+
+	// 200 frames of 1ms to 200ms; only the last 100 are kept.
+	for i := 1; i <= 200; i++ {
+		ft.BeginFrame()
+		tick(time.Millisecond)
+		ft.Mark(PhaseEvents)
+		tick(time.Duration(i-1) * time.Millisecond)
+		ft.Mark(PhaseDraw)
+		ft.EndFrame()
+	}
+	ft.Mark(PhaseUpdate) // outside of a frame
+	ft.EndFrame()
+
+	stats := ft.Stats()
+	if len(stats.Frames) != 100 {
+		t.Fatalf("%d frames kept; want 100", len(stats.Frames))
+	}
+	if got := stats.Last().Total; got != 200*time.Millisecond {
+		t.Errorf("last frame took %v; want 200ms", got)
+	}
+	want := Durations{
+		Min: 101 * time.Millisecond,
+		Avg: 150500 * time.Microsecond,
+		Max: 200 * time.Millisecond,
+		P95: 195 * time.Millisecond,
+		P99: 199 * time.Millisecond,
+	}
+	if stats.Total != want {
+		t.Errorf("frame times %+v; want %+v", stats.Total, want)
+	}
+	if events := stats.Phases[PhaseEvents]; events.Min != time.Millisecond || events.Max != time.Millisecond {
+		t.Errorf("events took %+v; want 1ms each", events)
+	}
+	if update := stats.Phases[PhaseUpdate]; update.Max != 0 {
+		t.Errorf("update took %+v; want none", update)
+	}
+}
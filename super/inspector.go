@@ -0,0 +1,313 @@
+package super
+
+import (
+	"fmt"
+	"image/color"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"github.com/nanitefactory/visual/atlas"
+	"golang.org/x/image/colornames"
+)
+
+// -------------------------------------------------------------------------
+// Inspection
+
+// Field is a named property of an actor shown in an Inspector.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Inspectable is an actor that tells what's to be inspected of itself.
+// Actors that are not Inspectables get their struct fields inspected by reflection.
+type Inspectable interface {
+	Inspect() []Field
+}
+
+// inspectedValueLen is the max length of a value inspected by reflection.
+const inspectedValueLen = 60
+
+// InspectFields returns the fields of an actor, through Inspect() if it's Inspectable,
+// or otherwise every field of the struct it is or points to, exported or not.
+func InspectFields(actor interface{}) []Field {
+	if i, ok := actor.(Inspectable); ok {
+		return i.Inspect()
+	}
+	rv := reflect.ValueOf(actor)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return []Field{{"value", truncate(fmt.Sprint(rv), inspectedValueLen)}}
+	}
+	ret := make([]Field, 0, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		// fmt prints what a reflect.Value holds even if it's unexported.
+		ret = append(ret, Field{rv.Type().Field(i).Name, truncate(fmt.Sprint(rv.Field(i)), inspectedValueLen)})
+	}
+	return ret
+}
+
+func truncate(s string, n int) string {
+	s = strings.Replace(s, "\n", " ", -1)
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
+
+// InspectedItem is an actor (or a HUD) listed in an Inspector.
+type InspectedItem struct {
+	Actor       interface{}
+	Type        string
+	HUD         bool
+	Visible     bool
+	Active      bool
+	Quarantined bool
+	Tags        []string
+}
+
+// -------------------------------------------------------------------------
+// Inspector
+
+// Inspector lists actors and HUDs in draw order, and the fields of the one selected.
+// It is hidden until it's shown. (See SetVisible().)
+type Inspector struct {
+	txt   *text.Text     // shared variable
+	atlas *text.Atlas    // borrowed atlas for txt
+	imd   *imdraw.IMDraw // shared variable
+	mutex sync.Mutex     // synchronize
+	//
+	list     func() []InspectedItem
+	items    []InspectedItem // as of the last refresh
+	visible  bool
+	selected int
+	nRows    int
+	sinceRef float64 // seconds since the last refresh
+	//
+	pos      pixel.Vec
+	anchorX  AnchorX
+	anchorY  AnchorY
+	colorBg  color.Color
+	colorTxt color.Color
+}
+
+// inspectorRefresh is how often an Inspector gets refreshed in seconds.
+const inspectorRefresh = 0.25
+
+// NewInspector is a constructor. It lists up to nRows actors out of what list returns,
+// which is called back on Update().
+func NewInspector(
+	list func() []InspectedItem, nRows int, _pos pixel.Vec,
+	_anchorY AnchorY, _anchorX AnchorX, // This is because the order is usually Y then X in spoken language.
+) *Inspector {
+	return &Inspector{
+		atlas:    atlas.AtlasASCII18,
+		list:     list,
+		nRows:    nRows,
+		pos:      _pos,
+		anchorX:  _anchorX,
+		anchorY:  _anchorY,
+		colorBg:  color.RGBA{0, 0, 0, 0xc0},
+		colorTxt: colornames.White,
+	}
+}
+
+// SetPos to a position in screen coords.
+func (ins *Inspector) SetPos(pos pixel.Vec, anchorY AnchorY, anchorX AnchorX) {
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	ins.pos = pos
+	ins.anchorX = anchorX
+	ins.anchorY = anchorY
+	ins.sinceRef = inspectorRefresh // refresh on the next update
+}
+
+// SetVisible shows or hides this inspector.
+func (ins *Inspector) SetVisible(visible bool) {
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	ins.visible = visible
+	ins.sinceRef = inspectorRefresh
+}
+
+// IsVisible determines whether this inspector is shown or not.
+func (ins *Inspector) IsVisible() bool {
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	return ins.visible
+}
+
+// Select the i-th item listed. It wraps around the list.
+func (ins *Inspector) Select(i int) {
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	ins.selected = i
+	if n := len(ins.items); n > 0 {
+		ins.selected = (i%n + n) % n
+	}
+	ins.sinceRef = inspectorRefresh
+}
+
+// Selected returns the item selected as of the last refresh, if any.
+func (ins *Inspector) Selected() (item InspectedItem, index int, ok bool) {
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	if ins.selected < 0 || ins.selected >= len(ins.items) {
+		return InspectedItem{}, ins.selected, false
+	}
+	return ins.items[ins.selected], ins.selected, true
+}
+
+// SelectNext moves the selection down the list, or up if delta is negative.
+func (ins *Inspector) SelectNext(delta int) {
+	_, i, _ := ins.Selected()
+	ins.Select(i + delta)
+}
+
+// Update refreshes the list every once in a while. Nothing happens while it's hidden.
+func (ins *Inspector) Update(dt float64) {
+	ins.mutex.Lock()
+	if !ins.visible {
+		ins.mutex.Unlock()
+		return
+	}
+	ins.sinceRef += dt
+	if ins.sinceRef < inspectorRefresh && ins.txt != nil {
+		ins.mutex.Unlock()
+		return
+	}
+	ins.sinceRef = 0
+	ins.mutex.Unlock()
+
+	items := ins.list()
+	var fields []Field
+	ins.mutex.Lock()
+	if ins.selected >= len(items) {
+		ins.selected = len(items) - 1
+	}
+	if ins.selected < 0 && len(items) > 0 {
+		ins.selected = 0
+	}
+	selected := ins.selected
+	ins.mutex.Unlock()
+	if selected >= 0 {
+		fields = InspectFields(items[selected].Actor) // without the lock since it may call Inspect() back
+	}
+
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	ins.items = items
+	ins._Update(fields)
+}
+
+// Draw Inspector.
+func (ins *Inspector) Draw(t pixel.Target) {
+	// lock before accessing txt & imdraw
+	ins.mutex.Lock()
+	defer ins.mutex.Unlock()
+
+	if !ins.visible || ins.imd == nil {
+		return
+	}
+
+	ins.imd.Draw(t)
+	ins.txt.Draw(t, pixel.IM)
+}
+
+// unexported
+func (ins *Inspector) _Update(fields []Field) {
+	var sb strings.Builder
+	nHUDs := 0
+	countByType := map[string]int{}
+	for _, item := range ins.items {
+		if item.HUD {
+			nHUDs++
+		}
+		countByType[item.Type]++
+	}
+	types := make([]string, 0, len(countByType))
+	for typ, n := range countByType {
+		types = append(types, fmt.Sprintf("%s x%d", typ, n))
+	}
+	sort.Strings(types)
+
+	fmt.Fprintf(&sb, "INSPECTOR %d actors, %d HUDs\n", len(ins.items)-nHUDs, nHUDs)
+	if len(types) > 0 {
+		fmt.Fprintf(&sb, "%s\n", truncate(strings.Join(types, ", "), 2*inspectedValueLen))
+	}
+	first := 0 // of the rows listed, scrolled to the one selected
+	if ins.selected >= ins.nRows {
+		first = ins.selected - ins.nRows + 1
+	}
+	for i := first; i < len(ins.items) && i < first+ins.nRows; i++ {
+		item := ins.items[i]
+		cursor, where := " ", "world"
+		if i == ins.selected {
+			cursor = ">"
+		}
+		if item.HUD {
+			where = "hud"
+		}
+		fmt.Fprintf(&sb, "%s%3d %-5s %s", cursor, i, where, item.Type)
+		if !item.Visible {
+			sb.WriteString(" [hidden]")
+		}
+		if !item.Active {
+			sb.WriteString(" [inactive]")
+		}
+		if item.Quarantined {
+			sb.WriteString(" [quarantined]")
+		}
+		for _, tag := range item.Tags {
+			fmt.Fprintf(&sb, " #%s", tag)
+		}
+		sb.WriteString("\n")
+	}
+	if rest := len(ins.items) - first - ins.nRows; rest > 0 {
+		fmt.Fprintf(&sb, "... and %d more\n", rest)
+	}
+	for _, f := range fields {
+		fmt.Fprintf(&sb, "    %s: %s\n", f.Name, f.Value)
+	}
+	str := strings.TrimSuffix(sb.String(), "\n")
+
+	// text label (a state machine)
+	if ins.txt == nil { // lazy creation
+		ins.txt = text.New(pixel.ZV, ins.atlas)
+	}
+	txt := ins.txt
+	txt.Clear()
+
+	AnchorTxt(txt, ins.pos, ins.anchorX, ins.anchorY, str)
+	txt.Color = ins.colorTxt
+	txt.Dot.Y += txt.BoundsOf(str).H() - txt.LineHeight // The first line goes on top.
+	txt.Orig = txt.Dot                                  // and the others start where it does.
+	txt.WriteString(str)
+
+	// imdraw (a state machine)
+	if ins.imd == nil { // lazy creation
+		ins.imd = imdraw.New(nil)
+	}
+	imd := ins.imd
+	imd.Clear()
+
+	imd.Color = ins.colorBg
+	imd.Push(txt.Bounds().Min, txt.Bounds().Max)
+	imd.Rectangle(0)
+}
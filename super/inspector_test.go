@@ -0,0 +1,60 @@
+package super
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+type inspected struct {
+	Name  string
+	speed float64
+}
+
+type inspectable struct{}
+
+func (inspectable) Inspect() []Field { return []Field{{"hp", "100"}} }
+
+func TestInspectFields(t *testing.T) {
+	got := InspectFields(&inspected{"ship", 1.5})
+	if len(got) != 2 || got[0] != (Field{"Name", "ship"}) || got[1] != (Field{"speed", "1.5"}) {
+		t.Errorf("fields %v; want Name and speed by reflection", got)
+	}
+	if got := InspectFields(inspectable{}); len(got) != 1 || got[0].Name != "hp" {
+		t.Errorf("fields %v; want what Inspect() returns", got)
+	}
+	if got := InspectFields((*inspected)(nil)); got != nil {
+		t.Errorf("fields %v of nil; want none", got)
+	}
+	long := make([]byte, 100)
+	for i := range long {
+		long[i] = 'x'
+	}
+	if got := InspectFields(&inspected{Name: string(long)}); len(got[0].Value) != inspectedValueLen {
+		t.Errorf("a long value inspected in %d chars; want %d", len(got[0].Value), inspectedValueLen)
+	}
+}
+
+func TestInspectorSelect(t *testing.T) {
+	items := []InspectedItem{
+		{Actor: &inspected{Name: "a"}, Type: "*super.inspected", Visible: true, Active: true},
+		{Actor: &inspected{Name: "b"}, Type: "*super.inspected", Visible: true, Active: true},
+		{Actor: inspectable{}, Type: "super.inspectable", HUD: true},
+	}
+	ins := NewInspector(func() []InspectedItem { return items }, 10, pixel.ZV, Top, Left)
+	ins.SetVisible(true)
+	ins.Update(0)
+	if item, i, ok := ins.Selected(); !ok || i != 0 || item.Actor != items[0].Actor {
+		t.Fatalf("selected %d %v; want the first one by default", i, ok)
+	}
+	ins.SelectNext(-1)
+	if _, i, _ := ins.Selected(); i != 2 {
+		t.Errorf("selected %d before the first; want the last one", i)
+	}
+
+	items = items[:1]
+	ins.Update(inspectorRefresh)
+	if _, i, ok := ins.Selected(); !ok || i != 0 {
+		t.Errorf("selected %d %v after the list got shorter; want the last one", i, ok)
+	}
+}
@@ -0,0 +1,324 @@
+package super
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"github.com/nanitefactory/visual/atlas"
+)
+
+// -------------------------------------------------------------------------
+// Shape
+
+// ShapeKind is what a Shape looks like.
+type ShapeKind int
+
+// Kinds of a Shape and how many points each of them takes.
+const (
+	ShapeRect    ShapeKind = iota // 2 points; the min and max corners
+	ShapeCircle                   // 1 point; the center, with a radius
+	ShapeLine                     // 2 or more points; a polyline
+	ShapePolygon                  // 3 or more points
+	NShapeKinds
+)
+
+var shapeKindNames = [NShapeKinds]string{"rect", "circle", "line", "polygon"}
+
+func (kind ShapeKind) String() string {
+	if kind < 0 || kind >= NShapeKinds {
+		return fmt.Sprintf("ShapeKind(%d)", int(kind))
+	}
+	return shapeKindNames[kind]
+}
+
+// ParseShapeKind parses what ShapeKind.String() returns, such as "rect".
+func ParseShapeKind(s string) (ShapeKind, error) {
+	for kind, name := range shapeKindNames {
+		if name == s {
+			return ShapeKind(kind), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown shape %q", s)
+}
+
+// minPoints returns how many points a kind takes at least.
+func (kind ShapeKind) minPoints() int {
+	switch kind {
+	case ShapeCircle:
+		return 1
+	case ShapePolygon:
+		return 3
+	}
+	return 2
+}
+
+// Shape is a rectangle, a circle, a line or a polygon in a single color.
+type Shape struct {
+	imd   *imdraw.IMDraw // shared variable
+	mutex sync.Mutex     // synchronize
+	//
+	kind      ShapeKind
+	points    []pixel.Vec
+	radius    float64
+	thickness float64 // 0 to fill it in, except for lines
+	color     color.Color
+	isDirty   bool
+}
+
+// NewShape is a constructor. It fills the shape in if the thickness is 0, but a line is drawn 1 thick instead.
+func NewShape(kind ShapeKind, points []pixel.Vec, radius, thickness float64, col color.Color) (*Shape, error) {
+	shape := &Shape{}
+	if err := shape.Set(kind, points, radius, thickness, col); err != nil {
+		return nil, err
+	}
+	return shape, nil
+}
+
+// Set what this shape looks like. It's left as it was on an error.
+func (shape *Shape) Set(kind ShapeKind, points []pixel.Vec, radius, thickness float64, col color.Color) error {
+	if kind < 0 || kind >= NShapeKinds {
+		return fmt.Errorf("unknown shape %v", kind)
+	}
+	if len(points) < kind.minPoints() || (kind == ShapeRect && len(points) != 2) || (kind == ShapeCircle && len(points) != 1) {
+		return fmt.Errorf("%d points given to a %v", len(points), kind)
+	}
+	if kind == ShapeCircle && !(radius > 0) {
+		return fmt.Errorf("a circle of radius %v", radius)
+	}
+	if !(thickness >= 0) {
+		return fmt.Errorf("a shape %v thick", thickness)
+	}
+
+	shape.mutex.Lock()
+	defer shape.mutex.Unlock()
+
+	shape.kind = kind
+	shape.points = append([]pixel.Vec(nil), points...)
+	shape.radius = radius
+	shape.thickness = thickness
+	shape.color = col
+	shape.isDirty = true
+	return nil
+}
+
+// Bounds of this shape.
+func (shape *Shape) Bounds() pixel.Rect {
+	shape.mutex.Lock()
+	defer shape.mutex.Unlock()
+
+	if shape.kind == ShapeCircle {
+		c := shape.points[0]
+		return pixel.R(c.X-shape.radius, c.Y-shape.radius, c.X+shape.radius, c.Y+shape.radius)
+	}
+	return boundsOf(shape.points)
+}
+
+// Draw Shape.
+func (shape *Shape) Draw(t pixel.Target) {
+	// lock before accessing imdraw
+	shape.mutex.Lock()
+	defer shape.mutex.Unlock()
+
+	if shape.isDirty {
+		shape.isDirty = false
+		shape._Update()
+	}
+	shape.imd.Draw(t)
+}
+
+// unexported
+func (shape *Shape) _Update() {
+	// imdraw (a state machine)
+	if shape.imd == nil { // lazy creation
+		shape.imd = imdraw.New(nil)
+	}
+	imd := shape.imd
+	imd.Clear()
+
+	imd.Color = shape.color
+	imd.Push(shape.points...)
+	switch shape.kind {
+	case ShapeRect:
+		imd.Rectangle(shape.thickness)
+	case ShapeCircle:
+		imd.Circle(shape.radius, shape.thickness)
+	case ShapeLine:
+		thickness := shape.thickness
+		if thickness <= 0 {
+			thickness = 1
+		}
+		imd.Line(thickness)
+	case ShapePolygon:
+		imd.Polygon(shape.thickness)
+	}
+}
+
+// -------------------------------------------------------------------------
+// Label
+
+// Label is a text drawn with its bottom left corner at a position, scaled up or down.
+type Label struct {
+	txt   *text.Text  // shared variable
+	atlas *text.Atlas // borrowed atlas for txt
+	mutex sync.Mutex  // synchronize
+	//
+	str     string
+	pos     pixel.Vec
+	scale   float64
+	color   color.Color
+	isDirty bool
+}
+
+// NewLabel is a constructor. A scale of 1 draws the text at 18 points.
+func NewLabel(str string, pos pixel.Vec, scale float64, col color.Color) *Label {
+	label := &Label{atlas: atlas.AtlasASCII18}
+	label.Set(str, pos, scale, col)
+	return label
+}
+
+// Set what this label reads and where it is. A scale of 0 or less is taken as 1.
+func (label *Label) Set(str string, pos pixel.Vec, scale float64, col color.Color) {
+	label.mutex.Lock()
+	defer label.mutex.Unlock()
+
+	if !(scale > 0) {
+		scale = 1
+	}
+	label.str = str
+	label.pos = pos
+	label.scale = scale
+	label.color = col
+	label.isDirty = true
+}
+
+// Bounds of this label.
+func (label *Label) Bounds() pixel.Rect {
+	label.mutex.Lock()
+	defer label.mutex.Unlock()
+
+	label._UpdateIfDirty()
+	b := label.txt.Bounds()
+	return pixel.Rect{
+		Min: label.pos.Add(b.Min.Scaled(label.scale)),
+		Max: label.pos.Add(b.Max.Scaled(label.scale)),
+	}
+}
+
+// Draw Label.
+func (label *Label) Draw(t pixel.Target) {
+	// lock before accessing txt
+	label.mutex.Lock()
+	defer label.mutex.Unlock()
+
+	label._UpdateIfDirty()
+	label.txt.Draw(t, pixel.IM.Scaled(pixel.ZV, label.scale).Moved(label.pos))
+}
+
+// unexported
+func (label *Label) _UpdateIfDirty() {
+	if !label.isDirty {
+		return
+	}
+	label.isDirty = false
+
+	// text label (a state machine)
+	if label.txt == nil { // lazy creation
+		label.txt = text.New(pixel.ZV, label.atlas)
+	}
+	txt := label.txt
+	txt.Clear()
+
+	txt.Color = label.color
+	txt.WriteString(label.str)
+}
+
+// -------------------------------------------------------------------------
+// Sprite
+
+// Sprite is an image drawn centered at a position, scaled and rotated.
+type Sprite struct {
+	sprite *pixel.Sprite // shared variable
+	mutex  sync.Mutex    // synchronize
+	//
+	pos    pixel.Vec
+	scale  float64
+	degree float64 // counterclockwise
+}
+
+// NewSprite is a constructor.
+func NewSprite(img image.Image, pos pixel.Vec, scale, degree float64) *Sprite {
+	sprite := &Sprite{}
+	sprite.SetImage(img)
+	sprite.Set(pos, scale, degree)
+	return sprite
+}
+
+// SetImage replaces what this sprite draws.
+func (sprite *Sprite) SetImage(img image.Image) {
+	pic := pixel.PictureDataFromImage(img)
+
+	sprite.mutex.Lock()
+	defer sprite.mutex.Unlock()
+
+	sprite.sprite = pixel.NewSprite(pic, pic.Bounds())
+}
+
+// Set where this sprite is, how big it is, and how many degrees it's rotated counterclockwise.
+// A scale of 0 or less is taken as 1.
+func (sprite *Sprite) Set(pos pixel.Vec, scale, degree float64) {
+	sprite.mutex.Lock()
+	defer sprite.mutex.Unlock()
+
+	if !(scale > 0) {
+		scale = 1
+	}
+	sprite.pos = pos
+	sprite.scale = scale
+	sprite.degree = degree
+}
+
+// Bounds of this sprite, around it even if it's rotated.
+func (sprite *Sprite) Bounds() pixel.Rect {
+	sprite.mutex.Lock()
+	defer sprite.mutex.Unlock()
+
+	m := sprite._Matrix()
+	frame := sprite.sprite.Frame()
+	frame = frame.Moved(frame.Center().Scaled(-1)) // centered
+	return boundsOf([]pixel.Vec{
+		m.Project(frame.Min), m.Project(frame.Max),
+		m.Project(pixel.V(frame.Min.X, frame.Max.Y)), m.Project(pixel.V(frame.Max.X, frame.Min.Y)),
+	})
+}
+
+// Draw Sprite.
+func (sprite *Sprite) Draw(t pixel.Target) {
+	sprite.mutex.Lock()
+	defer sprite.mutex.Unlock()
+
+	sprite.sprite.Draw(t, sprite._Matrix())
+}
+
+// unexported
+func (sprite *Sprite) _Matrix() pixel.Matrix {
+	return pixel.IM.Scaled(pixel.ZV, sprite.scale).Rotated(pixel.ZV, sprite.degree*math.Pi/180).Moved(sprite.pos)
+}
+
+// boundsOf returns the smallest rectangle around points.
+func boundsOf(points []pixel.Vec) pixel.Rect {
+	if len(points) <= 0 {
+		return pixel.Rect{}
+	}
+	r := pixel.Rect{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		r.Min.X, r.Min.Y = math.Min(r.Min.X, p.X), math.Min(r.Min.Y, p.Y)
+		r.Max.X, r.Max.Y = math.Max(r.Max.X, p.X), math.Max(r.Max.Y, p.Y)
+	}
+	return r
+}
@@ -0,0 +1,266 @@
+package super
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ProfileOp is what's timed by a Profiler.
+type ProfileOp int
+
+// enum ProfileOp
+const (
+	ProfileUpdate ProfileOp = iota // Actor.Update()
+	ProfileDraw                    // Actor.Draw()
+)
+
+// ProfileSort is an order of ActorProfiles, the most expensive first.
+type ProfileSort int
+
+// enum ProfileSort
+const (
+	SortByTotal   ProfileSort = iota // Update and draw times added up.
+	SortByUpdate                     // Update time.
+	SortByDraw                       // Draw time.
+	SortByMax                        // The slowest single call.
+	SortByCalls                      // The number of calls.
+	NProfileSorts                    // The number of orders, not an order.
+)
+
+func (op ProfileOp) String() string {
+	switch op {
+	case ProfileUpdate:
+		return "update"
+	case ProfileDraw:
+		return "draw"
+	}
+	return fmt.Sprintf("ProfileOp(%d)", int(op))
+}
+
+func (s ProfileSort) String() string {
+	switch s {
+	case SortByTotal:
+		return "total"
+	case SortByUpdate:
+		return "update"
+	case SortByDraw:
+		return "draw"
+	case SortByMax:
+		return "max"
+	case SortByCalls:
+		return "calls"
+	}
+	return fmt.Sprintf("ProfileSort(%d)", int(s))
+}
+
+// ActorProfile is how long an actor, or all actors of a type, took since profiling began.
+type ActorProfile struct {
+	Name       string // The type name, or the type name followed by the instance.
+	Type       string
+	Instances  int // The number of instances, when aggregated by type.
+	Updates    int
+	Draws      int
+	UpdateTime time.Duration
+	DrawTime   time.Duration
+	Max        time.Duration // The slowest single call.
+}
+
+// Total is the update time and the draw time added up.
+func (ap ActorProfile) Total() time.Duration {
+	return ap.UpdateTime + ap.DrawTime
+}
+
+// PerFrame returns the average time this took a frame.
+func (ap ActorProfile) PerFrame(frames int) time.Duration {
+	if frames <= 0 {
+		return 0
+	}
+	return ap.Total() / time.Duration(frames)
+}
+
+func (ap *ActorProfile) add(op ProfileOp, d time.Duration) {
+	switch op {
+	case ProfileUpdate:
+		ap.Updates++
+		ap.UpdateTime += d
+	case ProfileDraw:
+		ap.Draws++
+		ap.DrawTime += d
+	}
+	if d > ap.Max {
+		ap.Max = d
+	}
+}
+
+func (ap ActorProfile) less(other ActorProfile, by ProfileSort) bool {
+	var a, b time.Duration
+	switch by {
+	case SortByUpdate:
+		a, b = ap.UpdateTime, other.UpdateTime
+	case SortByDraw:
+		a, b = ap.DrawTime, other.DrawTime
+	case SortByMax:
+		a, b = ap.Max, other.Max
+	case SortByCalls:
+		a, b = time.Duration(ap.Updates+ap.Draws), time.Duration(other.Updates+other.Draws)
+	default:
+		a, b = ap.Total(), other.Total()
+	}
+	if a != b {
+		return a > b
+	}
+	return ap.Name < other.Name
+}
+
+// -------------------------------------------------------------------------
+
+// Profiler times each actor's Update() and Draw(), aggregated by instance.
+// It's off until enabled, and costs next to nothing while off.
+// It is safe to use it concurrently.
+type Profiler struct {
+	mutex     sync.Mutex
+	enabled   bool
+	frames    int
+	instances map[instanceKey]*ActorProfile
+	now       func() time.Time
+	onTimed   func(name string, op ProfileOp, start time.Time, d time.Duration)
+}
+
+// instanceKey identifies an actor; by its pointer if it's a pointer, or else by its index.
+type instanceKey struct {
+	typ reflect.Type
+	ptr uintptr
+	idx int
+}
+
+// NewProfiler is a constructor.
+func NewProfiler() *Profiler {
+	return &Profiler{
+		instances: map[instanceKey]*ActorProfile{},
+		now:       time.Now,
+	}
+}
+
+// SetEnabled starts or stops profiling. What's profiled so far stays until Reset().
+func (p *Profiler) SetEnabled(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.enabled = enabled
+}
+
+// IsEnabled determines whether it's profiling or not.
+func (p *Profiler) IsEnabled() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.enabled
+}
+
+// SetOnTimed sets a callback that's called on every call timed, such as to trace it. A nil removes it.
+// The name is the instance's; ActorProfile.Name.
+func (p *Profiler) SetOnTimed(onTimed func(name string, op ProfileOp, start time.Time, d time.Duration)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.onTimed = onTimed
+}
+
+// Reset forgets all profiles so far.
+func (p *Profiler) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.frames = 0
+	p.instances = map[instanceKey]*ActorProfile{}
+}
+
+// Frame counts a frame, if enabled. It should be called once every frame.
+func (p *Profiler) Frame() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.enabled {
+		p.frames++
+	}
+}
+
+// Frames returns the number of frames profiled so far.
+func (p *Profiler) Frames() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.frames
+}
+
+// Measure calls do() and times it as an op of an actor, if enabled.
+// The index is where the actor is in its list; it tells apart actors that aren't pointers.
+func (p *Profiler) Measure(actor interface{}, index int, op ProfileOp, do func()) {
+	if !p.IsEnabled() {
+		do()
+		return
+	}
+	start := p.now()
+	do()
+	d := p.now().Sub(start)
+
+	key := instanceKey{typ: reflect.TypeOf(actor), idx: index}
+	if rv := reflect.ValueOf(actor); rv.Kind() == reflect.Ptr {
+		key.ptr, key.idx = rv.Pointer(), 0
+	}
+
+	p.mutex.Lock()
+	ap, ok := p.instances[key]
+	if !ok {
+		ap = &ActorProfile{Type: fmt.Sprint(key.typ), Instances: 1}
+		if key.ptr != 0 {
+			ap.Name = fmt.Sprintf("%s@%#x", ap.Type, key.ptr)
+		} else {
+			ap.Name = fmt.Sprintf("%s#%d", ap.Type, key.idx)
+		}
+		p.instances[key] = ap
+	}
+	ap.add(op, d)
+	name, onTimed := ap.Name, p.onTimed
+	p.mutex.Unlock()
+
+	if onTimed != nil {
+		onTimed(name, op, start, d)
+	}
+}
+
+// Profiles returns profiles so far sorted, the most expensive first;
+// of each instance, or of each type with all its instances added up.
+func (p *Profiler) Profiles(byType bool, by ProfileSort) []ActorProfile {
+	p.mutex.Lock()
+	ret := make([]ActorProfile, 0, len(p.instances))
+	types := map[string]int{}
+	for _, ap := range p.instances {
+		if !byType {
+			ret = append(ret, *ap)
+			continue
+		}
+		i, ok := types[ap.Type]
+		if !ok {
+			i = len(ret)
+			types[ap.Type] = i
+			ret = append(ret, ActorProfile{Name: ap.Type, Type: ap.Type})
+		}
+		sum := &ret[i]
+		sum.Instances++
+		sum.Updates += ap.Updates
+		sum.Draws += ap.Draws
+		sum.UpdateTime += ap.UpdateTime
+		sum.DrawTime += ap.DrawTime
+		if ap.Max > sum.Max {
+			sum.Max = ap.Max
+		}
+	}
+	p.mutex.Unlock()
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].less(ret[j], by) })
+	return ret
+}
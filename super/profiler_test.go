@@ -0,0 +1,60 @@
+package super
+
+import (
+	"testing"
+	"time"
+)
+
+type profiled struct{ name string }
+
+func TestProfiler(t *testing.T) {
+	clock := time.Unix(0, 0)
+	p := NewProfiler()
+	p.now = func() time.Time { return clock }
+	work := func(d time.Duration) func() { return func() { clock = clock.Add(d) } }
+
+	a, b, c := &profiled{"a"}, &profiled{"b"}, profiled{"c"}
+	p.Measure(a, 0, ProfileUpdate, work(time.Millisecond)) // off
+	if n := len(p.Profiles(false, SortByTotal)); n != 0 {
+		t.Fatalf("%d profiles while off; want 0", n)
+	}
+
+	p.SetEnabled(true)
+	for i := 0; i < 10; i++ {
+		p.Frame()
+		p.Measure(a, 0, ProfileUpdate, work(1*time.Millisecond))
+		p.Measure(a, 0, ProfileDraw, work(1*time.Millisecond))
+		p.Measure(b, 1, ProfileUpdate, work(3*time.Millisecond))
+		p.Measure(c, 2, ProfileDraw, work(2*time.Millisecond))
+	}
+
+	byInstance := p.Profiles(false, SortByTotal)
+	if len(byInstance) != 3 {
+		t.Fatalf("%d instances profiled; want 3", len(byInstance))
+	}
+	if got := byInstance[0]; got.UpdateTime != 30*time.Millisecond || got.Updates != 10 || got.Type != "*super.profiled" {
+		t.Errorf("the top offender %+v; want b", got)
+	}
+	if got := byInstance[2].Name; got != "super.profiled#2" {
+		t.Errorf("a non-pointer actor named %q", got)
+	}
+	if got := p.Profiles(false, SortByDraw)[0].Name; got != "super.profiled#2" {
+		t.Errorf("the top drawer %q; want c", got)
+	}
+
+	byType := p.Profiles(true, SortByTotal)
+	if len(byType) != 2 {
+		t.Fatalf("%d types profiled; want 2", len(byType))
+	}
+	if got := byType[0]; got.Type != "*super.profiled" || got.Instances != 2 || got.Total() != 50*time.Millisecond {
+		t.Errorf("the top type %+v", got)
+	}
+	if got := byType[0].PerFrame(p.Frames()); got != 5*time.Millisecond {
+		t.Errorf("%v per frame; want 5ms", got)
+	}
+
+	p.Reset()
+	if n := len(p.Profiles(true, SortByTotal)); n != 0 || p.Frames() != 0 {
+		t.Errorf("%d profiles and %d frames after reset", n, p.Frames())
+	}
+}
@@ -0,0 +1,209 @@
+package super
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"github.com/nanitefactory/visual/atlas"
+	"golang.org/x/image/colornames"
+)
+
+// ProfileView lists the most expensive actors a Profiler has found, refreshed every half a second.
+// It is hidden until it's shown. (See SetVisible().)
+type ProfileView struct {
+	txt   *text.Text     // shared variable
+	atlas *text.Atlas    // borrowed atlas for txt
+	imd   *imdraw.IMDraw // shared variable
+	mutex sync.Mutex     // synchronize
+	//
+	profiler *Profiler
+	visible  bool
+	sortBy   ProfileSort
+	byType   bool
+	nRows    int
+	sinceRef float64 // seconds since the last refresh
+	//
+	pos      pixel.Vec
+	anchorX  AnchorX
+	anchorY  AnchorY
+	colorBg  color.Color
+	colorTxt color.Color
+}
+
+// profileViewRefresh is how often a ProfileView gets refreshed in seconds.
+const profileViewRefresh = 0.5
+
+// NewProfileView is a constructor. It lists up to nRows actors.
+func NewProfileView(
+	profiler *Profiler, nRows int, _pos pixel.Vec,
+	_anchorY AnchorY, _anchorX AnchorX, // This is because the order is usually Y then X in spoken language.
+) *ProfileView {
+	return &ProfileView{
+		atlas:    atlas.AtlasASCII18,
+		profiler: profiler,
+		byType:   true,
+		nRows:    nRows,
+		pos:      _pos,
+		anchorX:  _anchorX,
+		anchorY:  _anchorY,
+		colorBg:  color.RGBA{0, 0, 0, 0xc0},
+		colorTxt: colornames.White,
+	}
+}
+
+// SetPos to a position in screen coords.
+func (view *ProfileView) SetPos(pos pixel.Vec, anchorY AnchorY, anchorX AnchorX) {
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	view.pos = pos
+	view.anchorX = anchorX
+	view.anchorY = anchorY
+	view.sinceRef = profileViewRefresh // refresh on the next update
+}
+
+// SetVisible shows or hides this view.
+func (view *ProfileView) SetVisible(visible bool) {
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	view.visible = visible
+	view.sinceRef = profileViewRefresh
+}
+
+// IsVisible determines whether this view is shown or not.
+func (view *ProfileView) IsVisible() bool {
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	return view.visible
+}
+
+// SetSort sets the order of actors listed, and whether they're aggregated by type or listed by instance.
+func (view *ProfileView) SetSort(by ProfileSort, byType bool) {
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	view.sortBy = by
+	view.byType = byType
+	view.sinceRef = profileViewRefresh
+}
+
+// Sort returns the order of actors listed, and whether they're aggregated by type or listed by instance.
+func (view *ProfileView) Sort() (by ProfileSort, byType bool) {
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	return view.sortBy, view.byType
+}
+
+// NextSort moves on to the next order of actors listed.
+func (view *ProfileView) NextSort() {
+	by, byType := view.Sort()
+	view.SetSort((by+1)%NProfileSorts, byType)
+}
+
+// Update refreshes the list every once in a while. Nothing happens while it's hidden.
+func (view *ProfileView) Update(dt float64) {
+	view.mutex.Lock()
+	if !view.visible {
+		view.mutex.Unlock()
+		return
+	}
+	view.sinceRef += dt
+	if view.sinceRef < profileViewRefresh && view.txt != nil {
+		view.mutex.Unlock()
+		return
+	}
+	view.sinceRef = 0
+	by, byType := view.sortBy, view.byType
+	view.mutex.Unlock()
+
+	profiles := view.profiler.Profiles(byType, by)
+	frames := view.profiler.Frames()
+
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	view._Update(profiles, frames)
+}
+
+// Draw ProfileView.
+func (view *ProfileView) Draw(t pixel.Target) {
+	// lock before accessing txt & imdraw
+	view.mutex.Lock()
+	defer view.mutex.Unlock()
+
+	if !view.visible || view.imd == nil {
+		return
+	}
+
+	view.imd.Draw(t)
+	view.txt.Draw(t, pixel.IM)
+}
+
+// unexported
+func (view *ProfileView) _Update(profiles []ActorProfile, frames int) {
+	ms := func(d time.Duration) string { return fmt.Sprintf("%7.3f", float64(d)/float64(time.Millisecond)) }
+	perFrame := func(d time.Duration) time.Duration {
+		if frames <= 0 {
+			return 0
+		}
+		return d / time.Duration(frames)
+	}
+
+	var sb strings.Builder
+	grouping := "instance"
+	if view.byType {
+		grouping = "type"
+	}
+	state := "on"
+	if !view.profiler.IsEnabled() {
+		state = "off"
+	}
+	fmt.Fprintf(&sb, "PROFILE (%s) by %s, sorted by %s, %d frames\n", state, grouping, view.sortBy, frames)
+	fmt.Fprintf(&sb, "%7s %7s %7s %7s  %s\n", "upd ms", "drw ms", "max ms", "calls", "actor") // digits line up, names don't
+	for i, ap := range profiles {
+		if i >= view.nRows {
+			fmt.Fprintf(&sb, "... and %d more\n", len(profiles)-view.nRows)
+			break
+		}
+		name := ap.Name
+		if view.byType && ap.Instances > 1 {
+			name = fmt.Sprintf("%s x%d", name, ap.Instances)
+		}
+		fmt.Fprintf(&sb, "%s %s %s %7d  %s\n",
+			ms(perFrame(ap.UpdateTime)), ms(perFrame(ap.DrawTime)), ms(ap.Max), ap.Updates+ap.Draws, name)
+	}
+	str := strings.TrimSuffix(sb.String(), "\n")
+
+	// text label (a state machine)
+	if view.txt == nil { // lazy creation
+		view.txt = text.New(pixel.ZV, view.atlas)
+	}
+	txt := view.txt
+	txt.Clear()
+
+	AnchorTxt(txt, view.pos, view.anchorX, view.anchorY, str)
+	txt.Color = view.colorTxt
+	txt.Dot.Y += txt.BoundsOf(str).H() - txt.LineHeight // The first line goes on top.
+	txt.Orig = txt.Dot                                  // and the others start where it does.
+	txt.WriteString(str)
+
+	// imdraw (a state machine)
+	if view.imd == nil { // lazy creation
+		view.imd = imdraw.New(nil)
+	}
+	imd := view.imd
+	imd.Clear()
+
+	imd.Color = view.colorBg
+	imd.Push(txt.Bounds().Min, txt.Bounds().Max)
+	imd.Rectangle(0)
+}
@@ -0,0 +1,205 @@
+package super
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/text"
+	"github.com/nanitefactory/visual/atlas"
+	"golang.org/x/image/colornames"
+)
+
+// RuntimeGraph graphs runtime samples over time in rows of heap size, allocation rate, GC pauses and goroutines.
+// Each row is scaled to its own max. It is hidden until it's shown. (See SetVisible().)
+type RuntimeGraph struct {
+	txt   *text.Text     // shared variable
+	atlas *text.Atlas    // borrowed atlas for txt
+	imd   *imdraw.IMDraw // shared variable
+	mutex sync.Mutex     // synchronize
+	//
+	samples func() []RuntimeSample
+	visible bool
+	//
+	size     pixel.Vec // of the graph without labels
+	pos      pixel.Vec
+	anchorX  AnchorX
+	anchorY  AnchorY
+	colorBg  color.Color
+	colorTxt color.Color
+}
+
+// runtimeRow is a row of a RuntimeGraph.
+type runtimeRow struct {
+	name  string
+	color color.Color
+	value func(s RuntimeSample) float64
+	label func(v float64) string
+}
+
+var runtimeRows = []runtimeRow{
+	{"heap", colornames.Deepskyblue,
+		func(s RuntimeSample) float64 { return float64(s.HeapBytes) },
+		func(v float64) string { return fmt.Sprintf("%.1fMB", v/(1<<20)) }},
+	{"alloc", colornames.Orange,
+		func(s RuntimeSample) float64 { return s.AllocRate },
+		func(v float64) string { return fmt.Sprintf("%.1fMB/s", v/(1<<20)) }},
+	{"gc pause", colornames.Red,
+		func(s RuntimeSample) float64 { return float64(s.GCPauseMax) },
+		func(v float64) string { return fmt.Sprintf("%.2fms", v/float64(time.Millisecond)) }},
+	{"goroutines", colornames.Limegreen,
+		func(s RuntimeSample) float64 { return float64(s.Goroutines) },
+		func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+}
+
+// NewRuntimeGraph is a constructor. A graph shows what the samples func returns every frame.
+func NewRuntimeGraph(
+	samples func() []RuntimeSample, size pixel.Vec, _pos pixel.Vec,
+	_anchorY AnchorY, _anchorX AnchorX, // This is because the order is usually Y then X in spoken language.
+) *RuntimeGraph {
+	return &RuntimeGraph{
+		atlas:    atlas.AtlasASCII18,
+		samples:  samples,
+		size:     size,
+		pos:      _pos,
+		anchorX:  _anchorX,
+		anchorY:  _anchorY,
+		colorBg:  color.RGBA{0, 0, 0, 0xc0},
+		colorTxt: colornames.White,
+	}
+}
+
+// SetPos to a position in screen coords.
+func (graph *RuntimeGraph) SetPos(pos pixel.Vec, anchorY AnchorY, anchorX AnchorX) {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph.pos = pos
+	graph.anchorX = anchorX
+	graph.anchorY = anchorY
+}
+
+// SetVisible shows or hides this graph.
+func (graph *RuntimeGraph) SetVisible(visible bool) {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph.visible = visible
+}
+
+// IsVisible determines whether this graph is shown or not.
+func (graph *RuntimeGraph) IsVisible() bool {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	return graph.visible
+}
+
+// Bounds returns the rect of this graph in screen coords.
+func (graph *RuntimeGraph) Bounds() pixel.Rect {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	return anchoredRect(graph.pos, graph.size, graph.anchorY, graph.anchorX)
+}
+
+// Update the graph with the latest samples. Nothing happens while it's hidden.
+func (graph *RuntimeGraph) Update(_ float64) {
+	if !graph.IsVisible() {
+		return
+	}
+	samples := graph.samples()
+
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	graph._Update(samples)
+}
+
+// Draw RuntimeGraph.
+func (graph *RuntimeGraph) Draw(t pixel.Target) {
+	// lock before accessing txt & imdraw
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	if !graph.visible || graph.imd == nil {
+		return
+	}
+
+	graph.imd.Draw(t)
+	graph.txt.Draw(t, pixel.IM)
+}
+
+// unexported
+func (graph *RuntimeGraph) _Update(samples []RuntimeSample) {
+	r := anchoredRect(graph.pos, graph.size, graph.anchorY, graph.anchorX)
+	rowH := r.H() / float64(len(runtimeRows))
+
+	// imdraw (a state machine)
+	if graph.imd == nil { // lazy creation
+		graph.imd = imdraw.New(nil)
+	}
+	imd := graph.imd
+	imd.Clear()
+
+	// text label (a state machine)
+	if graph.txt == nil { // lazy creation
+		graph.txt = text.New(pixel.ZV, graph.atlas)
+	}
+	txt := graph.txt
+	txt.Clear()
+
+	// background
+	imd.Color = graph.colorBg
+	imd.Push(r.Min, r.Max)
+	imd.Rectangle(0)
+
+	// a row each from the top
+	for i, row := range runtimeRows {
+		bottom := r.Max.Y - rowH*float64(i+1)
+		max, last := 0.0, 0.0
+		for _, s := range samples {
+			if v := row.value(s); v > max {
+				max = v
+			}
+		}
+		if len(samples) > 0 {
+			last = row.value(samples[len(samples)-1])
+		}
+
+		// a line from the oldest on the left to the latest on the right
+		if max > 0 && len(samples) > 1 {
+			step := r.W() / float64(len(samples)-1)
+			imd.Color = row.color
+			for j, s := range samples {
+				imd.Push(pixel.V(r.Min.X+step*float64(j), bottom+2+(rowH-4)*row.value(s)/max))
+			}
+			imd.Line(1)
+		}
+
+		txt.Color = row.color
+		txt.Dot = pixel.V(r.Min.X+2, bottom+2)
+		txt.WriteString(fmt.Sprintf("%s %s (max %s)", row.name, row.label(last), row.label(max)))
+	}
+}
+
+// anchoredRect returns a rect of a size, anchored at a position.
+func anchoredRect(pos, size pixel.Vec, anchorY AnchorY, anchorX AnchorX) pixel.Rect {
+	min := pos
+	switch anchorX {
+	case Center:
+		min.X -= size.X / 2
+	case Right:
+		min.X -= size.X
+	}
+	switch anchorY {
+	case Top:
+		min.Y -= size.Y
+	case Middle:
+		min.Y -= size.Y / 2
+	}
+	return pixel.Rect{Min: min, Max: min.Add(size)}
+}
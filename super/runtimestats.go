@@ -0,0 +1,139 @@
+package super
+
+import (
+	"sync"
+	"time"
+)
+
+// RuntimeSample is what the Go runtime has been up to between two samples.
+type RuntimeSample struct {
+	At         time.Time
+	HeapBytes  uint64        // Bytes of live and not yet swept heap objects.
+	AllocRate  float64       // Bytes allocated per second since the last sample.
+	GCCycles   uint64        // GC cycles completed since the last sample.
+	GCPauseMax time.Duration // The longest stop-the-world pause since the last sample.
+	Goroutines int
+}
+
+// runtimeReading is a raw reading that samples are made of, by differences between two.
+type runtimeReading struct {
+	at         time.Time
+	heapBytes  uint64
+	allocBytes uint64 // cumulative
+	gcCycles   uint64 // cumulative
+	goroutines int
+	pauses     pauseReading
+}
+
+// RuntimeSampler samples runtime metrics periodically into a ring buffer.
+// It reads runtime/metrics on Go 1.17 or later, and runtime.ReadMemStats() before that.
+// It's off until enabled, since ReadMemStats() stops the world.
+// It is safe to use it concurrently.
+type RuntimeSampler struct {
+	mutex    sync.Mutex
+	enabled  bool
+	interval float64 // in seconds
+	elapsed  float64
+	last     *runtimeReading
+	samples  []RuntimeSample // ring buffer
+	next     int
+	n        int
+	read     func() runtimeReading
+}
+
+// NewRuntimeSampler is a constructor. It samples every interval and keeps up to capacity samples.
+func NewRuntimeSampler(interval time.Duration, capacity int) *RuntimeSampler {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RuntimeSampler{
+		interval: interval.Seconds(),
+		samples:  make([]RuntimeSample, capacity),
+		read:     readRuntime,
+	}
+}
+
+// SetEnabled starts or stops sampling. Samples so far stay.
+func (rs *RuntimeSampler) SetEnabled(enabled bool) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if enabled && !rs.enabled {
+		rs.last = nil // A gap in between is not a sample.
+		rs.elapsed = rs.interval
+	}
+	rs.enabled = enabled
+}
+
+// IsEnabled determines whether it's sampling or not.
+func (rs *RuntimeSampler) IsEnabled() bool {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	return rs.enabled
+}
+
+// Update samples if it's time to, while enabled.
+func (rs *RuntimeSampler) Update(dt float64) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if !rs.enabled {
+		return
+	}
+	if rs.elapsed += dt; rs.elapsed < rs.interval {
+		return
+	}
+	rs.elapsed = 0
+	rs._Sample()
+}
+
+// Samples returns the samples so far, from the oldest to the latest.
+func (rs *RuntimeSampler) Samples() []RuntimeSample {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	ret := make([]RuntimeSample, rs.n)
+	for i := range ret {
+		ret[i] = rs.samples[(rs.next-rs.n+i+len(rs.samples))%len(rs.samples)]
+	}
+	return ret
+}
+
+// Last returns the latest sample. It's zero if there's none.
+func (rs *RuntimeSampler) Last() RuntimeSample {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if rs.n <= 0 {
+		return RuntimeSample{}
+	}
+	return rs.samples[(rs.next-1+len(rs.samples))%len(rs.samples)]
+}
+
+// unexported
+func (rs *RuntimeSampler) _Sample() {
+	curr := rs.read()
+	prev := rs.last
+	rs.last = &curr
+	if prev == nil { // It takes two readings to make a sample.
+		return
+	}
+
+	sample := RuntimeSample{
+		At:         curr.at,
+		HeapBytes:  curr.heapBytes,
+		GCCycles:   curr.gcCycles - prev.gcCycles,
+		GCPauseMax: curr.pauses.maxSince(prev.pauses),
+		Goroutines: curr.goroutines,
+	}
+	if secs := curr.at.Sub(prev.at).Seconds(); secs > 0 {
+		sample.AllocRate = float64(curr.allocBytes-prev.allocBytes) / secs
+	}
+
+	rs.samples[rs.next] = sample
+	rs.next = (rs.next + 1) % len(rs.samples)
+	if rs.n < len(rs.samples) {
+		rs.n++
+	}
+}
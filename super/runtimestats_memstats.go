@@ -0,0 +1,43 @@
+//go:build !go1.17
+// +build !go1.17
+
+package super
+
+import (
+	"runtime"
+	"time"
+)
+
+// pauseReading is the recent GC pauses.
+type pauseReading struct {
+	numGC   uint32
+	pauseNs [256]uint64 // circular, by numGC
+}
+
+// maxSince returns the longest pause since an earlier reading.
+func (curr pauseReading) maxSince(prev pauseReading) time.Duration {
+	n := curr.numGC - prev.numGC
+	if n > uint32(len(curr.pauseNs)) {
+		n = uint32(len(curr.pauseNs))
+	}
+	max := uint64(0)
+	for i := uint32(0); i < n; i++ {
+		if ns := curr.pauseNs[(curr.numGC-i+255)%256]; ns > max {
+			max = ns
+		}
+	}
+	return time.Duration(max)
+}
+
+func readRuntime() runtimeReading {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return runtimeReading{
+		at:         time.Now(),
+		heapBytes:  ms.HeapAlloc,
+		allocBytes: ms.TotalAlloc,
+		gcCycles:   uint64(ms.NumGC),
+		goroutines: runtime.NumGoroutine(),
+		pauses:     pauseReading{numGC: ms.NumGC, pauseNs: ms.PauseNs},
+	}
+}
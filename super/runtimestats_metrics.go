@@ -0,0 +1,71 @@
+//go:build go1.17
+// +build go1.17
+
+package super
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+var runtimeMetrics = []string{
+	"/memory/classes/heap/objects:bytes",
+	"/gc/heap/allocs:bytes",
+	"/gc/cycles/total:gc-cycles",
+	"/sched/goroutines:goroutines",
+	"/gc/pauses:seconds",
+}
+
+// pauseReading is the cumulative histogram of GC pauses.
+type pauseReading struct {
+	counts  []uint64
+	buckets []float64 // boundaries in seconds; len(counts)+1
+}
+
+// maxSince returns the longest pause since an earlier reading; the upper bound of its bucket.
+func (curr pauseReading) maxSince(prev pauseReading) time.Duration {
+	for i := len(curr.counts) - 1; i >= 0; i-- {
+		n := curr.counts[i]
+		if i < len(prev.counts) {
+			n -= prev.counts[i]
+		}
+		if n == 0 {
+			continue
+		}
+		bound := curr.buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = curr.buckets[i]
+		}
+		return time.Duration(bound * float64(time.Second))
+	}
+	return 0
+}
+
+func readRuntime() runtimeReading {
+	samples := make([]metrics.Sample, len(runtimeMetrics))
+	for i, name := range runtimeMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	r := runtimeReading{at: time.Now()}
+	u64 := func(s metrics.Sample) uint64 {
+		if s.Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return s.Value.Uint64()
+	}
+	r.heapBytes = u64(samples[0])
+	r.allocBytes = u64(samples[1])
+	r.gcCycles = u64(samples[2])
+	r.goroutines = int(u64(samples[3]))
+	if samples[4].Value.Kind() == metrics.KindFloat64Histogram {
+		h := samples[4].Value.Float64Histogram()
+		r.pauses = pauseReading{
+			counts:  append([]uint64(nil), h.Counts...),
+			buckets: h.Buckets,
+		}
+	}
+	return r
+}
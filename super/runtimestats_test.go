@@ -0,0 +1,52 @@
+package super
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRuntimeSampler(t *testing.T) {
+	rs := NewRuntimeSampler(100*time.Millisecond, 3)
+	rs.Update(1) // off
+	if n := len(rs.Samples()); n != 0 {
+		t.Fatalf("%d samples while off; want 0", n)
+	}
+
+	// fake readings of 1MB allocated every 100ms
+	clock := time.Unix(0, 0)
+	alloc := uint64(0)
+	rs.read = func() runtimeReading {
+		clock = clock.Add(100 * time.Millisecond)
+		alloc += 1 << 20
+		return runtimeReading{at: clock, heapBytes: 42, allocBytes: alloc, gcCycles: alloc >> 20, goroutines: 7}
+	}
+
+	rs.SetEnabled(true)
+	for i := 0; i < 12; i++ {
+		rs.Update(0.05) // every other update samples, the first one to begin with
+	}
+	samples := rs.Samples()
+	if len(samples) != 3 {
+		t.Fatalf("%d samples kept; want 3", len(samples))
+	}
+	last := rs.Last()
+	if last != samples[2] || last.At != clock {
+		t.Errorf("the last sample %+v is not the latest", last)
+	}
+	if last.AllocRate != 10<<20 || last.GCCycles != 1 || last.HeapBytes != 42 || last.Goroutines != 7 {
+		t.Errorf("sample %+v; want 10MB/s, a GC cycle, 42 bytes of heap and 7 goroutines", last)
+	}
+
+	// with the real runtime
+	rs = NewRuntimeSampler(0, 10)
+	rs.SetEnabled(true)
+	garbage := [][]byte{}
+	for i := 0; i < 3; i++ {
+		garbage = append(garbage, make([]byte, 1<<20))
+		rs.Update(0)
+	}
+	if last := rs.Last(); last.HeapBytes == 0 || last.Goroutines < 1 || last.AllocRate <= 0 {
+		t.Errorf("real sample %+v", last)
+	}
+	_ = garbage
+}
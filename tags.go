@@ -0,0 +1,194 @@
+package visual
+
+import (
+	"reflect"
+	"sort"
+)
+
+// -------------------------------------------------------------------------
+// Actor flags and tags
+
+// actorMeta is what a visualizer keeps for an actor besides the actor itself.
+// Its zero value is a visible and active actor with no tags.
+type actorMeta struct {
+	hidden   bool
+	inactive bool
+	tags     map[string]bool
+}
+
+// SetVisible lets an actor (or a HUD) be drawn or not, keeping it where it is in order.
+// Flags and tags can be set before an actor is pushed, and are forgotten once it's removed.
+// Actors of types that can't be compared with == are ignored, just like RemoveActor() can't find them.
+func (v *Visualizer) SetVisible(actor Actor, visible bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if m := v._Meta(actor, true); m != nil {
+		m.hidden = !visible
+	}
+}
+
+// IsVisible tells whether an actor (or a HUD) gets drawn. It's true by default.
+func (v *Visualizer) IsVisible(actor Actor) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	m := v._Meta(actor, false)
+	return m == nil || !m.hidden
+}
+
+// SetActive lets an actor (or a HUD) be updated or not, keeping it where it is in order.
+// An inactive actor still gets drawn if it's visible.
+func (v *Visualizer) SetActive(actor Actor, active bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if m := v._Meta(actor, true); m != nil {
+		m.inactive = !active
+	}
+}
+
+// IsActive tells whether an actor (or a HUD) gets updated. It's true by default.
+func (v *Visualizer) IsActive(actor Actor) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	m := v._Meta(actor, false)
+	return m == nil || !m.inactive
+}
+
+// Tag an actor (or a HUD) so that it can be handled along with others of the same tag.
+func (v *Visualizer) Tag(actor Actor, tags ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	m := v._Meta(actor, true)
+	if m == nil {
+		return
+	}
+	if m.tags == nil {
+		m.tags = map[string]bool{}
+	}
+	for _, tag := range tags {
+		m.tags[tag] = true
+	}
+}
+
+// Untag an actor (or a HUD).
+func (v *Visualizer) Untag(actor Actor, tags ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if m := v._Meta(actor, false); m != nil {
+		for _, tag := range tags {
+			delete(m.tags, tag)
+		}
+	}
+}
+
+// Tags of an actor (or a HUD) in lexical order.
+func (v *Visualizer) Tags(actor Actor) []string {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	m := v._Meta(actor, false)
+	if m == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(m.tags))
+	for tag := range m.tags {
+		ret = append(ret, tag)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// ActorsByTag returns actors and then HUDs of a tag, in the order they are updated and drawn.
+func (v *Visualizer) ActorsByTag(tag string) []Actor {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	var ret []Actor
+	v._EachByTag(tag, func(actor Actor, _ *actorMeta) {
+		ret = append(ret, actor)
+	})
+	return ret
+}
+
+// SetVisibleByTag shows or hides all actors and HUDs of a tag at once.
+// It returns how many of them there are.
+func (v *Visualizer) SetVisibleByTag(tag string, visible bool) (n int) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v._EachByTag(tag, func(_ Actor, m *actorMeta) {
+		m.hidden = !visible
+		n++
+	})
+	return n
+}
+
+// SetActiveByTag activates or deactivates all actors and HUDs of a tag at once.
+// It returns how many of them there are.
+func (v *Visualizer) SetActiveByTag(tag string, active bool) (n int) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v._EachByTag(tag, func(_ Actor, m *actorMeta) {
+		m.inactive = !active
+		n++
+	})
+	return n
+}
+
+// Meta of an actor, created if it's not there yet and create is true.
+// It returns nil for an actor that can't be a map key.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _Meta(actor interface{}, create bool) *actorMeta {
+	if actor == nil || !reflect.TypeOf(actor).Comparable() {
+		return nil
+	}
+	m, ok := v.meta[actor]
+	if !ok && create {
+		if v.meta == nil {
+			v.meta = map[interface{}]*actorMeta{}
+		}
+		m = &actorMeta{}
+		v.meta[actor] = m
+	}
+	return m
+}
+
+// ForgetMeta drops flags and tags of an actor removed, unless it's still there as another actor or HUD.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _ForgetMeta(actor interface{}) {
+	if v._Meta(actor, false) == nil {
+		return
+	}
+	for _, a := range v.actors {
+		if a == actor {
+			return
+		}
+	}
+	for _, hud := range v.huds {
+		if hud == actor {
+			return
+		}
+	}
+	delete(v.meta, actor)
+}
+
+// EachByTag calls back with actors and then HUDs of a tag in order.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _EachByTag(tag string, callback func(actor Actor, m *actorMeta)) {
+	for _, actor := range v.actors {
+		if m := v._Meta(actor, false); m != nil && m.tags[tag] {
+			callback(actor, m)
+		}
+	}
+	for _, hud := range v.huds {
+		if m := v._Meta(hud, false); m != nil && m.tags[tag] {
+			callback(hud, m)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package trace records spans and markers in the Trace Event Format of Chrome,
+// which can be inspected with chrome://tracing or https://ui.perfetto.dev.
+//
+// A Recorder records nothing until started, and stops by itself once it's full.
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEvents is the number of events a Recorder holds by default;
+// about 10 seconds of frames at 60 FPS with a few dozen actors profiled.
+const DefaultMaxEvents = 1 << 18
+
+// The only process and thread traced; a visualizer runs its actors on the mainthread.
+const (
+	pid = 1
+	tid = 1
+)
+
+// Event is a single event in the Trace Event Format.
+type Event struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Ph    string                 `json:"ph"`            // "X" for a span, "i" for a marker, "M" for metadata.
+	Ts    float64                `json:"ts"`            // Microseconds since the recorder started.
+	Dur   float64                `json:"dur,omitempty"` // Microseconds. (spans)
+	Scope string                 `json:"s,omitempty"`   // "g", "p" or "t". (markers)
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// Recorder records events. It is safe to use it concurrently.
+type Recorder struct {
+	mutex     sync.Mutex
+	recording bool
+	start     time.Time
+	events    []Event
+	dropped   int
+	// MaxEvents is how many events it holds at most. Events beyond are dropped.
+	MaxEvents int
+}
+
+// NewRecorder is a constructor.
+func NewRecorder() *Recorder {
+	return &Recorder{MaxEvents: DefaultMaxEvents}
+}
+
+// Start recording from scratch. The process gets named after the name given.
+func (r *Recorder) Start(processName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.recording = true
+	r.start = time.Now()
+	r.dropped = 0
+	r.events = []Event{
+		{Name: "process_name", Ph: "M", Pid: pid, Tid: tid, Args: map[string]interface{}{"name": processName}},
+		{Name: "thread_name", Ph: "M", Pid: pid, Tid: tid, Args: map[string]interface{}{"name": "mainthread"}},
+	}
+}
+
+// Stop recording. What's recorded stays until started again.
+func (r *Recorder) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.recording = false
+}
+
+// IsRecording determines whether it's recording or not.
+func (r *Recorder) IsRecording() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.recording
+}
+
+// Len returns the number of events recorded so far, and the number of events dropped since it was full.
+func (r *Recorder) Len() (n, dropped int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.events), r.dropped
+}
+
+// Span records a span of time. Spans nest by time, so a span within another is shown under it.
+func (r *Recorder) Span(name, cat string, start time.Time, dur time.Duration, args map[string]interface{}) {
+	r._Add(Event{Name: name, Cat: cat, Ph: "X", Dur: micro(dur), Args: args}, start)
+}
+
+// Marker records an instant event, such as a resize.
+func (r *Recorder) Marker(name, cat string, at time.Time, args map[string]interface{}) {
+	r._Add(Event{Name: name, Cat: cat, Ph: "i", Scope: "p", Args: args}, at)
+}
+
+// Events returns the events recorded so far.
+func (r *Recorder) Events() []Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Event(nil), r.events...)
+}
+
+// WriteTo writes the events recorded so far in JSON.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	events := r.Events()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	enc := json.NewEncoder(cw)
+	io.WriteString(cw, `{"displayTimeUnit":"ms","traceEvents":[`)
+	for i := range events {
+		if i > 0 {
+			io.WriteString(cw, ",")
+		}
+		if err := enc.Encode(&events[i]); err != nil {
+			return cw.n, err
+		}
+	}
+	io.WriteString(cw, "]}\n")
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// unexported
+func (r *Recorder) _Add(e Event, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.recording {
+		return
+	}
+	if r.MaxEvents > 0 && len(r.events) >= r.MaxEvents {
+		r.dropped++
+		return
+	}
+	e.Ts = micro(at.Sub(r.start))
+	e.Pid, e.Tid = pid, tid
+	r.events = append(r.events, e)
+}
+
+func micro(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// countingWriter counts bytes written, and remembers the first error so that writes can go unchecked.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
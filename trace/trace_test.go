@@ -0,0 +1,56 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	r.MaxEvents = 5
+	r.Marker("ignored", "", time.Now(), nil) // not started
+
+	r.Start("testing")
+	start := time.Now()
+	r.Span("frame", "frame", start, 16*time.Millisecond, nil)
+	r.Span("update", "phase", start.Add(time.Millisecond), 2*time.Millisecond, map[string]interface{}{"actors": 3})
+	r.Marker("resize", "marker", start.Add(5*time.Millisecond), nil)
+	r.Marker("dropped", "marker", start, nil)
+	r.Stop()
+	r.Marker("ignored", "", time.Now(), nil)
+
+	if n, dropped := r.Len(); n != 5 || dropped != 1 {
+		t.Errorf("%d events, %d dropped; want 5, 1", n, dropped)
+	}
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("%d bytes written; counted %d", buf.Len(), n)
+	}
+	var file struct {
+		TraceEvents []Event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	evs := file.TraceEvents
+	if len(evs) != 5 || evs[0].Ph != "M" || evs[2].Name != "frame" || evs[4].Name != "resize" {
+		t.Fatalf("events %+v", evs)
+	}
+	update := evs[3]
+	if update.Ph != "X" || update.Dur != 2000 || update.Ts < 1000 || update.Ts > evs[2].Ts+1000+100 {
+		t.Errorf("update span %+v", update)
+	}
+	if update.Args["actors"] != 3.0 {
+		t.Errorf("args %v", update.Args)
+	}
+	if evs[4].Ph != "i" || evs[4].Scope != "p" {
+		t.Errorf("marker %+v", evs[4])
+	}
+}
@@ -0,0 +1,81 @@
+package visual
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nanitefactory/visual/super"
+)
+
+// -------------------------------------------------------------------------
+// Tracing
+
+// StartTrace starts recording frames in the Trace Event Format of Chrome,
+// to be inspected with chrome://tracing or https://ui.perfetto.dev.
+// A frame is a span with its phases nested in it; handling events, updating, drawing, swapping and waiting on the vsync.
+// Actors are nested in the phases too, while profiling. (See SetProfiling().)
+// Resizes, fullscreen toggles and pauses are marked.
+// It stops by itself after about 10 seconds of frames, when it's full. (F6 starts it, and stops it to a file.)
+func (v *Visualizer) StartTrace() {
+	fullname, _, _ := v.Title()
+	v.tracer.Start(fullname)
+}
+
+// StopTrace stops recording frames and writes them in JSON.
+func (v *Visualizer) StopTrace(w io.Writer) error {
+	v.tracer.Stop()
+	_, err := v.tracer.WriteTo(w)
+	return err
+}
+
+// IsTracing determines whether frames are being recorded or not.
+func (v *Visualizer) IsTracing() bool {
+	return v.tracer.IsRecording()
+}
+
+// unexported
+func (v *Visualizer) _StopTraceToFile() {
+	path := fmt.Sprintf("trace-%s.json", time.Now().Format("20060102-150405"))
+	f, err := os.Create(path)
+	if err == nil {
+		err = v.StopTrace(f)
+		if errClose := f.Close(); err == nil {
+			err = errClose
+		}
+	}
+	if err != nil {
+		v.tracer.Stop()
+		v.logger.Error("trace failed to be written", "file", path, "err", err)
+		return
+	}
+	n, dropped := v.tracer.Len()
+	v.logger.Info("trace written", "file", path, "events", n, "dropped", dropped)
+}
+
+// TraceFrame records the latest frame timed.
+func (v *Visualizer) _TraceFrame() {
+	if !v.tracer.IsRecording() {
+		return
+	}
+	f := v.frames.Last()
+	v.tracer.Span("frame", "frame", f.Start, f.Total, nil)
+	start := f.Start
+	for p, d := range f.Phases {
+		if d > 0 {
+			v.tracer.Span(super.Phase(p).String(), "phase", start, d, nil)
+		}
+		start = start.Add(d)
+	}
+}
+
+// TraceMarker marks an instant in the trace, if tracing.
+func (v *Visualizer) _TraceMarker(name string, args map[string]interface{}) {
+	v.tracer.Marker(name, "marker", time.Now(), args)
+}
+
+// TraceActor records an actor's Update() or Draw() timed by the profiler.
+func (v *Visualizer) _TraceActor(name string, op super.ProfileOp, start time.Time, d time.Duration) {
+	v.tracer.Span(name, op.String(), start, d, nil)
+}
@@ -1,680 +1,1127 @@
-// Package visual provides a simple visualizer system that's based on faiface/pixel.
-package visual
-
-import (
-	"errors"
-	"fmt"
-	"log"
-	"math"
-	"reflect"
-	"sync"
-	"time"
-	"unsafe"
-
-	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
-	glfw "github.com/go-gl/glfw/v3.2/glfw"
-	"github.com/nanitefactory/visual/actors"
-	"github.com/nanitefactory/visual/jukebox"
-	"github.com/nanitefactory/visual/super"
-	"github.com/sqweek/dialog"
-	"golang.org/x/image/colornames"
-)
-
-func init() {
-	fmt.Println() // The standard out is flushed. (This is to prevent bugs regarding windows syscalls.)
-}
-
-// -------------------------------------------------------------------------
-// Itf export (Actor)
-
-// HUD is an Actor positioned in screen-coord.
-type HUD interface {
-	Actor
-	PosOnScreen(width, height float64) // Callback on screen resize from mainthread.
-}
-
-// Actor is what a Visualizer visualizes.
-// Actor updates and draws itself. It acts as a game (virtual) object.
-//
-// The mainthread, called Visualizer,
-// will do what's shown below, every single frame.
-//
-//	func (v *Visualizer) _NextFrame(dt float64) {
-//		// ---------------------------------------------------
-//		// 1. update - calc state of game (virtual) objects each frame
-//		v._Update(dt)
-//		v.fpsw.Poll()
-//
-//		// ---------------------------------------------------
-//		// 2. draw on window
-//		v.window.Clear(v.bg) // clear canvas
-//		v._Draw()            // then draw
-//
-//		// ---------------------------------------------------
-//		// 3. update window - always end with it
-//		v.window.Update()
-//		<-v.vsync
-//	}
-//
-type Actor interface {
-	Drawer
-	Updater
-}
-
-// Drawer draws itself on a target canvas.
-//
-// The mainthread will do what's shown below every single frame.
-//
-//	// Canvas a game (virtual) world
-//	t.SetMatrix(v.camera.Transform())
-//
-//	// For all actors, Draw() in an order.
-//	for i := range v.actors {
-//		v.actors[i].Draw(t)
-//	}
-//
-type Drawer interface {
-	// Draw obligatorily invoked by Visualizer on mainthread.
-	Draw(t pixel.Target)
-}
-
-// Updater updates itself with the delta time given, every frame on mainthread.
-//
-// The mainthread will do what's shown below every single frame.
-//
-//	// For all actors, Update() in an order.
-//	for i := range v.actors {
-//		v.actors[i].Update(dt)
-//	}
-//
-type Updater interface {
-	// Update obligatorily invoked by Visualizer on mainthread.
-	Update(dt float64)
-}
-
-// -------------------------------------------------------------------------
-// Config
-
-// Config is just an argument of NewVisualizer() that defines a new visualizer.
-type Config struct {
-	Bg                  pixel.RGBA
-	OnDrawn             func(t pixel.Target)
-	OnUpdated           func(dt float64)
-	OnResized           func(width float64, height float64)
-	OnPaused            func()
-	OnResumed           func()
-	OnClose             func()
-	OnHandlingEvents    func(dt float64, window *pixelgl.Window)
-	OnLogging           func(args ...interface{})
-	WinCentered         bool
-	Undecorated         bool
-	Title               string
-	Version             string
-	Width               float64
-	Height              float64
-	WinWidth            float64
-	WinHeight           float64
-	InitialZoomLevel    float64
-	InitialRotateDegree float64
-}
-
-// PosCenterGame returns the world center in game position.
-func (c Config) PosCenterGame() pixel.Vec {
-	return pixel.V(c.Width/2, c.Height/2)
-}
-
-// -------------------------------------------------------------------------
-// Visualizer
-
-// Visualizer is a mainthread that visualizes stuff.
-//
-// Visualizer manages:
-//  1. A window
-//  2. Actors; General Actors or HUDs
-//  3. A game-like visualizer system along with vsync/fps/dt/camera
-//
-// Inputs handled by Visualizer by default: Esc, Tab, Enter, Space, Arrows, Left click, Wheeling, Ctrl+M and Ctrl+Click
-//
-type Visualizer struct { // also called a game
-	// something system, something runtime
-	window *pixelgl.Window // lazy init
-	bg     pixel.RGBA
-	camera *super.Camera // lazy init
-	fpsw   *actors.FPSWatch
-	dtw    super.DtWatch
-	vsync  <-chan time.Time // lazy init
-	// game (visualizer) state
-	isTitleChanged bool
-	// drawings
-	mutex      sync.Mutex // actors must be locked up
-	actors     []Actor
-	huds       []HUD
-	explosions *actors.Explosions
-	// callbacks
-	onDrawn          func(t pixel.Target)
-	onUpdated        func(dt float64)
-	onResized        func(width float64, height float64)
-	onPaused         func()
-	onResumed        func()
-	onClose          func()
-	onHandlingEvents func(dt float64, window *pixelgl.Window)
-	onLogging        func(args ...interface{})
-	// other initial user settings
-	winCentered         bool
-	undecorated         bool
-	title               string
-	version             string
-	width               float64
-	height              float64
-	winWidth            float64 // The screen width, not the game width.
-	winHeight           float64
-	initialZoomLevel    float64
-	initialRotateDegree float64
-}
-
-// NewVisualizer is a constructor.
-func NewVisualizer(cfg Config, optionalHUDs []HUD, generalActors ...Actor) *Visualizer {
-	if optionalHUDs == nil {
-		optionalHUDs = []HUD{}
-	}
-	v := Visualizer{
-		bg:   cfg.Bg,
-		fpsw: actors.NewFPSWatchSimple(pixel.V(cfg.WinWidth, cfg.WinHeight), super.Top, super.Right),
-		actors: func() []Actor { // Actors in game coords. (general actors)
-			ret := make([]Actor, len(generalActors))
-			for i := range generalActors {
-				ret[i] = generalActors[i]
-			}
-			return ret
-		}(),
-		huds: func() []HUD { // Actors in screen coords. (HUDs)
-			ret := make([]HUD, len(optionalHUDs))
-			for i := range optionalHUDs {
-				ret[i] = optionalHUDs[i]
-			}
-			return ret
-		}(),
-		explosions:          actors.NewExplosions(cfg.Width, cfg.Height, nil, 4),
-		onPaused:            cfg.OnPaused,
-		onResumed:           cfg.OnResumed,
-		onDrawn:             cfg.OnDrawn,
-		onUpdated:           cfg.OnUpdated,
-		onResized:           cfg.OnResized,
-		onClose:             cfg.OnClose,
-		onHandlingEvents:    cfg.OnHandlingEvents,
-		onLogging:           cfg.OnLogging,
-		winCentered:         cfg.WinCentered,
-		undecorated:         cfg.Undecorated,
-		title:               cfg.Title,
-		version:             cfg.Version,
-		width:               cfg.Width,
-		height:              cfg.Height,
-		winWidth:            cfg.WinWidth,
-		winHeight:           cfg.WinHeight,
-		initialZoomLevel:    cfg.InitialZoomLevel,
-		initialRotateDegree: cfg.InitialRotateDegree,
-	}
-
-	// This (so-called jukebox) will be finalized (cleaned-up) when the window gets closed.
-	if err := jukebox.Initialize(); err != nil {
-		v.logPrintln("This function successfully returns, though there was an error: ", err)
-	}
-
-	return &v
-}
-
-// -------------------------------------------------------------------------
-// Exported methods
-
-// PushActors to this visualizer.
-func (v *Visualizer) PushActors(actors ...Actor) {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	v.actors = append(v.actors, actors...)
-}
-
-// PopActor of this visualizer.
-func (v *Visualizer) PopActor() Actor {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	pop := v.actors[len(v.actors)-1]
-	v.actors = v.actors[:len(v.actors)-1]
-	return pop
-}
-
-// RemoveActor of this visualizer.
-// Time complexity is O(N); N is the number of actors available in this visualizer.
-func (v *Visualizer) RemoveActor(thisGuyGetsRemoved Actor) (removedIndeed bool) {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	for i, actorFound := range v.actors {
-		if actorFound == thisGuyGetsRemoved {
-			v.actors = append(v.actors[:i], v.actors[i+1:]...)
-			return true
-		}
-	}
-	return false
-}
-
-// PushHUDs to this visualizer. (HUD: Screen-positioned Actor.)
-func (v *Visualizer) PushHUDs(actorHUDs ...HUD) {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	v.huds = append(v.huds, actorHUDs...)
-}
-
-// PopHUD of this visualizer. (HUD: Screen-positioned Actor.)
-func (v *Visualizer) PopHUD() HUD {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	pop := v.huds[len(v.huds)-1]
-	v.huds = v.huds[:len(v.huds)-1]
-	return pop
-}
-
-// RemoveHUD of this visualizer. (HUD: Screen-positioned Actor.)
-// Time complexity is O(N); N is the number of HUDs available in this visualizer.
-func (v *Visualizer) RemoveHUD(thisGuyGetsRemoved HUD) (removedIndeed bool) {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	for i, actorHUDFound := range v.huds {
-		if actorHUDFound == thisGuyGetsRemoved {
-			v.huds = append(v.huds[:i], v.huds[i+1:]...)
-			return true
-		}
-	}
-	return false
-}
-
-// Pause everything going on.
-func (v *Visualizer) Pause() {
-	if v.onPaused != nil {
-		v.onPaused()
-	}
-}
-
-// Resume after pause.
-func (v *Visualizer) Resume() {
-	v.dtw.Dt()
-
-	if v.onResumed != nil {
-		v.onResumed()
-	}
-}
-
-// Close this visualizer. This function breaks the run loop of this.
-func (v *Visualizer) Close() {
-	v.window.SetClosed(true)
-}
-
-// Title gets the title and the version be displayed in the title bar.
-func (v *Visualizer) Title() (fullname, title, version string) {
-	fullname = v.title + "  (" + v.version + ")" // What's written or what's going to be written in the title bar.
-	title = v.title
-	version = v.version
-	return fullname, title, version
-}
-
-// SetTitle updates the title and the version be displayed in the title bar.
-func (v *Visualizer) SetTitle(title, version string) {
-	if v.title == title && v.version == version { // ol the same
-		return
-	}
-	v.isTitleChanged = true
-	if v.title != title {
-		v.title = title
-	}
-	if v.version != version {
-		v.version = version
-	}
-}
-
-// -------------------------------------------------------------------------
-// Unexported self-updating methods - engine encapsulated
-
-// Draw instructs this visualizer to draw its Actors.
-func (v *Visualizer) _Draw() {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	// The target canvas Draw() draws on is called t.
-	var t pixel.BasicTarget
-	t = v.window
-
-	// ---------------------------------------------------
-	// 1. canvas a game world
-	t.SetMatrix(v.camera.Transform())
-
-	// Draw() all general actors in order.
-	for i := range v.actors {
-		v.actors[i].Draw(t)
-	}
-
-	// Default general actor gets placed after custom ones above.
-	v.explosions.Draw(t)
-
-	// Custom action after all general actors got drawn.
-	if v.onDrawn != nil {
-		v.onDrawn(t)
-	}
-
-	// ---------------------------------------------------
-	// 2. canvas a screen
-	t.SetMatrix(pixel.IM)
-
-	// Draw()s all HUDs in an order.
-	for i := range v.huds {
-		v.huds[i].Draw(t)
-	}
-
-	// Default HUD.
-	v.fpsw.Draw(t)
-}
-
-// Update instructs this visualizer to update its Actors.
-func (v *Visualizer) _Update(dt float64) {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	// The camera would and should update every frame.
-	v.camera.Update(dt)
-
-	// All general actors Update() in order.
-	for i := range v.actors {
-		v.actors[i].Update(dt)
-	}
-
-	// Default general actor gets placed after custom ones above.
-	v.explosions.Update(dt)
-
-	// All HUDs Update() in order.
-	for i := range v.huds {
-		v.huds[i].Update(dt)
-	}
-
-	// Default HUD.
-	v.fpsw.Update(dt)
-
-	// Custom action after that all actors got updated.
-	if v.onUpdated != nil {
-		v.onUpdated(dt)
-	}
-}
-
-func (v *Visualizer) _OnResize(width, height float64) {
-	v.camera.SetScreenBound(pixel.R(0, 0, width, height))
-
-	// Position our actors in screen coords.
-	for i := range v.huds { // All huds(actors) PosOnScreen() in order.
-		v.huds[i].PosOnScreen(width, height)
-	}
-
-	// Default HUD.
-	v.fpsw.PosOnScreen(width, height)
-
-	// Custom action on resized.
-	if v.onResized != nil {
-		v.onResized(width, height)
-	}
-}
-
-// unexported because the lazy dude should be handled with care (for not guaranteeing the safety)
-func (v *Visualizer) _SetFullScreenMode(on bool) {
-	if on {
-		monitor := pixelgl.PrimaryMonitor()
-		width, height := monitor.Size()
-		v.window.SetMonitor(monitor)
-		go func(width, height float64) {
-			v._OnResize(width, height)
-		}(width, height)
-	} else if !on { // off
-		v.window.SetMonitor(nil)
-	} else {
-		panic(errors.New("it may be thread"))
-	}
-}
-
-// -------------------------------------------------------------------------
-// Trivial dependency
-
-func (v *Visualizer) logPrintln(args ...interface{}) {
-	if v.onLogging != nil {
-		v.onLogging(args...)
-	}
-	log.Println(args...)
-}
-
-// -------------------------------------------------------------------------
-// Read only getter method(s)
-
-// WindowDeep is a hacky way to access `glfw.Window`.
-// It returns (window *glfw.Window) which is an unexported member inside a (*pixelgl.Window).
-func (v *Visualizer) _WindowDeep() (baseWindow *glfw.Window) {
-	return *(**glfw.Window)(unsafe.Pointer(reflect.Indirect(reflect.ValueOf(v.window)).FieldByName("window").UnsafeAddr()))
-}
-
-// -------------------------------------------------------------------------
-// Run on mainthread - EP
-
-// Run the game window and its event loop on mainthread.
-// This function must be called from the main function of an
-// application so that it can work on the context of OpenGL.
-func (v *Visualizer) Run() {
-	pixelgl.Run(func() {
-		v._RunLazyInit()
-		v._RunEventLoop()
-	})
-}
-
-func (v *Visualizer) _RunLazyInit() {
-	// This window will show up as soon as it is created.
-	win, err := pixelgl.NewWindow(pixelgl.WindowConfig{
-		Title:       func(a, b, c string) string { return a }(v.Title()),
-		Bounds:      pixel.R(0, 0, v.winWidth, v.winHeight),
-		Monitor:     nil,
-		Resizable:   true,
-		Undecorated: v.undecorated,
-		VSync:       false,
-	})
-	if err != nil {
-		panic(err)
-	}
-	win.SetSmooth(true)
-
-	if v.winCentered {
-		MoveWindowToCenterOfPrimaryMonitor := func(win *pixelgl.Window) {
-			vmodes := pixelgl.PrimaryMonitor().VideoModes()
-			vmodesLast := vmodes[len(vmodes)-1]
-			biggestResolution := pixel.R(0, 0, float64(vmodesLast.Width), float64(vmodesLast.Height))
-			win.SetPos(biggestResolution.Center().Sub(win.Bounds().Center()))
-		}
-		MoveWindowToCenterOfPrimaryMonitor(win)
-	}
-
-	// lazy init vars
-	v.window = win
-	v.camera = super.NewCamera(pixel.V(v.width/2, v.height/2), v.window.Bounds())
-
-	// register callback
-	windowGL := v._WindowDeep()
-	windowGL.SetSizeCallback(func(_ *glfw.Window, width int, height int) {
-		v._OnResize(float64(width), float64(height))
-	})
-	windowGL.SetCloseCallback(func(w *glfw.Window) {
-		err := jukebox.Finalize()
-		if err != nil {
-			v.logPrintln(err)
-		}
-		if v.onClose != nil {
-			v.onClose()
-		}
-	})
-
-	// time manager
-	v.vsync = time.Tick(time.Second / 120)
-	v.fpsw.Start()
-	v.dtw.Start()
-
-	// so-called loading
-	{
-		v.window.Clear(colornames.Brown)
-		txt := text.New(v.window.Bounds().Center() /* screenCenter */, AtlasASCII36())
-		txt.WriteString("Loading...")
-		txt.Draw(v.window, pixel.IM)
-		v.window.Update()
-	}
-	v._NextFrame(v.dtw.Dt()) // Give it a blood pressure.
-	v._NextFrame(v.dtw.Dt()) // Now the oxygenated blood will start to pump through its vein.
-	// Do whatever you want after that...
-
-	// from user setting
-	v._OnResize(float64(v.winWidth), float64(v.winHeight))
-	v.camera.Zoom(float64(v.initialZoomLevel))
-	v.camera.Rotate(v.initialRotateDegree)
-}
-
-func (v *Visualizer) _RunEventLoop() {
-	for v.window.Closed() != true { // Your average event loop in mainthread.
-		// Notice that all function calls as go routine are non-blocking, but the others will block the mainthread.
-
-		// ---------------------------------------------------
-		// 0. dt
-		dt := v.dtw.Dt()
-
-		// ---------------------------------------------------
-		// 1. handling events
-		v._HandleEvents(dt)
-
-		// ---------------------------------------------------
-		// 2. move on
-		v._NextFrame(dt)
-
-	} // for
-} // func
-
-func (v *Visualizer) _HandleEvents(dt float64) {
-	// Notice that all function calls as go routine are non-blocking, but the others will block the mainthread.
-
-	// custom event handler
-	if v.onHandlingEvents != nil {
-		v.onHandlingEvents(dt, v.window)
-	}
-
-	// system
-	if v.window.JustReleased(pixelgl.KeyEscape) {
-		v.window.SetClosed(true)
-	}
-	if v.window.JustReleased(pixelgl.KeySpace) {
-		v.Pause()
-		dialog.Message("%s", "Pause").Title("PPAP").Info()
-		v.Resume()
-	}
-	if v.window.JustReleased(pixelgl.KeyTab) {
-		if v.window.Monitor() == nil {
-			v._SetFullScreenMode(true)
-		} else {
-			v._SetFullScreenMode(false)
-		}
-	}
-
-	// "distracting" music
-	if v.window.JustReleased(pixelgl.KeyM) {
-		if v.window.Pressed(pixelgl.KeyLeftControl) { // because annoying stuff
-			if !jukebox.IsPlaying() {
-				// The purpose of this crappy music: the music works like those beep sounds out of patient monitors.
-				// When it slows down, we at least get an idea that something isn't going quite smoothly.
-				jukebox.Play()
-			}
-		}
-	}
-
-	// click or ctrl+click
-	if v.window.JustReleased(pixelgl.MouseButtonLeft) {
-		posWin := v.window.MousePosition()
-		posGame := v.camera.Unproject(posWin)
-		go func() {
-			v.explosions.ExplodeAt(pixel.V(posGame.X, posGame.Y), pixel.V(10, 10))
-		}()
-		if v.window.Pressed(pixelgl.KeyLeftControl) { // because annoying stuff
-			// strTitle := fmt.Sprint(posGame.X, ", ", posGame.Y) //
-			strDlg := fmt.Sprint(
-				"camera angle in degree: ", (v.camera.Angle()/math.Pi)*180, "\r\n", "\r\n",
-				"camera coordinates: ", v.camera.XY().X, v.camera.XY().Y, "\r\n", "\r\n",
-				"game clock: ", v.dtw.GetTimeStarted(), "\r\n", "\r\n",
-				"mouse click coords in screen pos: ", posWin.X, posWin.Y, "\r\n", "\r\n",
-				"mouse click coords in game pos: ", posGame.X, posGame.Y,
-			)
-			go func() {
-				fmt.Println() // this line resolves a syscall bug
-				// v.window.SetTitle(strTitle) //
-				dialog.Message("%s", strDlg).Title("MouseButtonLeft").Info()
-			}()
-		}
-	}
-
-	// camera
-	if v.window.JustReleased(pixelgl.KeyEnter) {
-		go func() {
-			v.camera.Rotate(-90)
-		}()
-	}
-	if v.window.Pressed(pixelgl.KeyRight) {
-		go func(dt float64) { // This camera will go diagonal while the case is in middle of rotating the camera.
-			v.camera.Move(pixel.V(1000*dt, 0).Rotated(-v.camera.Angle()))
-		}(dt)
-	}
-	if v.window.Pressed(pixelgl.KeyLeft) {
-		go func(dt float64) {
-			v.camera.Move(pixel.V(-1000*dt, 0).Rotated(-v.camera.Angle()))
-		}(dt)
-	}
-	if v.window.Pressed(pixelgl.KeyUp) {
-		go func(dt float64) {
-			v.camera.Move(pixel.V(0, 1000*dt).Rotated(-v.camera.Angle()))
-		}(dt)
-	}
-	if v.window.Pressed(pixelgl.KeyDown) {
-		go func(dt float64) {
-			v.camera.Move(pixel.V(0, -1000*dt).Rotated(-v.camera.Angle()))
-		}(dt)
-	}
-	{ // if scrolled
-		zoomLevel := v.window.MouseScroll().Y
-		go func() {
-			v.camera.Zoom(zoomLevel)
-		}()
-	}
-}
-
-func (v *Visualizer) _NextFrame(dt float64) {
-	// ---------------------------------------------------
-	// 1. update - calc state of game objects each frame
-	v._Update(dt)
-	v.fpsw.Poll()
-
-	// ---------------------------------------------------
-	// 2. draw on window
-	v.window.Clear(v.bg) // clear canvas
-	v._Draw()            // then draw
-
-	// ---------------------------------------------------
-	// 3. update title bar
-	if v.isTitleChanged {
-		v.isTitleChanged = false
-		displayed, _, _ := v.Title()
-		v.window.SetTitle(displayed)
-	}
-
-	// ---------------------------------------------------
-	// 4. update window - always end with it
-	v.window.Update()
-	<-v.vsync
-}
+// Package visual provides a simple visualizer system that's based on faiface/pixel.
+package visual
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/faiface/mainthread"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	glfw "github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/nanitefactory/visual/actors"
+	"github.com/nanitefactory/visual/atlas"
+	"github.com/nanitefactory/visual/drawproto"
+	"github.com/nanitefactory/visual/jukebox"
+	"github.com/nanitefactory/visual/logger"
+	"github.com/nanitefactory/visual/metrics"
+	"github.com/nanitefactory/visual/mirror"
+	"github.com/nanitefactory/visual/remote"
+	"github.com/nanitefactory/visual/replay"
+	"github.com/nanitefactory/visual/stream"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/trace"
+	"github.com/sqweek/dialog"
+	"golang.org/x/image/colornames"
+)
+
+func init() {
+	fmt.Println() // The standard out is flushed. (This is to prevent bugs regarding windows syscalls.)
+}
+
+// -------------------------------------------------------------------------
+// Itf export (Actor)
+
+// HUD is an Actor positioned in screen-coord.
+type HUD interface {
+	Actor
+	PosOnScreen(width, height float64) // Callback on screen resize from mainthread.
+}
+
+// Actor is what a Visualizer visualizes.
+// Actor updates and draws itself. It acts as a game (virtual) object.
+//
+// The mainthread, called Visualizer,
+// will do what's shown below, every single frame.
+//
+//	func (v *Visualizer) _NextFrame(dt float64) {
+//		// ---------------------------------------------------
+//		// 1. update - calc state of game (virtual) objects each frame
+//		v._Update(dt)
+//		v.fpsw.Poll()
+//
+//		// ---------------------------------------------------
+//		// 2. draw on window
+//		v.window.Clear(v.bg) // clear canvas
+//		v._Draw()            // then draw
+//
+//		// ---------------------------------------------------
+//		// 3. update window - always end with it
+//		v.window.Update()
+//		<-v.vsync
+//	}
+type Actor interface {
+	Drawer
+	Updater
+}
+
+// Drawer draws itself on a target canvas.
+//
+// The mainthread will do what's shown below every single frame.
+//
+//	// Canvas a game (virtual) world
+//	t.SetMatrix(v.camera.Transform())
+//
+//	// For all actors, Draw() in an order.
+//	for i := range v.actors {
+//		v.actors[i].Draw(t)
+//	}
+type Drawer interface {
+	// Draw obligatorily invoked by Visualizer on mainthread.
+	Draw(t pixel.Target)
+}
+
+// Updater updates itself with the delta time given, every frame on mainthread.
+//
+// The mainthread will do what's shown below every single frame.
+//
+//	// For all actors, Update() in an order.
+//	for i := range v.actors {
+//		v.actors[i].Update(dt)
+//	}
+type Updater interface {
+	// Update obligatorily invoked by Visualizer on mainthread.
+	Update(dt float64)
+}
+
+// -------------------------------------------------------------------------
+// Config
+
+// Config is just an argument of NewVisualizer() that defines a new visualizer.
+// Zero values fall back on defaults. (See DefaultConfig().)
+// It can also be loaded from a file with LoadConfig().
+type Config struct {
+	Bg                  pixel.RGBA
+	OnDrawn             func(t pixel.Target)
+	OnUpdated           func(dt float64)
+	OnResized           func(width float64, height float64)
+	OnPaused            func()
+	OnResumed           func()
+	OnClose             func()
+	OnHandlingEvents    func(dt float64, window *pixelgl.Window)
+	OnLogging           func(args ...interface{}) // Deprecated: Use Logger instead. It still gets every record as a formatted line. (See logger.Format().)
+	OnExporting         func(tilesDone, tilesTotal int)
+	OnActorPanic        func(p ActorPanic) // Called on the mainthread after a frame in which an actor panicked and got quarantined.
+	WinCentered         bool
+	Undecorated         bool
+	Logger              logger.Logger // Where the visualizer, jukebox and atlas loading log to. logger.Default() if nil.
+	Headless            bool          // Hides the window and stops waiting for vsync. Mostly for testing.
+	FixedDt             float64       // Every frame steps by this in seconds instead of the wall clock, if positive.
+	Seed                int64         // Seeds the randomness of default actors, if non-zero.
+	RecordTo            io.Writer     // Records dt and user inputs of every frame to be replayed later on, if non-nil.
+	ReplayFrom          io.Reader     // Replays what's recorded instead of the wall clock and the window, if non-nil.
+	MetricsAddr         string        // Serves metrics in the Prometheus text format at /metrics on a loopback address such as ":9464", if non-empty.
+	RemoteAddr          string        // Serves a JSON API to control the visualizer on a loopback address such as ":9465", if non-empty. (See RemoteAddr().)
+	DrawAddr            string        // Serves the drawing protocol on "unix:/path/to/socket" or a loopback address such as ":9466", if non-empty. (See DrawAddr().)
+	StreamAddr          string        // Streams frames to browsers on a loopback address such as ":9467", if non-empty. (See StreamAddr().)
+	StreamFPS           float64       // Frames a second streamed at most.
+	LeadAddr            string        // Leads followers on a TCP address such as ":9468", if non-empty. (See LeadAddr() and the mirror package.)
+	FollowAddr          string        // Follows a leader at a TCP address such as "192.168.0.10:9468", if non-empty.
+	Viewport            pixel.Vec     // Where the bottom left of the window is on the screen of the leader, as a part of a video wall.
+	Title               string
+	Version             string
+	Width               float64
+	Height              float64
+	WinWidth            float64
+	WinHeight           float64
+	InitialZoom         float64 // The zoom as a scale; 2 shows everything twice as big. (Overrides InitialZoomLevel.)
+	InitialZoomLevel    float64 // The zoom in steps of 1.2 times each; 1 zooms in by 1.2 and -1 zooms out by 1/1.2.
+	InitialRotateDegree float64
+}
+
+// PosCenterGame returns the world center in game position.
+func (c Config) PosCenterGame() pixel.Vec {
+	return pixel.V(c.Width/2, c.Height/2)
+}
+
+// -------------------------------------------------------------------------
+// Visualizer
+
+// nFramesTimed is the number of frames FrameStats() sums up; about 5 seconds at 60 FPS.
+const nFramesTimed = 300
+
+// Visualizer is a mainthread that visualizes stuff.
+//
+// Visualizer manages:
+//  1. A window
+//  2. Actors; General Actors or HUDs
+//  3. A game-like visualizer system along with vsync/fps/dt/camera
+//
+// Inputs handled by Visualizer by default: Esc, Tab, Enter, Space, Arrows, Left click, Wheeling, Ctrl+M, Ctrl+Click, Backtick, F1, PageUp, PageDown, F3, F4, F5, F6 and F7
+type Visualizer struct { // also called a game
+	// something system, something runtime
+	window *pixelgl.Window // lazy init
+	input  Input           // lazy init; the window by default
+	bg     pixel.RGBA
+	camera *super.Camera // lazy init
+	fpsw   *actors.FPSWatch
+	fgraph *actors.FrameGraph
+	prof   *super.Profiler
+	pview  *actors.ProfileView
+	insp   *actors.Inspector
+	cons   *actors.Console
+	tracer *trace.Recorder
+	rstats *super.RuntimeSampler
+	rgraph *actors.RuntimeGraph
+	// metrics
+	metricsAddr   string
+	metricsServer *metrics.Server
+	frameHist     *metrics.Histogram
+	started       time.Time
+	dtw           super.DtWatch
+	frames        *super.FrameTimer
+	vsync         <-chan time.Time // lazy init
+	seed          int64
+	timeScale     float64 // guarded by the mutex
+	// remote control
+	remoteAddr   string
+	remoteServer *remote.Server
+	tasks        *remote.Queue // to be run on the mainthread
+	// external drawing
+	drawAddr   string
+	drawServer *drawproto.Server
+	drawn      map[string]map[string]drawnActor // by clients and IDs; guarded by the mutex
+	// streaming
+	streamAddr   string
+	streamFPS    float64
+	streamServer *remote.Server
+	streamer     *stream.Broadcaster
+	viewerInput  *mergedInput // lazy init; what viewers of the stream input, merged into the input
+	// mirroring
+	leadAddr   string
+	followAddr string
+	viewport   pixel.Vec
+	leader     *mirror.Leader
+	follower   *mirror.Follower
+	// record and replay
+	recordTo          io.Writer
+	replayFrom        io.Reader
+	recording         *replay.Writer
+	nRecorded         int
+	replaying         *replayInput
+	inputBeforeReplay Input
+	// game (visualizer) state
+	isTitleChanged bool
+	paused         bool               // guarded by the mutex
+	loadedState    *savedState        // guarded by the mutex; to be restored on the mainthread
+	hudsToPlace    []HUD              // guarded by the mutex; to be positioned on the mainthread
+	shownCamera    *super.CameraState // guarded by the mutex; as of the last frame
+	shownClock     time.Time          // guarded by the mutex; when the game clock started as of the last frame
+	// drawings
+	mutex      sync.Mutex // actors must be locked up
+	actors     []Actor
+	huds       []HUD
+	explosions *actors.Explosions
+	meta       map[interface{}]*actorMeta // flags and tags
+	// quarantine of actors panicked
+	quarantine     map[interface{}]*ActorPanic
+	quarantined    []*ActorPanic // in the order they panicked
+	panicsToReport []ActorPanic  // to Config.OnActorPanic
+	// callbacks
+	onDrawn          func(t pixel.Target)
+	onUpdated        func(dt float64)
+	onResized        func(width float64, height float64)
+	onPaused         func()
+	onResumed        func()
+	onClose          func()
+	onHandlingEvents func(dt float64, window *pixelgl.Window)
+	onExporting      func(tilesDone, tilesTotal int)
+	onActorPanic     func(p ActorPanic)
+	logger           logger.Logger
+	// other initial user settings
+	winCentered         bool
+	undecorated         bool
+	headless            bool
+	fixedDt             float64
+	title               string
+	version             string
+	width               float64
+	height              float64
+	winWidth            float64 // The screen width, not the game width.
+	winHeight           float64
+	initialZoom         float64
+	initialZoomLevel    float64
+	initialRotateDegree float64
+}
+
+// NewVisualizer is a constructor.
+// It fails if the config is invalid. (See Config.Validate().)
+func NewVisualizer(cfg Config, optionalHUDs []HUD, generalActors ...Actor) (*Visualizer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+	if optionalHUDs == nil {
+		optionalHUDs = []HUD{}
+	}
+	v := Visualizer{
+		bg:   cfg.Bg,
+		fpsw: actors.NewFPSWatchSimple(pixel.V(cfg.WinWidth, cfg.WinHeight), super.Top, super.Right),
+		actors: func() []Actor { // Actors in game coords. (general actors)
+			ret := make([]Actor, len(generalActors))
+			for i := range generalActors {
+				ret[i] = generalActors[i]
+			}
+			return ret
+		}(),
+		huds: func() []HUD { // Actors in screen coords. (HUDs)
+			ret := make([]HUD, len(optionalHUDs))
+			for i := range optionalHUDs {
+				ret[i] = optionalHUDs[i]
+			}
+			return ret
+		}(),
+		explosions:          actors.NewExplosions(cfg.Width, cfg.Height, nil, 4),
+		onPaused:            cfg.OnPaused,
+		onResumed:           cfg.OnResumed,
+		onDrawn:             cfg.OnDrawn,
+		onUpdated:           cfg.OnUpdated,
+		onResized:           cfg.OnResized,
+		onClose:             cfg.OnClose,
+		onHandlingEvents:    cfg.OnHandlingEvents,
+		onExporting:         cfg.OnExporting,
+		onActorPanic:        cfg.OnActorPanic,
+		logger:              cfg.logger(),
+		winCentered:         cfg.WinCentered,
+		undecorated:         cfg.Undecorated,
+		headless:            cfg.Headless,
+		fixedDt:             cfg.FixedDt,
+		seed:                cfg.Seed,
+		recordTo:            cfg.RecordTo,
+		replayFrom:          cfg.ReplayFrom,
+		metricsAddr:         cfg.MetricsAddr,
+		remoteAddr:          cfg.RemoteAddr,
+		drawAddr:            cfg.DrawAddr,
+		streamAddr:          cfg.StreamAddr,
+		streamFPS:           cfg.StreamFPS,
+		leadAddr:            cfg.LeadAddr,
+		followAddr:          cfg.FollowAddr,
+		viewport:            cfg.Viewport,
+		title:               cfg.Title,
+		version:             cfg.Version,
+		width:               cfg.Width,
+		height:              cfg.Height,
+		winWidth:            cfg.WinWidth,
+		winHeight:           cfg.WinHeight,
+		initialZoom:         cfg.InitialZoom,
+		initialZoomLevel:    cfg.InitialZoomLevel,
+		initialRotateDegree: cfg.InitialRotateDegree,
+		timeScale:           1,
+	}
+
+	v.frames = super.NewFrameTimer(nFramesTimed)
+	v.fgraph = actors.NewFrameGraph(v.frames.Stats, pixel.V(nFramesTimed, 100), pixel.V(-2, -30), super.Top, super.Right)
+	v.prof = super.NewProfiler()
+	v.pview = actors.NewProfileView(v.prof, 15, pixel.V(2, -2), super.Top, super.Left)
+	v.insp = actors.NewInspector(v._InspectedItems, 20, pixel.V(-2, -2), super.Top, super.Right)
+	v.cons = actors.NewConsole(200, 0.4)
+	v.tasks = remote.NewQueue(64)
+	v._RegisterBuiltinCommands()
+	v.tracer = trace.NewRecorder()
+	v.prof.SetOnTimed(v._TraceActor)
+	v.frameHist = metrics.NewHistogram(frameSecondsBounds...)
+	v.rstats = super.NewRuntimeSampler(time.Second/10, nFramesTimed)
+	v.rgraph = actors.NewRuntimeGraph(v.rstats.Samples, pixel.V(nFramesTimed, 130), pixel.V(-2-nFramesTimed-4, -30), super.Top, super.Right)
+
+	if cfg.Seed != 0 {
+		v.explosions.Seed(cfg.Seed)
+	}
+
+	if err := atlas.FallbackErr(); err != nil {
+		v.logger.Warn("texts are drawn in a fallback font", "err", err)
+	}
+
+	// This (so-called jukebox) will be finalized (cleaned-up) when the window gets closed.
+	jukebox.SetLogger(v.logger)
+	if err := jukebox.Initialize(); err != nil {
+		v.logger.Error("jukebox failed to initialize; this visualizer goes on without music", "err", err)
+	}
+
+	return &v, nil
+}
+
+// -------------------------------------------------------------------------
+// Exported methods
+
+// PushActors to this visualizer.
+func (v *Visualizer) PushActors(actors ...Actor) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.actors = append(v.actors, actors...)
+}
+
+// PopActor of this visualizer.
+func (v *Visualizer) PopActor() Actor {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	pop := v.actors[len(v.actors)-1]
+	v.actors = v.actors[:len(v.actors)-1]
+	v._Unquarantine(pop, len(v.actors))
+	v._ForgetMeta(pop)
+	return pop
+}
+
+// RemoveActor of this visualizer.
+// Time complexity is O(N); N is the number of actors available in this visualizer.
+func (v *Visualizer) RemoveActor(thisGuyGetsRemoved Actor) (removedIndeed bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for i, actorFound := range v.actors {
+		if actorFound == thisGuyGetsRemoved {
+			v.actors = append(v.actors[:i], v.actors[i+1:]...)
+			v._Unquarantine(thisGuyGetsRemoved, i)
+			v._ForgetMeta(thisGuyGetsRemoved)
+			return true
+		}
+	}
+	return false
+}
+
+// PushHUDs to this visualizer. (HUD: Screen-positioned Actor.)
+func (v *Visualizer) PushHUDs(actorHUDs ...HUD) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.huds = append(v.huds, actorHUDs...)
+}
+
+// PopHUD of this visualizer. (HUD: Screen-positioned Actor.)
+func (v *Visualizer) PopHUD() HUD {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	pop := v.huds[len(v.huds)-1]
+	v.huds = v.huds[:len(v.huds)-1]
+	v._Unquarantine(pop, len(v.actors)+len(v.huds))
+	v._ForgetMeta(pop)
+	return pop
+}
+
+// RemoveHUD of this visualizer. (HUD: Screen-positioned Actor.)
+// Time complexity is O(N); N is the number of HUDs available in this visualizer.
+func (v *Visualizer) RemoveHUD(thisGuyGetsRemoved HUD) (removedIndeed bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for i, actorHUDFound := range v.huds {
+		if actorHUDFound == thisGuyGetsRemoved {
+			v.huds = append(v.huds[:i], v.huds[i+1:]...)
+			v._Unquarantine(thisGuyGetsRemoved, len(v.actors)+i)
+			v._ForgetMeta(thisGuyGetsRemoved)
+			return true
+		}
+	}
+	return false
+}
+
+// Pause everything going on.
+func (v *Visualizer) Pause() {
+	v._TraceMarker("pause", nil)
+
+	if v.onPaused != nil {
+		v.onPaused()
+	}
+}
+
+// Resume after pause.
+func (v *Visualizer) Resume() {
+	v.dtw.Dt()
+	v._TraceMarker("resume", nil)
+
+	if v.onResumed != nil {
+		v.onResumed()
+	}
+}
+
+// SetPaused stops updating general actors until it's set back, while the camera and HUDs are still updated.
+// Pause() or Resume() gets called along with it, if it's not already so.
+func (v *Visualizer) SetPaused(paused bool) {
+	v.mutex.Lock()
+	changed := v.paused != paused
+	v.paused = paused
+	v.mutex.Unlock()
+
+	switch {
+	case changed && paused:
+		v.Pause()
+	case changed:
+		v.Resume()
+	}
+}
+
+// IsPaused determines whether general actors are paused or not. (See SetPaused().)
+func (v *Visualizer) IsPaused() bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.paused
+}
+
+// SetInput replaces where this visualizer reads user inputs from; a VirtualInput for example.
+// A nil sets it back to the window.
+func (v *Visualizer) SetInput(in Input) {
+	v.input = in
+	if in == nil && v.window != nil {
+		v.input = v.window
+	}
+}
+
+// Close this visualizer. This function breaks the run loop of this.
+func (v *Visualizer) Close() {
+	v.window.SetClosed(true)
+}
+
+// Title gets the title and the version be displayed in the title bar.
+func (v *Visualizer) Title() (fullname, title, version string) {
+	fullname = v.title + "  (" + v.version + ")" // What's written or what's going to be written in the title bar.
+	title = v.title
+	version = v.version
+	return fullname, title, version
+}
+
+// SetTitle updates the title and the version be displayed in the title bar.
+func (v *Visualizer) SetTitle(title, version string) {
+	if v.title == title && v.version == version { // ol the same
+		return
+	}
+	v.isTitleChanged = true
+	if v.title != title {
+		v.title = title
+	}
+	if v.version != version {
+		v.version = version
+	}
+}
+
+// -------------------------------------------------------------------------
+// Unexported self-updating methods - engine encapsulated
+
+// Draw instructs this visualizer to draw its Actors.
+func (v *Visualizer) _Draw() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	// The target canvas Draw() draws on is called t.
+	var t pixel.BasicTarget
+	t = v.window
+
+	// ---------------------------------------------------
+	// 1. canvas a game world
+	v._DrawWorld(t, v.camera.Transform())
+	v._DrawInspectedBounds(t, false)
+
+	// ---------------------------------------------------
+	// 2. canvas a screen
+	v._DrawScreen(t)
+	v._DrawInspectedBounds(t, true)
+}
+
+// DrawWorld draws general actors on a target with the given camera matrix.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _DrawWorld(t pixel.BasicTarget, cam pixel.Matrix) {
+	// Canvas a game (virtual) world.
+	t.SetMatrix(cam)
+
+	// Draw() all general actors in order.
+	for i := range v.actors {
+		v._CallActor(v.actors[i], i, super.ProfileDraw, t, func() { v.actors[i].Draw(t) })
+	}
+
+	// Default general actor gets placed after custom ones above.
+	v.prof.Measure(v.explosions, -1, super.ProfileDraw, func() { v.explosions.Draw(t) })
+
+	// Custom action after all general actors got drawn.
+	if v.onDrawn != nil {
+		v.onDrawn(t)
+	}
+}
+
+// DrawScreen draws HUDs on a target in screen coords.
+// The caller is responsible for locking actors up.
+func (v *Visualizer) _DrawScreen(t pixel.BasicTarget) {
+	// Canvas a screen.
+	t.SetMatrix(pixel.IM)
+
+	// Draw()s all HUDs in an order.
+	for i := range v.huds {
+		v._CallActor(v.huds[i], len(v.actors)+i, super.ProfileDraw, t, func() { v.huds[i].Draw(t) })
+	}
+
+	// Default HUD.
+	v.fpsw.Draw(t)
+	v.fgraph.Draw(t)
+	v.rgraph.Draw(t)
+	v.pview.Draw(t)
+	v.insp.Draw(t)
+	v.cons.Draw(t)
+	v._DrawErrorList(t)
+}
+
+// Update instructs this visualizer to update its Actors.
+func (v *Visualizer) _Update(dt float64) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	// The camera would and should update every frame.
+	v.camera.Update(dt)
+
+	// All general actors Update() in order, unless paused.
+	v.prof.Frame()
+	if !v.paused {
+		for i := range v.actors {
+			v._CallActor(v.actors[i], i, super.ProfileUpdate, nil, func() { v.actors[i].Update(dt) })
+		}
+
+		// Default general actor gets placed after custom ones above.
+		v.prof.Measure(v.explosions, -1, super.ProfileUpdate, func() { v.explosions.Update(dt) })
+	}
+
+	// All HUDs Update() in order.
+	for i := range v.huds {
+		v._CallActor(v.huds[i], len(v.actors)+i, super.ProfileUpdate, nil, func() { v.huds[i].Update(dt) })
+	}
+
+	// Default HUD.
+	v.rstats.Update(dt)
+	v.fpsw.Update(dt)
+	v.fgraph.Update(dt)
+	v.rgraph.Update(dt)
+	v.pview.Update(dt)
+	v.insp.Update(dt)
+	v.cons.Update(dt)
+
+	// Custom action after that all actors got updated.
+	if v.onUpdated != nil {
+		v.onUpdated(dt)
+	}
+}
+
+func (v *Visualizer) _OnResize(width, height float64) {
+	v._TraceMarker("resize", map[string]interface{}{"width": width, "height": height})
+	v.camera.SetScreenBound(pixel.R(0, 0, width, height).Moved(v.viewport.Scaled(-1)))
+
+	// Position our actors in screen coords.
+	for i := range v.huds { // All huds(actors) PosOnScreen() in order.
+		v.huds[i].PosOnScreen(width, height)
+	}
+
+	// Default HUD.
+	v.fpsw.PosOnScreen(width, height)
+	v.fgraph.PosOnScreen(width, height)
+	v.rgraph.PosOnScreen(width, height)
+	v.pview.PosOnScreen(width, height)
+	v.insp.PosOnScreen(width, height)
+	v.cons.PosOnScreen(width, height)
+
+	// Custom action on resized.
+	if v.onResized != nil {
+		v.onResized(width, height)
+	}
+}
+
+// unexported because the lazy dude should be handled with care (for not guaranteeing the safety)
+func (v *Visualizer) _SetFullScreenMode(on bool) {
+	v._TraceMarker("fullscreen", map[string]interface{}{"on": on})
+	if on {
+		monitor := pixelgl.PrimaryMonitor()
+		width, height := monitor.Size()
+		v.window.SetMonitor(monitor)
+		go func(width, height float64) {
+			v._OnResize(width, height)
+		}(width, height)
+	} else if !on { // off
+		v.window.SetMonitor(nil)
+	} else {
+		panic(errors.New("it may be thread"))
+	}
+}
+
+// -------------------------------------------------------------------------
+// Read only getter method(s)
+
+// Input returns where this visualizer reads user inputs from.
+func (v *Visualizer) Input() Input {
+	return v.input
+}
+
+// Logger returns where this visualizer logs to.
+func (v *Visualizer) Logger() logger.Logger {
+	return v.logger
+}
+
+// FrameStats returns how long the last few seconds of frames took, phase by phase;
+// handling events, updating, drawing, swapping buffers and waiting on the vsync.
+// It is safe to call this from any goroutine.
+func (v *Visualizer) FrameStats() super.FrameStats {
+	return v.frames.Stats()
+}
+
+// SetFrameGraphVisible shows or hides the frame time graph below the FPS. (F3 toggles it.)
+func (v *Visualizer) SetFrameGraphVisible(visible bool) {
+	v.fgraph.SetVisible(visible)
+}
+
+// SetRuntimeSampling starts or stops sampling runtime metrics every 100ms;
+// heap size, allocation rate, GC pauses and goroutines. (F7 toggles it along with its graph.)
+func (v *Visualizer) SetRuntimeSampling(on bool) {
+	v.rstats.SetEnabled(on)
+}
+
+// RuntimeStats returns the runtime metrics sampled lately, from the oldest to the latest.
+func (v *Visualizer) RuntimeStats() []super.RuntimeSample {
+	return v.rstats.Samples()
+}
+
+// SetRuntimeGraphVisible shows or hides the graph of runtime metrics next to the frame time graph.
+// It graphs nothing unless sampling. (See SetRuntimeSampling().)
+func (v *Visualizer) SetRuntimeGraphVisible(visible bool) {
+	v.rgraph.SetVisible(visible)
+}
+
+// SetProfiling starts or stops timing each actor's Update() and Draw(), along with the overlay listing them.
+// Profiles are reset on start. (F4 toggles it.)
+func (v *Visualizer) SetProfiling(on bool) {
+	if on && !v.prof.IsEnabled() {
+		v.prof.Reset()
+	}
+	v.prof.SetEnabled(on)
+	v.pview.SetVisible(on)
+}
+
+// IsProfiling determines whether actors are being profiled or not.
+func (v *Visualizer) IsProfiling() bool {
+	return v.prof.IsEnabled()
+}
+
+// ActorProfiles returns how long actors took since profiling began, the most expensive first;
+// of each instance, or of each type with all its instances added up.
+// The number of frames profiled is returned along with them.
+func (v *Visualizer) ActorProfiles(byType bool, by super.ProfileSort) (profiles []super.ActorProfile, frames int) {
+	return v.prof.Profiles(byType, by), v.prof.Frames()
+}
+
+// Camera returns the camera of this visualizer. It is nil until the visualizer runs.
+func (v *Visualizer) Camera() *super.Camera {
+	return v.camera
+}
+
+// Project converts a game position to a screen position.
+func (v *Visualizer) Project(gamePos pixel.Vec) (screenPos pixel.Vec) {
+	return v.camera.Transform().Project(gamePos)
+}
+
+// Unproject converts a screen position to a game position.
+func (v *Visualizer) Unproject(screenPos pixel.Vec) (gamePos pixel.Vec) {
+	return v.camera.Unproject(screenPos)
+}
+
+// WindowDeep is a hacky way to access `glfw.Window`.
+// It returns (window *glfw.Window) which is an unexported member inside a (*pixelgl.Window).
+func (v *Visualizer) _WindowDeep() (baseWindow *glfw.Window) {
+	return *(**glfw.Window)(unsafe.Pointer(reflect.Indirect(reflect.ValueOf(v.window)).FieldByName("window").UnsafeAddr()))
+}
+
+// -------------------------------------------------------------------------
+// Run on mainthread - EP
+
+// Run the game window and its event loop on mainthread.
+// This function must be called from the main function of an
+// application so that it can work on the context of OpenGL.
+func (v *Visualizer) Run() {
+	pixelgl.Run(func() {
+		v._RunLazyInit()
+		v._RunEventLoop()
+	})
+}
+
+// RunFrames runs the game window for n frames, then returns the last frame rendered.
+// Just like Run(), this function must be called from the main function of an application.
+// It's meant to be used with Config.Headless, Config.FixedDt and Config.Seed
+// to render the same image every time.
+func (v *Visualizer) RunFrames(n int) (lastFrame *image.RGBA) {
+	v.Drive(func(step func()) {
+		for i := 0; i < n; i++ {
+			step()
+		}
+		lastFrame = v._Capture()
+	})
+	return lastFrame
+}
+
+// Drive runs the game window and lets a script step it frame by frame, instead of the event loop.
+// A single call to step() handles events and moves on to the next frame.
+// Calls to step() after the window is closed are ignored. This function returns when the script returns.
+// Just like Run(), this function must be called from the main function of an application.
+// It's meant to be used along with a VirtualInput and Config.FixedDt for testing.
+func (v *Visualizer) Drive(script func(step func())) {
+	pixelgl.Run(func() {
+		v._RunLazyInit()
+		script(func() {
+			if v.window.Closed() {
+				return
+			}
+			dt := v._BeginFrame()
+			v._HandleEvents(dt)
+			v._NextFrame(dt)
+		})
+		v._StopRecording()
+		v._StopMetrics()
+		v._StopRemote()
+		v._StopDraw()
+		v._StopStream()
+		v._StopMirror()
+	})
+}
+
+func (v *Visualizer) _RunLazyInit() {
+	// This window will show up as soon as it is created.
+	win, err := pixelgl.NewWindow(pixelgl.WindowConfig{
+		Title:       func(a, b, c string) string { return a }(v.Title()),
+		Bounds:      pixel.R(0, 0, v.winWidth, v.winHeight),
+		Monitor:     nil,
+		Resizable:   true,
+		Undecorated: v.undecorated,
+		VSync:       false,
+	})
+	if err != nil {
+		panic(err)
+	}
+	win.SetSmooth(true)
+
+	if v.winCentered {
+		MoveWindowToCenterOfPrimaryMonitor := func(win *pixelgl.Window) {
+			vmodes := pixelgl.PrimaryMonitor().VideoModes()
+			vmodesLast := vmodes[len(vmodes)-1]
+			biggestResolution := pixel.R(0, 0, float64(vmodesLast.Width), float64(vmodesLast.Height))
+			win.SetPos(biggestResolution.Center().Sub(win.Bounds().Center()))
+		}
+		MoveWindowToCenterOfPrimaryMonitor(win)
+	}
+
+	// lazy init vars
+	v.window = win
+	if v.input == nil {
+		v.input = win
+	}
+	v.camera = super.NewCamera(pixel.V(v.width/2, v.height/2), v.window.Bounds())
+
+	// register callback
+	windowGL := v._WindowDeep()
+	if v.headless {
+		mainthread.Call(windowGL.Hide)
+	}
+	windowGL.SetSizeCallback(func(_ *glfw.Window, width int, height int) {
+		v._OnResize(float64(width), float64(height))
+	})
+	windowGL.SetCloseCallback(func(w *glfw.Window) {
+		err := jukebox.Finalize()
+		if err != nil {
+			v.logger.Warn("jukebox failed to finalize", "err", err)
+		}
+		if v.onClose != nil {
+			v.onClose()
+		}
+	})
+
+	// time manager
+	v.vsync = time.Tick(time.Second / 120)
+	if v.fixedDt <= 0 { // The wall clock FPS means nothing when the dt is fixed.
+		v.fpsw.Start()
+	}
+	v.dtw.Start()
+	v.started = time.Now()
+	v._StartRecordAndReplay()
+	v._StartMetrics()
+	v._StartRemote()
+	v._StartDraw()
+	v._StartStream()
+	v._StartMirror()
+
+	// so-called loading
+	{
+		v.window.Clear(colornames.Brown)
+		txt := text.New(v.window.Bounds().Center() /* screenCenter */, AtlasASCII36())
+		txt.WriteString("Loading...")
+		txt.Draw(v.window, pixel.IM)
+		v.window.Update()
+	}
+	v._NextFrame(v._BeginFrame()) // Give it a blood pressure.
+	v._NextFrame(v._BeginFrame()) // Now the oxygenated blood will start to pump through its vein.
+	// Do whatever you want after that...
+
+	// from user setting
+	v._OnResize(float64(v.winWidth), float64(v.winHeight))
+	if v.initialZoom > 0 {
+		v.camera.ZoomTo(v.initialZoom)
+	} else {
+		v.camera.Zoom(float64(v.initialZoomLevel))
+	}
+	v.camera.Rotate(v.initialRotateDegree)
+
+	// from a state loaded
+	v._RestoreLoadedState()
+}
+
+func (v *Visualizer) _RunEventLoop() {
+	for v.window.Closed() != true { // Your average event loop in mainthread.
+		// Notice that all function calls as go routine are non-blocking, but the others will block the mainthread.
+
+		// ---------------------------------------------------
+		// 0. dt
+		dt := v._BeginFrame()
+
+		// ---------------------------------------------------
+		// 1. handling events
+		v._HandleEvents(dt)
+
+		// ---------------------------------------------------
+		// 2. move on
+		v._NextFrame(dt)
+
+	} // for
+
+	v._StopRecording()
+	v._StopMetrics()
+	v._StopRemote()
+	v._StopDraw()
+	v._StopStream()
+	v._StopMirror()
+} // func
+
+func (v *Visualizer) _HandleEvents(dt float64) {
+	// Notice that all function calls as go routine are non-blocking, but the others will block the mainthread.
+
+	in := v._Input()
+
+	// what's been invoked or loaded from other goroutines
+	v.tasks.Run()
+	v._RestoreLoadedState()
+
+	// custom event handler
+	if v.onHandlingEvents != nil {
+		v.onHandlingEvents(dt, v.window)
+	}
+
+	// console; backtick to drop it down, and the other hotkeys are ignored while it's down
+	if in.JustPressed(pixelgl.KeyGraveAccent) {
+		v.cons.Toggle()
+	}
+	if v.cons.IsVisible() {
+		v._HandleConsole(in)
+		return
+	}
+
+	// system
+	if in.JustReleased(pixelgl.KeyEscape) {
+		v.window.SetClosed(true)
+	}
+	if in.JustReleased(pixelgl.KeySpace) {
+		v.Pause()
+		dialog.Message("%s", "Pause").Title("PPAP").Info()
+		v.Resume()
+	}
+	if in.JustReleased(pixelgl.KeyTab) {
+		if v.window.Monitor() == nil {
+			v._SetFullScreenMode(true)
+		} else {
+			v._SetFullScreenMode(false)
+		}
+	}
+
+	// frame time graph
+	if in.JustReleased(pixelgl.KeyF3) {
+		v.fgraph.Toggle()
+	}
+
+	// runtime metrics graph
+	if in.JustReleased(pixelgl.KeyF7) {
+		visible := !v.rgraph.IsVisible()
+		v.SetRuntimeGraphVisible(visible)
+		v.SetRuntimeSampling(visible)
+	}
+
+	// inspector; F1 to show, PageUp/PageDown to select, Ctrl+F1 to show or hide the one selected
+	if in.JustReleased(pixelgl.KeyF1) {
+		if in.Pressed(pixelgl.KeyLeftControl) {
+			v._ToggleInspectedVisible()
+		} else {
+			v.SetInspectorVisible(!v.IsInspectorVisible())
+		}
+	}
+	if v.insp.IsVisible() {
+		if in.JustPressed(pixelgl.KeyPageUp) {
+			v.insp.SelectNext(-1)
+		}
+		if in.JustPressed(pixelgl.KeyPageDown) {
+			v.insp.SelectNext(+1)
+		}
+	}
+
+	// actor profiling; F4 to profile and show, F5 to sort, Ctrl+F5 to group by type or not
+	if in.JustReleased(pixelgl.KeyF4) {
+		v.SetProfiling(!v.IsProfiling())
+	}
+	if in.JustReleased(pixelgl.KeyF5) {
+		if in.Pressed(pixelgl.KeyLeftControl) {
+			by, byType := v.pview.Sort()
+			v.pview.SetSort(by, !byType)
+		} else {
+			v.pview.NextSort()
+		}
+	}
+
+	// trace
+	if in.JustReleased(pixelgl.KeyF6) {
+		if v.IsTracing() {
+			v._StopTraceToFile()
+		} else {
+			v.StartTrace()
+		}
+	}
+
+	// "distracting" music
+	if in.JustReleased(pixelgl.KeyM) {
+		if in.Pressed(pixelgl.KeyLeftControl) { // because annoying stuff
+			if !jukebox.IsPlaying() {
+				// The purpose of this crappy music: the music works like those beep sounds out of patient monitors.
+				// When it slows down, we at least get an idea that something isn't going quite smoothly.
+				jukebox.Play()
+			}
+		}
+	}
+
+	// click or ctrl+click
+	if in.JustReleased(pixelgl.MouseButtonLeft) {
+		posWin := in.MousePosition()
+		posGame := v.camera.Unproject(posWin)
+		v.explosions.ExplodeAt(pixel.V(posGame.X, posGame.Y), pixel.V(10, 10))
+		if in.Pressed(pixelgl.KeyLeftControl) { // because annoying stuff
+			// strTitle := fmt.Sprint(posGame.X, ", ", posGame.Y) //
+			strDlg := fmt.Sprint(
+				"camera angle in degree: ", (v.camera.Angle()/math.Pi)*180, "\r\n", "\r\n",
+				"camera coordinates: ", v.camera.XY().X, v.camera.XY().Y, "\r\n", "\r\n",
+				"game clock: ", v.dtw.GetTimeStarted(), "\r\n", "\r\n",
+				"mouse click coords in screen pos: ", posWin.X, posWin.Y, "\r\n", "\r\n",
+				"mouse click coords in game pos: ", posGame.X, posGame.Y,
+			)
+			go func() {
+				fmt.Println() // this line resolves a syscall bug
+				// v.window.SetTitle(strTitle) //
+				dialog.Message("%s", strDlg).Title("MouseButtonLeft").Info()
+			}()
+		}
+	}
+
+	// camera - moved in place, not in a go routine, so that the same inputs end up the same.
+	if in.JustReleased(pixelgl.KeyEnter) {
+		v.camera.Rotate(-90)
+	}
+	if in.Pressed(pixelgl.KeyRight) {
+		v.camera.Move(pixel.V(1000*dt, 0).Rotated(-v.camera.Angle())) // This camera will go diagonal while the case is in middle of rotating the camera.
+	}
+	if in.Pressed(pixelgl.KeyLeft) {
+		v.camera.Move(pixel.V(-1000*dt, 0).Rotated(-v.camera.Angle()))
+	}
+	if in.Pressed(pixelgl.KeyUp) {
+		v.camera.Move(pixel.V(0, 1000*dt).Rotated(-v.camera.Angle()))
+	}
+	if in.Pressed(pixelgl.KeyDown) {
+		v.camera.Move(pixel.V(0, -1000*dt).Rotated(-v.camera.Angle()))
+	}
+	{ // if scrolled
+		v.camera.Zoom(in.MouseScroll().Y)
+	}
+}
+
+func (v *Visualizer) _NextFrame(dt float64) {
+	v.frames.Mark(super.PhaseEvents) // since _BeginFrame()
+
+	// ---------------------------------------------------
+	// 1. update - calc state of game objects each frame
+	v._Update(dt)
+	v._Mirror()
+	v._ShowState()
+	v.fpsw.Poll()
+	v.frames.Mark(super.PhaseUpdate)
+
+	// ---------------------------------------------------
+	// 2. draw on window
+	v.window.Clear(v.bg) // clear canvas
+	v._Draw()            // then draw
+	v._ReportActorPanics()
+	v._StreamFrame()
+
+	// ---------------------------------------------------
+	// 3. update title bar
+	if v.isTitleChanged {
+		v.isTitleChanged = false
+		displayed, _, _ := v.Title()
+		v.window.SetTitle(displayed)
+	}
+
+	v.frames.Mark(super.PhaseDraw)
+
+	// ---------------------------------------------------
+	// 4. update window - always end with it
+	v.window.Update()
+	v.frames.Mark(super.PhaseSwap)
+	if !v.headless {
+		<-v.vsync
+	}
+	v.frames.Mark(super.PhaseVsync)
+	v.frames.EndFrame()
+	v.frameHist.Observe(v.frames.Last().Total.Seconds())
+	v._TraceFrame()
+}
+
+// BeginFrame gets user inputs ready for the frame and returns its delta time.
+func (v *Visualizer) _BeginFrame() (dt float64) {
+	v.frames.BeginFrame()
+
+	// input other than the window moves on to this frame
+	if v.input != Input(v.window) {
+		v.input.UpdateInput()
+	}
+	if v.viewerInput != nil { // and so do inputs from viewers of the stream
+		v.viewerInput.Input = v.input
+		v.viewerInput.UpdateInput()
+	}
+
+	dt = v.dtw.Dt()
+	if v.fixedDt > 0 {
+		dt = v.fixedDt
+	}
+	dt *= v.TimeScale()
+	if v.replaying != nil {
+		if v.replaying.done {
+			v._StopReplay()
+		} else {
+			dt = v.replaying.curr.Dt
+		}
+	}
+	if v.recording != nil {
+		v._RecordFrame(dt)
+	}
+	return dt
+}
@@ -1,57 +1,976 @@
-package visual
-
-import (
-	"flag"
-	"testing"
-	"time"
-
-	"github.com/faiface/pixel"
-	"golang.org/x/image/colornames"
-)
-
-func TestMain(*testing.M) {
-	{
-		var bFlagHeadless = false
-		flag.BoolVar(&bFlagHeadless, "headless", false, "If set to true, the test runs in non-windowed mode.")
-		flag.Parse()
-		if bFlagHeadless {
-			return
-		}
-	}
-
-	visualizer := NewVisualizer(
-		Config{
-			Bg:                  pixel.ToRGBA(colornames.Coral),
-			OnPaused:            nil,
-			OnResumed:           nil,
-			OnDrawn:             nil,
-			OnUpdated:           nil,
-			OnResized:           nil,
-			OnClose:             nil,
-			OnHandlingEvents:    nil,
-			OnLogging:           nil,
-			WinCentered:         false,
-			Undecorated:         false,
-			Title:               "testing visualizer",
-			Version:             "undefined",
-			Width:               60000.0,
-			Height:              20000.0,
-			WinWidth:            900.0,
-			WinHeight:           600.0,
-			InitialZoomLevel:    -1.0,
-			InitialRotateDegree: -360.0,
-		}, nil,
-	)
-	// 1
-	go func() {
-		time.Sleep(time.Second * 3)
-		visualizer.Close()
-	}()
-	visualizer.Run()
-	// 2
-	go func() {
-		time.Sleep(time.Second * 3)
-		visualizer.Close()
-	}()
-	visualizer.Run()
-}
+package visual
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/nanitefactory/visual/actors"
+	"github.com/nanitefactory/visual/drawproto"
+	"github.com/nanitefactory/visual/logger"
+	"github.com/nanitefactory/visual/mirror"
+	"github.com/nanitefactory/visual/super"
+	"github.com/nanitefactory/visual/trace"
+	"github.com/nanitefactory/visual/visualtest"
+	"golang.org/x/image/colornames"
+)
+
+// headless is whether tests run in non-windowed mode, where those running a visualizer get skipped.
+var headless bool
+
+// mainQueue takes what tests run on the main goroutine, which a window must be run on.
+var mainQueue = make(chan func())
+
+func TestMain(m *testing.M) {
+	flag.BoolVar(&headless, "headless", false, "If set to true, the test runs in non-windowed mode.")
+	flag.BoolVar(&visualtest.Update, "update", false, "If set to true, golden images get (re)generated rather than compared.")
+	flag.Parse()
+	if headless {
+		os.Exit(m.Run())
+	}
+
+	// Tests run on another goroutine, while this one runs what they ask for.
+	exit := make(chan int)
+	go func() {
+		exit <- m.Run()
+	}()
+	for {
+		select {
+		case fn := <-mainQueue:
+			fn()
+		case code := <-exit:
+			os.Exit(code)
+		}
+	}
+}
+
+// onMainthread runs fn on the main goroutine and waits for it, or skips the test in non-windowed mode.
+// fn is not on the goroutine of the test, so it must not call t.Fatal() and the like.
+func onMainthread(t *testing.T, fn func()) {
+	t.Helper()
+	if headless {
+		t.Skip("not run in non-windowed mode")
+	}
+	done := make(chan struct{})
+	mainQueue <- func() {
+		defer close(done)
+		fn()
+	}
+	<-done
+}
+
+// stepWhile steps a visualizer until fn returns on another goroutine, and then 2 more frames.
+func stepWhile(step func(), fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	for {
+		select {
+		case <-done:
+			step()
+			step()
+			return
+		default:
+			step()
+		}
+	}
+}
+
+// testConfig is a config of a headless visualizer stepping at a fixed rate.
+func testConfig(version string) Config {
+	return Config{
+		Bg:        pixel.ToRGBA(colornames.Coral),
+		Title:     "testing visualizer",
+		Version:   version,
+		Width:     900.0,
+		Height:    600.0,
+		WinWidth:  900.0,
+		WinHeight: 600.0,
+		Headless:  true,
+		FixedDt:   1.0 / 60,
+	}
+}
+
+func TestRun(t *testing.T) {
+	visualizer, err := NewVisualizer(
+		Config{
+			Bg:                  pixel.ToRGBA(colornames.Coral),
+			OnPaused:            nil,
+			OnResumed:           nil,
+			OnDrawn:             nil,
+			OnUpdated:           nil,
+			OnResized:           nil,
+			OnClose:             nil,
+			OnHandlingEvents:    nil,
+			OnLogging:           nil,
+			WinCentered:         false,
+			Undecorated:         false,
+			Title:               "testing visualizer",
+			Version:             "undefined",
+			Width:               60000.0,
+			Height:              20000.0,
+			WinWidth:            900.0,
+			WinHeight:           600.0,
+			InitialZoomLevel:    -1.0,
+			InitialRotateDegree: -360.0,
+		}, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onMainthread(t, func() {
+		// 1
+		go func() {
+			time.Sleep(time.Second * 3)
+			visualizer.Close()
+		}()
+		visualizer.Run()
+		// 2
+		go func() {
+			time.Sleep(time.Second * 3)
+			visualizer.Close()
+		}()
+		visualizer.Run()
+	})
+}
+
+func TestGoldenExplosions(t *testing.T) {
+	cfg := testConfig("golden")
+	cfg.Seed = 1
+	visualizer, err := NewVisualizer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visualizer.explosions.ExplodeAt(pixel.V(450, 300), pixel.V(10, 10))
+	visualizer.explosions.ExplodeAt(pixel.V(300, 200), pixel.V(-5, 10))
+	visualizer.explosions.ExplodeAt(pixel.V(600, 400), pixel.V(10, -5))
+	var img *image.RGBA
+	onMainthread(t, func() {
+		img = visualizer.RunFrames(30)
+	})
+	visualtest.CompareGolden(t, "explosions", img, visualtest.DefaultTolerance)
+}
+
+// -------------------------------------------------------------------------
+// Input
+
+// inputRun is what's seen of a visualizer driven by driveInput().
+type inputRun struct {
+	camStart, camMoved, camZoomed   [3]float64 // X, Y, Z
+	explodingBefore, explodingAfter bool
+	camEnd                          [3]float64
+	nSteps                          int
+	lastFrame                       *image.RGBA
+}
+
+// driveInput drives a visualizer with a VirtualInput through the same script every time;
+// moving right for a second, zooming in and clicking to explode.
+// Started and ended, if any, are called on the mainthread after the first step and after the last one.
+func driveInput(t *testing.T, cfg Config, started, ended func(v *Visualizer)) (run inputRun) {
+	t.Helper()
+	vi := NewVirtualInput()
+	visualizer, err := NewVisualizer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visualizer.SetInput(vi)
+	xyz := func() [3]float64 {
+		x, y, z := visualizer.Camera().XYZFollow()
+		return [3]float64{x, y, z}
+	}
+	onMainthread(t, func() {
+		visualizer.Drive(func(_step func()) {
+			step := func() {
+				_step()
+				run.nSteps++
+			}
+			step()
+			run.camStart = xyz()
+			if started != nil {
+				started(visualizer)
+			}
+
+			vi.Press(pixelgl.KeyRight)
+			for i := 0; i < 60; i++ {
+				step()
+			}
+			vi.Release(pixelgl.KeyRight)
+			step()
+			run.camMoved = xyz()
+
+			vi.Scroll(pixel.V(0, 1))
+			step()
+			run.camZoomed = xyz()
+
+			run.explodingBefore = visualizer.explosions.IsExploding()
+			vi.Click(pixelgl.MouseButtonLeft, visualizer.Project(pixel.V(450, 300)))
+			step()
+			step()
+			run.explodingAfter = visualizer.explosions.IsExploding()
+			for i := 0; i < 10; i++ {
+				step()
+			}
+			x, y, z := visualizer.Camera().XYZ()
+			run.camEnd = [3]float64{x, y, z}
+			run.lastFrame = visualizer._Capture()
+			if ended != nil {
+				ended(visualizer)
+			}
+		})
+	})
+	return run
+}
+
+func TestVirtualInput(t *testing.T) {
+	run := driveInput(t, testConfig("input"), nil, nil)
+	if dx := run.camMoved[0] - run.camStart[0]; dx < 900 || dx > 1100 {
+		t.Errorf("camera moved %v to the right for a second of the right arrow; want about 1000", dx)
+	}
+	if z := run.camZoomed[2] / run.camMoved[2]; z < 1.19 || z > 1.21 {
+		t.Errorf("camera zoomed %v times by a scroll; want 1.2", z)
+	}
+	if run.explodingBefore || !run.explodingAfter {
+		t.Error("a click does not explode")
+	}
+}
+
+func TestFrameStats(t *testing.T) {
+	var stats super.FrameStats
+	run := driveInput(t, testConfig("frame stats"), nil, func(v *Visualizer) {
+		stats = v.FrameStats()
+	})
+	if n := len(stats.Frames); n != run.nSteps+2 { // 2 frames on lazy init
+		t.Errorf("%d frames timed; want %d", n, run.nSteps+2)
+	}
+	if stats.Total.Min <= 0 || stats.Total.Min > stats.Total.P95 || stats.Total.P99 > stats.Total.Max {
+		t.Errorf("frame times out of order: %+v", stats.Total)
+	}
+	if stats.Phases[super.PhaseDraw].Max <= 0 {
+		t.Error("drawing took no time")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var traced bytes.Buffer
+	var err error
+	run := driveInput(t, testConfig("trace"), func(v *Visualizer) {
+		v.StartTrace()
+	}, func(v *Visualizer) {
+		err = v.StopTrace(&traced)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		TraceEvents []trace.Event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(traced.Bytes(), &file); err != nil {
+		t.Fatal(err)
+	}
+	count := map[string]int{}
+	for _, e := range file.TraceEvents {
+		count[e.Name]++
+	}
+	if n := count["frame"]; n != run.nSteps-1 { // all but the first step
+		t.Errorf("%d frames traced; want %d", n, run.nSteps-1)
+	}
+	if count["draw"] != count["frame"] || count["update"] != count["frame"] {
+		t.Errorf("phases traced %v; want an update and a draw for every frame", count)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	cfg := testConfig("metrics")
+	cfg.MetricsAddr = "127.0.0.1:0"
+	var metrics []byte
+	run := driveInput(t, cfg, nil, func(v *Visualizer) {
+		if resp, err := http.Get("http://" + v.MetricsAddr() + "/metrics"); err == nil {
+			metrics, _ = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+	})
+	for _, want := range []string{
+		fmt.Sprintf("visual_frame_seconds_count %d\n", run.nSteps+2),
+		"visual_actors 0\n",
+		"visual_particles ",
+		`visual_frame_phase_seconds{phase="draw"} `,
+	} {
+		if !bytes.Contains(metrics, []byte(want)) {
+			t.Errorf("%q not scraped in:\n%s", want, metrics)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var recorded bytes.Buffer
+	cfg := testConfig("record")
+	cfg.RecordTo = &recorded
+	run := driveInput(t, cfg, nil, nil)
+
+	cfg = testConfig("replay")
+	cfg.FixedDt = 0 // dt of frames replayed
+	cfg.ReplayFrom = bytes.NewReader(recorded.Bytes())
+	visualizer, err := NewVisualizer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cam [3]float64
+	var lastFrame *image.RGBA
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			for i := 0; i < run.nSteps; i++ {
+				step()
+			}
+			x, y, z := visualizer.Camera().XYZ()
+			cam = [3]float64{x, y, z}
+			lastFrame = visualizer._Capture()
+		})
+	})
+	if cam != run.camEnd {
+		t.Errorf("camera replayed at %v; want %v", cam, run.camEnd)
+	}
+	if n, _ := visualtest.Diff(run.lastFrame, lastFrame, 0); n != 0 {
+		t.Errorf("the last frame replayed differs in %d pixels", n)
+	}
+}
+
+// -------------------------------------------------------------------------
+// Export
+
+func TestExportRegion(t *testing.T) {
+	// The bottom half of the region in red, over 2x2 tiles of 1024px.
+	red, err := actors.NewShape(super.ShapeRect, []pixel.Vec{pixel.V(0, 0), pixel.V(1500, 550)}, 0, 0, colornames.Red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []string
+	cfg := testConfig("export")
+	cfg.Bg = pixel.ToRGBA(colornames.Blue)
+	cfg.Width, cfg.Height = 3000.0, 2000.0
+	cfg.OnExporting = func(tilesDone, tilesTotal int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", tilesDone, tilesTotal))
+	}
+	visualizer, err := NewVisualizer(cfg, nil, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "visual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "region.png")
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			step()
+			err = visualizer.ExportRegion(pixel.R(0, 0, 1500, 1100), 1, path)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"1/4", "2/4", "3/4", "4/4"}; fmt.Sprint(progress) != fmt.Sprint(want) {
+		t.Errorf("exporting progressed %v; want %v", progress, want)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b != image.Rect(0, 0, 1500, 1100) {
+		t.Fatalf("exported %v; want 1500x1100", b)
+	}
+	// Pixels by the borders and the seams of tiles, top-down.
+	for _, p := range []struct {
+		x, y int
+		red  bool
+	}{
+		{0, 0, false}, {1499, 0, false}, {1023, 0, false}, {1024, 0, false},
+		{0, 75, false}, {1499, 76, false}, // by the seam between the upper tiles and the lower ones, 1024px up from the bottom
+		{0, 1099, true}, {1499, 1099, true}, {1023, 1099, true}, {1024, 1099, true},
+		{1499, 548, false}, {1499, 552, true},
+	} {
+		want := colornames.Blue
+		if p.red {
+			want = colornames.Red
+		}
+		if r, g, b, _ := img.At(p.x, p.y).RGBA(); r>>8 != uint32(want.R) || g>>8 != uint32(want.G) || b>>8 != uint32(want.B) {
+			t.Errorf("pixel (%d, %d) exported is %v; want %v", p.x, p.y, img.At(p.x, p.y), want)
+		}
+	}
+}
+
+func TestExportRegionNotRunning(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.ExportRegion(pixel.R(0, 0, 100, 100), 1, filepath.Join(os.TempDir(), "never.png")); err == nil {
+		t.Error("exported without running")
+	}
+}
+
+// -------------------------------------------------------------------------
+// Actor panics
+
+func TestActorPanic(t *testing.T) {
+	var reported []ActorPanic
+	survivor := &counter{}
+	cfg := testConfig("panic")
+	cfg.Logger = logger.Nop
+	cfg.OnActorPanic = func(p ActorPanic) {
+		reported = append(reported, p)
+	}
+	visualizer, err := NewVisualizer(cfg, nil, &panicky{onUpdate: true}, survivor, &panicky{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	onMainthread(t, func() {
+		visualizer.RunFrames(10)
+	})
+
+	if n := len(reported); n != 2 {
+		t.Fatalf("%d panics reported; want 2", n)
+	}
+	for i, op := range []string{"update", "draw"} {
+		if p := reported[i]; p.Op != op || p.Value != "oops" || len(p.Stack) == 0 {
+			t.Errorf("panic %d reported as %s %v; want %s oops with a stack", i, p.Op, p.Value, op)
+		}
+	}
+	if n := len(visualizer.Quarantined()); n != 2 {
+		t.Errorf("%d actors quarantined; want 2", n)
+	}
+	if survivor.n != 10+2 { // 2 frames on lazy init
+		t.Errorf("the actor beside panicking ones updated %d times; want %d", survivor.n, 10+2)
+	}
+}
+
+// -------------------------------------------------------------------------
+// Console and servers
+
+func TestConsole(t *testing.T) {
+	vi := NewVirtualInput()
+	visualizer, err := NewVisualizer(testConfig("console"), nil, &counter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	visualizer.SetInput(vi)
+	var timeScale float64
+	var explodes bool
+	var lines []string
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			step()
+			vi.Click(pixelgl.KeyGraveAccent, pixel.ZV)
+			vi.Type("`times")
+			step()
+			vi.Press(pixelgl.KeyTab)
+			step()
+			vi.Release(pixelgl.KeyTab)
+			vi.Type("0.5")
+			step()
+			vi.Click(pixelgl.KeyEnter, pixel.ZV)
+			step()
+			step()
+			timeScale = visualizer.TimeScale()
+			explodes = visualizer.Exec("explode 10 10") == "" && visualizer.explosions.IsExploding()
+			visualizer.cons.Print(visualizer.Exec("actors"))
+			lines = visualizer.cons.Lines()
+		})
+	})
+
+	if timeScale != 0.5 {
+		t.Errorf("time scale %v after typed in; want 0.5", timeScale)
+	}
+	if !explodes {
+		t.Error("the explode command does not explode")
+	}
+	want := []string{"> timescale 0.5", "*visual.counter x1", "1 actors, 0 HUDs"}
+	if len(lines) != len(want) {
+		t.Fatalf("console printed %q; want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("console printed %q; want %q", lines[i], want[i])
+		}
+	}
+}
+
+func TestRemote(t *testing.T) {
+	cfg := testConfig("remote")
+	cfg.RemoteAddr = "127.0.0.1:0"
+	visualizer, err := NewVisualizer(cfg, nil, &counter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := map[string]string{}
+	var paused bool
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			step()
+			stepWhile(step, func() {
+				for _, req := range []string{
+					"POST /pause {}",
+					"GET /actors",
+					`POST /camera {"move_to": {"x": 100, "y": 200}}`,
+					"GET /screenshot",
+					`POST /title {"unknown": 1}`,
+				} {
+					var resp *http.Response
+					var err error
+					url := "http://" + visualizer.RemoteAddr() + strings.Fields(req)[1]
+					if strings.HasPrefix(req, "GET") {
+						resp, err = http.Get(url)
+					} else {
+						resp, err = http.Post(url, "application/json", strings.NewReader(strings.SplitN(req, " ", 3)[2]))
+					}
+					if err != nil {
+						responses[req] = err.Error()
+						continue
+					}
+					body, _ := ioutil.ReadAll(resp.Body)
+					resp.Body.Close()
+					responses[req] = fmt.Sprintf("%d %s %s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+				}
+			})
+			paused = visualizer.IsPaused()
+		})
+	})
+
+	if !paused {
+		t.Error("not paused remotely")
+	}
+	for req, want := range map[string]string{
+		"POST /pause {}": `200 application/json {"paused":true}`,
+		"GET /actors":    `200 application/json [{"index":0,"type":"*visual.counter","hud":false,"visible":true,"active":true,"quarantined":false}]`,
+		`POST /camera {"move_to": {"x": 100, "y": 200}}`: "200 application/json",
+		"GET /screenshot":            "200 image/png",
+		`POST /title {"unknown": 1}`: "400 application/json",
+	} {
+		if got := responses[req]; !strings.HasPrefix(got, want) {
+			t.Errorf("%s responded %q; want %q", req, got, want)
+		}
+	}
+}
+
+func TestDrawProto(t *testing.T) {
+	cfg := testConfig("drawproto")
+	cfg.DrawAddr = "127.0.0.1:0"
+	visualizer, err := NewVisualizer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var drawn []string
+	var dropped bool
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			step()
+			stepWhile(step, func() {
+				conn, err := net.Dial("tcp", visualizer.DrawAddr())
+				if err != nil {
+					return
+				}
+				fmt.Fprintln(conn, `{"op": "create", "seq": 1, "id": "box", "prim": {"kind": "shape", "shape": "rect", "points": [[0, 0], [10, 10]]}}`)
+				fmt.Fprintln(conn, `{"op": "batch", "seq": 2, "ops": [{"op": "update", "id": "box", "prim": {"color": "#ff0000"}}, {"op": "create", "id": "hi", "prim": {"kind": "text", "text": "hi", "space": "screen"}}]}`)
+				acks := bufio.NewScanner(conn)
+				acks.Scan() // of the create
+				acks.Scan() // of the batch
+				for _, actor := range visualizer.ActorsByTag("drawproto") {
+					drawn = append(drawn, fmt.Sprintf("%T", actor))
+				}
+				conn.Close()
+				for i := 0; i < 100 && len(visualizer.ActorsByTag("client-1")) > 0; i++ {
+					time.Sleep(10 * time.Millisecond)
+				}
+				dropped = len(visualizer.ActorsByTag("client-1")) == 0
+			})
+		})
+	})
+
+	if want := []string{"*actors.Shape", "*actors.Label"}; fmt.Sprint(drawn) != fmt.Sprint(want) {
+		t.Errorf("a client drew %v; want %v", drawn, want)
+	}
+	if !dropped {
+		t.Error("what a client drew is not dropped on disconnect")
+	}
+}
+
+func TestStream(t *testing.T) {
+	cfg := testConfig("stream")
+	cfg.StreamAddr = "127.0.0.1:0"
+	visualizer, err := NewVisualizer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed []string
+	var toggled bool
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			step()
+			consoleVisible := visualizer.IsConsoleVisible()
+			stepWhile(step, func() {
+				url := "http://" + visualizer.StreamAddr()
+				for _, req := range []func() (*http.Response, error){
+					func() (*http.Response, error) { return http.Get(url + "/") },
+					func() (*http.Response, error) {
+						return http.Post(url+"/input", "application/json", strings.NewReader(
+							`[{"type": "down", "code": "Backquote"}, {"type": "up", "code": "Backquote"}]`))
+					},
+				} {
+					resp, err := req()
+					if err != nil {
+						streamed = append(streamed, err.Error())
+						continue
+					}
+					resp.Body.Close()
+					streamed = append(streamed, fmt.Sprint(resp.StatusCode, " ", resp.Header.Get("Content-Type")))
+				}
+			})
+			toggled = visualizer.IsConsoleVisible() != consoleVisible
+		})
+	})
+
+	if want := []string{"200 text/html; charset=utf-8", "204 "}; fmt.Sprint(streamed) != fmt.Sprint(want) {
+		t.Errorf("the stream responded %q; want %q", streamed, want)
+	}
+	if !toggled {
+		t.Error("a key pressed by a viewer is not handled")
+	}
+}
+
+func TestMirror(t *testing.T) {
+	cfg := testConfig("mirror")
+	cfg.LeadAddr = "127.0.0.1:0"
+	visualizer, err := NewVisualizer(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mirrored *mirror.State
+	onMainthread(t, func() {
+		visualizer.Drive(func(step func()) {
+			step()
+			visualizer.SetPaused(true)
+			visualizer.SetTimeScale(0.5)
+			visualizer.Camera().MoveTo(pixel.V(100, 200))
+			stepWhile(step, func() {
+				f := mirror.Follow(visualizer.LeadAddr())
+				defer f.Close()
+				for i := 0; i < 500; i++ {
+					if s, _, ok := f.Latest(); ok {
+						mirrored = &s
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			})
+		})
+	})
+
+	if mirrored == nil {
+		t.Fatal("a follower got nothing from the leader")
+	}
+	if s := *mirrored; !s.Paused || s.TimeScale != 0.5 || s.Camera.FollowX != 100 || s.Camera.FollowY != 200 {
+		t.Errorf("a follower got %+v; want it paused at 0.5x following (100, 200)", s)
+	}
+}
+
+// counter is an Actor that counts its updates, and a Snapshotter.
+type counter struct {
+	n int
+}
+
+func (c *counter) Draw(pixel.Target) {}
+
+func (c *counter) Update(float64) { c.n++ }
+
+func (c *counter) Snapshot() ([]byte, error) { return []byte(strconv.Itoa(c.n)), nil }
+
+func (c *counter) Restore(data []byte) (err error) {
+	c.n, err = strconv.Atoi(string(data))
+	return err
+}
+
+func init() {
+	RegisterActorType("visual.counter", func(map[string]interface{}) (Actor, error) {
+		return &counter{}, nil
+	})
+	RegisterActorType("visual.test.panicky", func(props map[string]interface{}) (Actor, error) {
+		return &panicky{onUpdate: props["on_update"].(bool)}, nil // panics with nil props
+	})
+	RegisterActorType("visual.test.counter", func(props map[string]interface{}) (Actor, error) {
+		return NewActor("visual.counter", props) // not deadlocked
+	})
+}
+
+func TestRegisterActorType(t *testing.T) {
+	schemas := map[string]ActorSchema{}
+	for _, schema := range ActorSchemas() {
+		schemas[schema.Name] = schema
+	}
+	if got := schemas["visual.test.panicky"].GoType; got != "" {
+		t.Errorf("Go type %q of a factory panicking; want none", got)
+	}
+	if got := schemas["visual.test.counter"].GoType; got != "*visual.counter" {
+		t.Errorf("Go type %q of a factory creating another; want *visual.counter", got)
+	}
+	if _, err := NewActor("visual.test.panicky", map[string]interface{}{"on_update": true}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSaveLoadState(t *testing.T) {
+	saved, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved.PushActors(&counter{1}, &counter{2})
+	var buf bytes.Buffer
+	if err := saved.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil, &counter{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.actors) != 2 {
+		t.Fatalf("%d actors loaded; want 2", len(loaded.actors))
+	}
+	for i, actor := range loaded.actors {
+		if c, ok := actor.(*counter); !ok || c.n != i+1 {
+			t.Errorf("actor %d loaded is %#v; want &counter{%d}", i, actor, i+1)
+		}
+	}
+
+	saved.PushActors(&struct{ counter }{})
+	if err := saved.SaveState(&buf); err == nil {
+		t.Error("an actor of a type not registered is saved")
+	}
+}
+
+func TestLoadStateForgets(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil, &counter{}, &panicky{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Tag(v.actors[0], "debug")
+	v._Quarantine(v.actors[1], 1, super.ProfileUpdate, "oops", nil)
+	label := drawproto.Primitive{Kind: drawproto.KindText, Text: "drawn", Scale: 1}
+	if err := v._ApplyDrawn("client-1", []drawproto.Change{{ID: "label", Prim: label}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.LoadState(strings.NewReader(`{"version": 1, "actors": [{"type": "visual.counter", "data": "MQ=="}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.actors) != 1 || len(v.huds) != 0 {
+		t.Fatalf("%d actors and %d HUDs loaded; want 1 and 0", len(v.actors), len(v.huds))
+	}
+	if len(v.meta) != 0 || len(v.quarantine) != 0 || len(v.quarantined) != 0 || len(v.drawn) != 0 {
+		t.Errorf("%d flagged, %d quarantined and %d clients drawing after loaded; want none", len(v.meta), len(v.quarantined), len(v.drawn))
+	}
+	if len(v.panicsToReport) != 1 {
+		t.Errorf("%d panics to report after loaded; want 1", len(v.panicsToReport))
+	}
+	if err := v._ApplyDrawn("client-1", []drawproto.Change{{ID: "label", Prim: label}}); err != nil || len(v.actors)+len(v.huds) != 2 {
+		t.Errorf("%d actors and HUDs after drawn again, %v; want 2", len(v.actors)+len(v.huds), err)
+	}
+}
+
+func TestNewActorExplosions(t *testing.T) {
+	actor, err := NewActor("explosions", map[string]interface{}{"colors": []interface{}{"red", "#00ff0080"}, "seed": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	explosions := actor.(*actors.Explosions)
+	explosions.ExplodeAt(pixel.V(100, 100), pixel.V(10, 10))
+	explosions.Update(1.0 / 60)
+	if !explosions.IsExploding() {
+		t.Error("not exploding in colors given")
+	}
+}
+
+func TestActorTags(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := &counter{}, &counter{}, &counter{}
+	v.PushActors(a, b, c)
+	v.Tag(a, "debug", "grid")
+	v.Tag(c, "debug")
+	v.Untag(a, "grid")
+
+	if tags := v.Tags(a); len(tags) != 1 || tags[0] != "debug" {
+		t.Errorf("tags %v; want [debug]", tags)
+	}
+	if got := v.ActorsByTag("debug"); len(got) != 2 || got[0] != a || got[1] != c {
+		t.Errorf("actors by tag %v; want [a c] in order", got)
+	}
+	if n := v.SetVisibleByTag("debug", false); n != 2 {
+		t.Errorf("%d actors hidden; want 2", n)
+	}
+	if v.IsVisible(a) || !v.IsVisible(b) || v.IsVisible(c) {
+		t.Error("actors hidden are not the ones tagged")
+	}
+	v.SetActive(b, false)
+
+	update := func() {
+		for i, actor := range v.actors {
+			v._CallActor(actor, i, super.ProfileUpdate, nil, func() { actor.Update(1) })
+		}
+	}
+	update()
+	if a.n != 1 || b.n != 0 || c.n != 1 {
+		t.Errorf("updated %d, %d and %d times; want hidden ones updated but not the inactive one", a.n, b.n, c.n)
+	}
+	v.SetActive(b, true)
+	update()
+	if b.n != 1 {
+		t.Errorf("updated %d times after activated; want 1", b.n)
+	}
+}
+
+func TestActorTagsForgotten(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, twice := &counter{}, &counter{}
+	v.Tag(a, "debug") // before pushed
+	v.PushActors(a, twice, twice)
+	v.Tag(twice, "debug")
+	b := &hud{}
+	v.PushHUDs(b)
+	v.Tag(b, "debug")
+
+	v.RemoveActor(twice)
+	if tags := v.Tags(twice); len(tags) != 1 {
+		t.Errorf("tags %v of an actor still pushed once; want [debug]", tags)
+	}
+	v.RemoveActor(twice)
+	v.RemoveActor(a)
+	v.PopHUD()
+	if len(v.meta) != 0 {
+		t.Errorf("%d actors still flagged after removed; want 0", len(v.meta))
+	}
+
+	// Actors that can't be map keys are just ignored.
+	var unhashable sliceActor
+	v.PushActors(unhashable)
+	v.Tag(unhashable, "debug")
+	v.SetVisible(unhashable, false)
+	if !v.IsVisible(unhashable) || len(v.Tags(unhashable)) != 0 || len(v.ActorsByTag("debug")) != 0 {
+		t.Error("an actor of a type not comparable is flagged or tagged")
+	}
+}
+
+// hud is a HUD that does nothing.
+type hud struct{ counter }
+
+func (h *hud) PosOnScreen(width, height float64) {}
+
+// sliceActor is an Actor of a type that can't be compared with ==.
+type sliceActor []int
+
+func (sliceActor) Draw(pixel.Target) {}
+
+func (sliceActor) Update(float64) {}
+
+func TestApplyDrawnKeepsFlags(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	label := drawproto.Primitive{Kind: drawproto.KindText, Text: "before", Scale: 1}
+	if err := v._ApplyDrawn("client-1", []drawproto.Change{{ID: "label", Prim: label}}); err != nil {
+		t.Fatal(err)
+	}
+	v.SetVisibleByTag("drawproto", false)
+	label.Text = "after"
+	if err := v._ApplyDrawn("client-1", []drawproto.Change{{ID: "label", Prim: label}}); err != nil {
+		t.Fatal(err)
+	}
+	if v.IsVisible(v.drawn["client-1"]["label"].actor) {
+		t.Error("an actor drawn and hidden shows up again once updated by the client")
+	}
+	if n := v.SetVisibleByTag("client-1", true); n != 1 {
+		t.Errorf("%d actors tagged with the client after updated; want 1", n)
+	}
+
+	if err := v._ApplyDrawn("client-1", []drawproto.Change{{ID: "label", Delete: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v._ApplyDrawn("client-2", []drawproto.Change{{ID: "label", Prim: label}}); err != nil {
+		t.Fatal(err)
+	}
+	v._DropDrawn("client-2")
+	if len(v.meta) != 0 {
+		t.Errorf("%d actors still flagged after deleted or dropped; want 0", len(v.meta))
+	}
+}
+
+func TestInspectedItems(t *testing.T) {
+	v, err := NewVisualizer(Config{Width: 900.0, Height: 600.0, WinWidth: 900.0, WinHeight: 600.0}, nil, &counter{}, &panicky{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Tag(v.actors[1], "flaky", "debug")
+	v.SetVisible(v.actors[1], false)
+	v._Quarantine(v.actors[1], 1, super.ProfileUpdate, "oops", nil)
+
+	items := v._InspectedItems()
+	if len(items) != 2 {
+		t.Fatalf("%d items inspected; want 2", len(items))
+	}
+	if got := items[0]; got.Type != "*visual.counter" || !got.Visible || !got.Active || got.Quarantined || got.HUD {
+		t.Errorf("the first item %+v; want a plain counter", got)
+	}
+	if got := items[1]; got.Visible || !got.Quarantined || len(got.Tags) != 2 || got.Tags[0] != "debug" {
+		t.Errorf("the second item %+v; want hidden, quarantined and tagged in order", got)
+	}
+}
+
+// panicky is an Actor that panics on Update() or Draw().
+type panicky struct {
+	onUpdate bool
+}
+
+func (p *panicky) Draw(pixel.Target) {
+	if !p.onUpdate {
+		panic("oops")
+	}
+}
+
+func (p *panicky) Update(float64) {
+	if p.onUpdate {
+		panic("oops")
+	}
+}
+
+func (p *panicky) Bounds() pixel.Rect { return pixel.R(100, 100, 200, 200) }
@@ -0,0 +1,159 @@
+package visualtest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// -------------------------------------------------------------------------
+// Golden images
+
+// Update makes CompareGolden() (re)generate golden images rather than compare them.
+// Tests usually set it with a flag of their own, such as -update.
+var Update bool
+
+// GoldenDir is where golden images are stored, relative to the package being tested.
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// Tolerance decides how different an image can be from its golden image.
+type Tolerance struct {
+	// Threshold is the perceptual difference in color a single pixel can have, from 0 to 1.
+	// 0 allows no difference at all and 1 allows anything. 0.1 is a reasonable value.
+	Threshold float64
+	// MaxDiffRatio is the ratio of pixels that are allowed to exceed the Threshold, from 0 to 1.
+	MaxDiffRatio float64
+}
+
+// DefaultTolerance allows a slight difference that comes from GPUs and drivers.
+var DefaultTolerance = Tolerance{Threshold: 0.1, MaxDiffRatio: 0.001}
+
+// CompareGolden compares an image against the golden image of a name in GoldenDir.
+// On failure, the image and a diff image are written next to the golden image.
+// Set Update to (re)generate the golden image instead.
+// A test fails if its golden image does not exist yet.
+func CompareGolden(tb testing.TB, name string, img image.Image, tol Tolerance) {
+	tb.Helper()
+
+	path := filepath.Join(GoldenDir, name+".png")
+	if Update {
+		if err := os.MkdirAll(GoldenDir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := writePNG(path, img); err != nil {
+			tb.Fatal(err)
+		}
+		tb.Logf("golden image updated: %s", path)
+		return
+	}
+
+	golden, err := readPNG(path)
+	if os.IsNotExist(err) {
+		tb.Fatalf("golden image %s does not exist; generate it with Update set", path)
+	}
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	nDiff, diff := Diff(golden, img, tol.Threshold)
+	if diff == nil {
+		tb.Errorf("image %s is %v; want %v", name, img.Bounds().Size(), golden.Bounds().Size())
+		return
+	}
+	nMax := int(tol.MaxDiffRatio * float64(img.Bounds().Dx()*img.Bounds().Dy()))
+	if nDiff <= nMax {
+		return
+	}
+
+	pathActual := filepath.Join(GoldenDir, name+"_actual.png")
+	pathDiff := filepath.Join(GoldenDir, name+"_diff.png")
+	if err := writePNG(pathActual, img); err != nil {
+		tb.Log(err)
+	}
+	if err := writePNG(pathDiff, diff); err != nil {
+		tb.Log(err)
+	}
+	tb.Errorf("image %s differs from its golden image in %d pixels (%d allowed); see %s and %s",
+		name, nDiff, nMax, pathActual, pathDiff)
+}
+
+// Diff compares two images pixel by pixel with a perceptual threshold from 0 to 1.
+// It returns the number of pixels that differ and an image that highlights them in red.
+// The diff image is nil if the sizes of two images don't match.
+func Diff(a, b image.Image, threshold float64) (nDiff int, diff *image.RGBA) {
+	ra, rb := a.Bounds(), b.Bounds()
+	if ra.Size() != rb.Size() {
+		return -1, nil
+	}
+	const maxDelta = 35215.0 // the YIQ delta between black and white
+	maxAllowed := maxDelta * threshold * threshold
+
+	diff = image.NewRGBA(image.Rect(0, 0, ra.Dx(), ra.Dy()))
+	for y := 0; y < ra.Dy(); y++ {
+		for x := 0; x < ra.Dx(); x++ {
+			ca := a.At(ra.Min.X+x, ra.Min.Y+y)
+			cb := b.At(rb.Min.X+x, rb.Min.Y+y)
+			if yiqDelta(ca, cb) > maxAllowed {
+				nDiff++
+				diff.Set(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			// A faded grayscale of the original to give some context.
+			gray := color.GrayModel.Convert(ca).(color.Gray)
+			gray.Y = 255 - (255-gray.Y)/4
+			diff.Set(x, y, gray)
+		}
+	}
+	return nDiff, diff
+}
+
+// yiqDelta returns the squared perceptual distance between two colors in the YIQ color space,
+// where both are blended with white first.
+func yiqDelta(c1, c2 color.Color) float64 {
+	y1, i1, q1 := yiq(c1)
+	y2, i2, q2 := yiq(c2)
+	dy, di, dq := y1-y2, i1-i2, q1-q2
+	return 0.5053*dy*dy + 0.299*di*di + 0.1957*dq*dq
+}
+
+func yiq(c color.Color) (y, i, q float64) {
+	r16, g16, b16, a16 := c.RGBA() // alpha-premultiplied
+	white := 255 * (1 - float64(a16)/0xffff)
+	r := float64(r16)/0xffff*255 + white
+	g := float64(g16)/0xffff*255 + white
+	b := float64(b16)/0xffff*255 + white
+	y = 0.29889531*r + 0.58662247*g + 0.11448223*b
+	i = 0.59597799*r - 0.27417610*g - 0.32180189*b
+	q = 0.21147017*r - 0.52261711*g + 0.31114694*b
+	return y, i, q
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
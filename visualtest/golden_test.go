@@ -0,0 +1,68 @@
+package visualtest
+
+import (
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	newImage := func(c color.Color) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				img.Set(x, y, c)
+			}
+		}
+		return img
+	}
+	gray := newImage(color.RGBA{128, 128, 128, 255})
+
+	if n, _ := Diff(gray, newImage(color.RGBA{130, 128, 127, 255}), 0.1); n != 0 {
+		t.Errorf("%d pixels differ between almost the same colors; want 0", n)
+	}
+	if n, _ := Diff(gray, newImage(color.RGBA{255, 0, 0, 255}), 0.1); n != 16 {
+		t.Errorf("%d pixels differ between gray and red; want 16", n)
+	}
+
+	red := newImage(color.RGBA{128, 128, 128, 255})
+	red.Set(1, 2, color.RGBA{255, 0, 0, 255})
+	n, diff := Diff(gray, red, 0.1)
+	if n != 1 {
+		t.Errorf("%d pixels differ; want 1", n)
+	}
+	if diff.RGBAAt(1, 2) != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("the diff image does not highlight the pixel that differs")
+	}
+
+	if _, diff := Diff(gray, image.NewRGBA(image.Rect(0, 0, 4, 5)), 0.1); diff != nil {
+		t.Errorf("images of different sizes are compared")
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "visualtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(goldenDir string, updating bool) {
+		GoldenDir, Update = goldenDir, updating
+	}(GoldenDir, Update)
+	GoldenDir = filepath.Join(dir, "golden")
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	img.Set(3, 3, color.RGBA{0, 255, 0, 255})
+
+	Update = true
+	CompareGolden(t, "dot", img, DefaultTolerance)
+	if _, err := os.Stat(filepath.Join(GoldenDir, "dot.png")); err != nil {
+		t.Fatalf("golden image is not written: %v", err)
+	}
+
+	Update = false
+	CompareGolden(t, "dot", img, DefaultTolerance)
+}
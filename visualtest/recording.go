@@ -0,0 +1,260 @@
+// Package visualtest provides tools to test Actors and Visualizers.
+package visualtest
+
+import (
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/faiface/pixel"
+)
+
+// -------------------------------------------------------------------------
+// Recorded calls
+
+// Op is a kind of call a RecordingTarget records.
+type Op int
+
+// enum Op
+const (
+	OpMakeTriangles Op = 1 + iota // Target.MakeTriangles()
+	OpMakePicture                 // Target.MakePicture()
+	OpDraw                        // TargetTriangles.Draw() or TargetPicture.Draw()
+)
+
+// Vertex is a single vertex of recorded triangles.
+type Vertex struct {
+	Pos       pixel.Vec  // Position before it gets projected by the matrix.
+	Color     pixel.RGBA // Color before it gets multiplied by the color mask.
+	Pic       pixel.Vec  // Picture coords.
+	Intensity float64    // Weight of the picture. 0 if there's none.
+}
+
+// Call is a call recorded by a RecordingTarget.
+type Call struct {
+	Op         Op
+	Matrix     pixel.Matrix // The matrix set by SetMatrix() at the time of the call.
+	ColorMask  pixel.RGBA   // The color mask set by SetColorMask() at the time of the call.
+	Vertices   []Vertex     // Triangles involved. (OpMakeTriangles, OpDraw)
+	Picture    pixel.Rect   // Bounds of the picture involved. (OpMakePicture, OpDraw)
+	HasPicture bool         // Whether the call involves a picture or not.
+}
+
+// Projected returns the position of the i-th vertex projected by the matrix.
+func (c Call) Projected(i int) pixel.Vec {
+	return c.Matrix.Project(c.Vertices[i].Pos)
+}
+
+// Bounds returns the smallest rect that contains all the projected vertices.
+func (c Call) Bounds() pixel.Rect {
+	if len(c.Vertices) <= 0 {
+		return pixel.Rect{}
+	}
+	p := c.Projected(0)
+	r := pixel.Rect{Min: p, Max: p}
+	for i := range c.Vertices {
+		p := c.Projected(i)
+		r.Min.X, r.Min.Y = math.Min(r.Min.X, p.X), math.Min(r.Min.Y, p.Y)
+		r.Max.X, r.Max.Y = math.Max(r.Max.X, p.X), math.Max(r.Max.Y, p.Y)
+	}
+	return r
+}
+
+// -------------------------------------------------------------------------
+// RecordingTarget
+
+// RecordingTarget is a pixel.BasicTarget that draws nothing but records what's drawn on it.
+// It lets you test Drawers without a GPU.
+// It is safe to use it concurrently.
+type RecordingTarget struct {
+	mutex  sync.Mutex
+	matrix pixel.Matrix
+	mask   pixel.RGBA
+	calls  []Call
+}
+
+// NewRecordingTarget is a constructor.
+func NewRecordingTarget() *RecordingTarget {
+	return &RecordingTarget{
+		matrix: pixel.IM,
+		mask:   pixel.Alpha(1),
+	}
+}
+
+// MakeTriangles implements pixel.Target.
+func (rt *RecordingTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	tri := &recTriangles{pixel.MakeTrianglesData(t.Len()), rt}
+	tri.Update(t)
+	rt._Record(OpMakeTriangles, tri.vertices(), nil)
+	return tri
+}
+
+// MakePicture implements pixel.Target.
+func (rt *RecordingTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	pic := &recPicture{p, rt}
+	rt._Record(OpMakePicture, nil, pic)
+	return pic
+}
+
+// SetMatrix implements pixel.BasicTarget.
+func (rt *RecordingTarget) SetMatrix(m pixel.Matrix) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	rt.matrix = m
+}
+
+// SetColorMask implements pixel.BasicTarget.
+func (rt *RecordingTarget) SetColorMask(c color.Color) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	if c == nil {
+		rt.mask = pixel.Alpha(1)
+		return
+	}
+	rt.mask = pixel.ToRGBA(c)
+}
+
+// Calls returns all calls recorded so far in order.
+func (rt *RecordingTarget) Calls() []Call {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	return append([]Call(nil), rt.calls...)
+}
+
+// Draws returns all OpDraw calls recorded so far in order.
+func (rt *RecordingTarget) Draws() []Call {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	ret := []Call{}
+	for _, c := range rt.calls {
+		if c.Op == OpDraw {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Count returns the number of calls recorded so far of an Op.
+func (rt *RecordingTarget) Count(op Op) int {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	n := 0
+	for _, c := range rt.calls {
+		if c.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets all calls recorded so far. The matrix and the color mask stay.
+func (rt *RecordingTarget) Reset() {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	rt.calls = nil
+}
+
+// DrewNear determines whether a triangle of a color has been drawn around a position.
+// A triangle counts if all of its vertices are of the color,
+// and its center projected by the matrix is within the radius of pos.
+// For example, a filled circle drawn by imdraw is seen as triangles all around its center.
+func (rt *RecordingTarget) DrewNear(col color.Color, pos pixel.Vec, radius float64) bool {
+	want := pixel.ToRGBA(col)
+	for _, c := range rt.Draws() {
+		for i := 0; i+2 < len(c.Vertices); i += 3 {
+			if !SameColor(c.Vertices[i].Color, want) ||
+				!SameColor(c.Vertices[i+1].Color, want) ||
+				!SameColor(c.Vertices[i+2].Color, want) {
+				continue
+			}
+			center := c.Projected(i).Add(c.Projected(i + 1)).Add(c.Projected(i + 2)).Scaled(1.0 / 3)
+			if center.Sub(pos).Len() <= radius {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DrewPicture determines whether anything has been drawn with a picture, such as a text or a sprite.
+func (rt *RecordingTarget) DrewPicture() bool {
+	for _, c := range rt.Draws() {
+		if c.HasPicture {
+			return true
+		}
+	}
+	return false
+}
+
+// SameColor determines whether two colors are the same within the precision of 8 bits per channel.
+func SameColor(a, b pixel.RGBA) bool {
+	const epsilon = 1.0 / 255
+	return math.Abs(a.R-b.R) <= epsilon &&
+		math.Abs(a.G-b.G) <= epsilon &&
+		math.Abs(a.B-b.B) <= epsilon &&
+		math.Abs(a.A-b.A) <= epsilon
+}
+
+// unexported
+func (rt *RecordingTarget) _Record(op Op, vertices []Vertex, pic pixel.Picture) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	call := Call{
+		Op:        op,
+		Matrix:    rt.matrix,
+		ColorMask: rt.mask,
+		Vertices:  vertices,
+	}
+	if pic != nil {
+		call.Picture = pic.Bounds()
+		call.HasPicture = true
+	}
+	rt.calls = append(rt.calls, call)
+}
+
+// -------------------------------------------------------------------------
+
+// recTriangles is a pixel.TargetTriangles of a RecordingTarget.
+type recTriangles struct {
+	*pixel.TrianglesData
+	rt *RecordingTarget
+}
+
+func (tri *recTriangles) Draw() {
+	tri.rt._Record(OpDraw, tri.vertices(), nil)
+}
+
+func (tri *recTriangles) vertices() []Vertex {
+	ret := make([]Vertex, tri.Len())
+	for i := range ret {
+		pic, intensity := tri.Picture(i)
+		ret[i] = Vertex{
+			Pos:       tri.Position(i),
+			Color:     tri.Color(i),
+			Pic:       pic,
+			Intensity: intensity,
+		}
+	}
+	return ret
+}
+
+// recPicture is a pixel.TargetPicture of a RecordingTarget.
+type recPicture struct {
+	pixel.Picture
+	rt *RecordingTarget
+}
+
+func (pic *recPicture) Draw(t pixel.TargetTriangles) {
+	var vertices []Vertex
+	if tri, ok := t.(*recTriangles); ok {
+		vertices = tri.vertices()
+	}
+	pic.rt._Record(OpDraw, vertices, pic.Picture)
+}
@@ -0,0 +1,45 @@
+package visualtest
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+func TestRecordingTarget(t *testing.T) {
+	rt := NewRecordingTarget()
+	rt.SetMatrix(pixel.IM.Moved(pixel.V(100, 0)))
+
+	imd := imdraw.New(nil)
+	imd.Color = colornames.Red
+	imd.Push(pixel.V(10, 20))
+	imd.Circle(5, 0)
+	imd.Draw(rt)
+	imd.Draw(rt) // The second draw reuses the triangles made by the first one.
+
+	if n := rt.Count(OpMakeTriangles); n != 1 {
+		t.Errorf("MakeTriangles called %d times; want 1", n)
+	}
+	if n := rt.Count(OpDraw); n != 2 {
+		t.Errorf("Draw called %d times; want 2", n)
+	}
+	if !rt.DrewNear(colornames.Red, pixel.V(110, 20), 5) {
+		t.Error("a red circle is not drawn near the projected position")
+	}
+	if rt.DrewNear(colornames.Red, pixel.V(10, 20), 5) {
+		t.Error("the matrix is not applied")
+	}
+	if rt.DrewNear(colornames.Blue, pixel.V(110, 20), 5) {
+		t.Error("a blue circle is drawn though it shouldn't be")
+	}
+	if rt.DrewPicture() {
+		t.Error("a picture is drawn though it shouldn't be")
+	}
+
+	rt.Reset()
+	if len(rt.Calls()) != 0 {
+		t.Error("calls are not reset")
+	}
+}